@@ -0,0 +1,246 @@
+// Command sim runs the world headlessly at a fixed step and prints the final
+// run summary as JSON. Inputs come from a replay file or a simple tick script.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"horde-lab/internal/world"
+)
+
+type setFlags []string
+
+func (s *setFlags) String() string { return strings.Join(*s, ",") }
+
+func (s *setFlags) Set(v string) error {
+	*s = append(*s, v)
+	return nil
+}
+
+type simStats struct {
+	EnemiesSpawned int     `json:"enemies_spawned"`
+	EnemiesKilled  int     `json:"enemies_killed"`
+	Recycled       int     `json:"enemies_recycled,omitempty"`
+	DamageTaken    float32 `json:"damage_taken"`
+	XPCollected    float32 `json:"xp_collected"`
+}
+
+type simResult struct {
+	Seed             int64    `json:"seed"`
+	Ticks            uint64   `json:"ticks"`
+	FixedStepSeconds float32  `json:"fixed_step_seconds"`
+	TimeSurvived     float32  `json:"time_survived"`
+	Level            int      `json:"level"`
+	Wave             int      `json:"wave"`
+	GameOver         bool     `json:"game_over"`
+	Stats            simStats `json:"stats"`
+	Desync           string   `json:"desync,omitempty"`
+}
+
+func main() {
+	var (
+		seed       = flag.Int64("seed", 1, "world RNG seed (ignored with -replay)")
+		ticks      = flag.Uint64("ticks", 0, "ticks to simulate; 0 = whole replay, or 3600 for scripted runs")
+		step       = flag.Float64("step", 1.0/60.0, "fixed step in seconds (ignored with -replay)")
+		width      = flag.Float64("w", 2000, "world width")
+		height     = flag.Float64("h", 2000, "world height")
+		configPath = flag.String("config", "", "JSON file with config overrides")
+		enemyPath  = flag.String("enemies", "", "enemy content file replacing the embedded archetypes")
+		pickupPath = flag.String("pickups", "", "pickup content file replacing the embedded pickups")
+		scriptPath = flag.String("script", "", "tick script file (default: idle input)")
+		replayPath = flag.String("replay", "", "replay file to play back")
+		autoPick   = flag.Bool("autopick", true, "pick the first upgrade when a level-up menu opens (scripted runs)")
+		untilDeath = flag.Bool("stop-on-death", true, "stop as soon as the run is over")
+		sets       setFlags
+	)
+	flag.Var(&sets, "set", "config override Field=value (repeatable)")
+	flag.Parse()
+
+	res, err := run(runOptions{
+		seed:       *seed,
+		ticks:      *ticks,
+		step:       float32(*step),
+		w:          float32(*width),
+		h:          float32(*height),
+		configPath: *configPath,
+		enemyPath:  *enemyPath,
+		pickupPath: *pickupPath,
+		sets:       sets,
+		scriptPath: *scriptPath,
+		replayPath: *replayPath,
+		autoPick:   *autoPick,
+		untilDeath: *untilDeath,
+	})
+	if err != nil {
+		log.Fatalf("sim: %v", err)
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(res); err != nil {
+		log.Fatalf("sim: encode result: %v", err)
+	}
+}
+
+type runOptions struct {
+	seed       int64
+	ticks      uint64
+	step       float32
+	w, h       float32
+	configPath string
+	enemyPath  string
+	pickupPath string
+	sets       []string
+	scriptPath string
+	replayPath string
+	autoPick   bool
+	untilDeath bool
+}
+
+func run(opts runOptions) (simResult, error) {
+	var (
+		w        *world.World
+		src      frameSource
+		verifier *world.ReplayVerifier
+		desync   error
+	)
+
+	if opts.replayPath != "" {
+		rep, err := world.LoadReplayFile(opts.replayPath)
+		if err != nil {
+			return simResult{}, err
+		}
+		if rep.Header.FixedStepSeconds <= 0 {
+			return simResult{}, fmt.Errorf("replay fixed step must be positive")
+		}
+		w = world.NewWorld(1, 1)
+		if err := w.ApplySnapshot(rep.Initial); err != nil {
+			w.Close()
+			return simResult{}, fmt.Errorf("apply replay snapshot: %w", err)
+		}
+		src = &replaySource{frames: rep.Frames}
+		verifier = world.NewReplayVerifier(rep.Checkpoints)
+		opts.step = rep.Header.FixedStepSeconds
+		opts.autoPick = false
+		if opts.ticks == 0 {
+			opts.ticks = uint64(len(rep.Frames))
+		}
+	} else {
+		if opts.step <= 0 {
+			return simResult{}, fmt.Errorf("fixed step must be positive")
+		}
+		cfg, err := buildConfig(opts.configPath, opts.enemyPath, opts.pickupPath, opts.sets)
+		if err != nil {
+			return simResult{}, err
+		}
+		var steps []scriptStep
+		if opts.scriptPath != "" {
+			if steps, err = loadScript(opts.scriptPath); err != nil {
+				return simResult{}, err
+			}
+		}
+		w = world.NewWorldWithConfig(opts.w, opts.h, cfg, opts.seed)
+		src = newScriptSource(steps)
+		if opts.ticks == 0 {
+			opts.ticks = 3600
+		}
+	}
+	defer w.Close()
+
+	var tick uint64
+	for ; tick < opts.ticks; tick++ {
+		if opts.untilDeath && w.GameOver {
+			break
+		}
+		frame, ok := src.Next(tick)
+		if !ok {
+			break
+		}
+		if opts.autoPick && w.Upgrade.Active && frame.Choose < 0 && !frame.Reroll && !frame.Skip {
+			frame.Choose = 0
+		}
+		w.EnqueueFrame(frame)
+		w.Tick(opts.step)
+		if verifier != nil && desync == nil {
+			desync = verifier.Check(w, frame.Tick)
+		}
+	}
+
+	s := w.BuildSnapshot()
+	res := simResult{
+		Seed:             s.RNGSeed,
+		Ticks:            tick,
+		FixedStepSeconds: opts.step,
+		TimeSurvived:     s.TimeSurvived,
+		Level:            s.Player.Level,
+		Wave:             s.Wave.Index,
+		GameOver:         s.GameOver,
+		Stats: simStats{
+			EnemiesSpawned: s.Stats.EnemiesSpawned,
+			EnemiesKilled:  s.Stats.EnemiesKilled,
+			Recycled:       s.Stats.EnemiesRecycled,
+			DamageTaken:    s.Stats.DamageTaken,
+			XPCollected:    s.Stats.XPCollected,
+		},
+	}
+	if desync != nil {
+		res.Desync = desync.Error()
+	}
+	return res, nil
+}
+
+// buildConfig layers enemy and pickup content files, a JSON override file and
+// then -set pairs on top of the default config. Unknown field names are
+// rejected so typos fail loudly.
+func buildConfig(path, enemyPath, pickupPath string, sets []string) (world.Config, error) {
+	cfg := world.DefaultConfig()
+
+	if enemyPath != "" {
+		content, err := world.LoadEnemyContent(enemyPath)
+		if err != nil {
+			return cfg, err
+		}
+		cfg.DefaultEnemy, cfg.Enemies, cfg.Elites = content.Default, content.Enemies, content.Elites
+	}
+	if pickupPath != "" {
+		content, err := world.LoadPickupContent(pickupPath)
+		if err != nil {
+			return cfg, err
+		}
+		cfg.Pickups = content.Pickups
+	}
+
+	if path != "" {
+		blob, err := os.ReadFile(path)
+		if err != nil {
+			return cfg, fmt.Errorf("read config overrides: %w", err)
+		}
+		if err := decodeStrict(blob, &cfg); err != nil {
+			return cfg, fmt.Errorf("decode config overrides: %w", err)
+		}
+	}
+
+	for _, kv := range sets {
+		key, val, ok := strings.Cut(kv, "=")
+		if !ok || key == "" {
+			return cfg, fmt.Errorf("invalid -set %q: want Field=value", kv)
+		}
+		blob := fmt.Appendf(nil, "{%q:%s}", key, val)
+		if err := decodeStrict(blob, &cfg); err != nil {
+			return cfg, fmt.Errorf("apply -set %q: %w", kv, err)
+		}
+	}
+	return cfg, nil
+}
+
+func decodeStrict(blob []byte, v any) error {
+	dec := json.NewDecoder(bytes.NewReader(blob))
+	dec.DisallowUnknownFields()
+	return dec.Decode(v)
+}
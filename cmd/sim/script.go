@@ -0,0 +1,176 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"horde-lab/internal/shared/input"
+	"horde-lab/internal/world"
+)
+
+// frameSource feeds one replay frame per fixed step. ok=false ends the run.
+type frameSource interface {
+	Next(tick uint64) (frame world.ReplayFrame, ok bool)
+}
+
+// replaySource plays back the frames of a recorded replay file.
+type replaySource struct {
+	frames []world.ReplayFrame
+}
+
+func (s *replaySource) Next(tick uint64) (world.ReplayFrame, bool) {
+	if tick >= uint64(len(s.frames)) {
+		return world.ReplayFrame{}, false
+	}
+	return s.frames[tick], true
+}
+
+// scriptStep holds one input state for a number of ticks. Actions fire on the
+// first tick of the step only.
+type scriptStep struct {
+	ticks       uint64
+	input       input.State
+	choose      int
+	banish      bool
+	togglePause bool
+	restart     bool
+	reroll      bool
+	skip        bool
+}
+
+// scriptSource expands script steps into frames. Once the script is exhausted
+// it keeps feeding idle input so the tick budget decides when the run ends.
+type scriptSource struct {
+	steps []scriptStep
+	idx   int
+	left  uint64
+	first bool
+}
+
+func newScriptSource(steps []scriptStep) *scriptSource {
+	s := &scriptSource{steps: steps}
+	if len(steps) > 0 {
+		s.left = steps[0].ticks
+		s.first = true
+	}
+	return s
+}
+
+func (s *scriptSource) Next(tick uint64) (world.ReplayFrame, bool) {
+	frame := world.ReplayFrame{Tick: tick, Choose: -1}
+	for s.idx < len(s.steps) && s.left == 0 {
+		s.idx++
+		if s.idx < len(s.steps) {
+			s.left = s.steps[s.idx].ticks
+			s.first = true
+		}
+	}
+	if s.idx >= len(s.steps) {
+		return frame, true
+	}
+
+	step := s.steps[s.idx]
+	frame.Input = step.input
+	if s.first {
+		frame.Choose = step.choose
+		frame.TogglePause = step.togglePause
+		frame.Restart = step.restart
+		frame.Reroll = step.reroll
+		frame.Skip = step.skip
+		frame.Banish = step.banish
+		s.first = false
+	}
+	s.left--
+	return frame, true
+}
+
+func loadScript(path string) ([]scriptStep, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open script: %w", err)
+	}
+	defer f.Close()
+	return parseScript(f)
+}
+
+// parseScript reads one step per line:
+//
+//	<ticks> <keys> [actions...]
+//
+// keys is a comma-separated subset of up,down,left,right or "-" for idle.
+// Actions are pause, restart, choose=N, reroll, skip and banish=N. Blank lines and '#' comments are
+// ignored.
+func parseScript(r io.Reader) ([]scriptStep, error) {
+	var steps []scriptStep
+	sc := bufio.NewScanner(r)
+	line := 0
+	for sc.Scan() {
+		line++
+		text := sc.Text()
+		if i := strings.IndexByte(text, '#'); i >= 0 {
+			text = text[:i]
+		}
+		fields := strings.Fields(text)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("script line %d: want \"<ticks> <keys> [actions]\"", line)
+		}
+
+		ticks, err := strconv.ParseUint(fields[0], 10, 64)
+		if err != nil || ticks == 0 {
+			return nil, fmt.Errorf("script line %d: invalid tick count %q", line, fields[0])
+		}
+		step := scriptStep{ticks: ticks, choose: -1}
+
+		if fields[1] != "-" {
+			for _, key := range strings.Split(fields[1], ",") {
+				switch strings.ToLower(key) {
+				case "up":
+					step.input.Up = true
+				case "down":
+					step.input.Down = true
+				case "left":
+					step.input.Left = true
+				case "right":
+					step.input.Right = true
+				default:
+					return nil, fmt.Errorf("script line %d: unknown key %q", line, key)
+				}
+			}
+		}
+
+		for _, action := range fields[2:] {
+			switch {
+			case action == "pause":
+				step.togglePause = true
+			case action == "restart":
+				step.restart = true
+			case action == "reroll":
+				step.reroll = true
+			case action == "skip":
+				step.skip = true
+			case strings.HasPrefix(action, "choose="), strings.HasPrefix(action, "banish="):
+				name, value, _ := strings.Cut(action, "=")
+				n, err := strconv.Atoi(value)
+				if err != nil || n < 0 {
+					return nil, fmt.Errorf("script line %d: invalid choice %q", line, action)
+				}
+				step.choose = n
+				step.banish = name == "banish"
+			default:
+				return nil, fmt.Errorf("script line %d: unknown action %q", line, action)
+			}
+		}
+		steps = append(steps, step)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("read script: %w", err)
+	}
+	return steps, nil
+}
@@ -2,17 +2,21 @@ package game
 
 import (
 	// "fmt"
+	"errors"
 	"fmt"
 	"horde-lab/internal/assets"
+	"horde-lab/internal/render"
 	"horde-lab/internal/telemetry"
 	"horde-lab/internal/world"
+	"io/fs"
 	"log"
+	"os"
 	"slices"
+	"strings"
 	"time"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
-	"github.com/hajimehoshi/ebiten/v2/inpututil"
 )
 
 type Game struct {
@@ -31,19 +35,23 @@ type Game struct {
 	telemetry *telemetry.Sink
 
 	// cumulative stat baselines (for delta events)
-	lastKills  int
-	lastDamage float32
+	lastKills   int
+	lastDamage  float32
+	lastJobShed uint64
+	lastJobLate uint64
 
 	snapshotPath string
 	saveReply    chan error
 	loadReply    chan error
 
-	replayPath string
-	replay     world.ReplayFile
-	replayTick uint64
+	replayPath       string
+	legacyReplayPath string // JSON replays saved before the binary codec
+	replay           world.ReplayFile
+	replayTick       uint64
 
-	replayMode     bool
-	replayFrameIdx int
+	replayMode         bool
+	replayPlayer       *ReplayPlayer
+	replayDesyncLogged bool
 
 	profilePath   string
 	saveGamePath  string
@@ -51,18 +59,22 @@ type Game struct {
 	profile       PlayerProfile
 	highscores    HighscoreFile
 	gameOverSaved bool
+	shopSel       int // shop item highlighted on the game-over screen
+
+	// last drawn screen size, for mouse hit tests in Update
+	screenW, screenH int
 }
 
 func New() *Game {
 	g := &Game{
-		w:             world.NewWorld(2000, 2000), // world size
-		last:          time.Now(),
-		fixedStep:     time.Second / 60,
-		snapshotPath:  ".dist/snapshot.json",
-		replayPath:    ".dist/replay.json",
-		profilePath:   ".dist/player_profile.json",
-		saveGamePath:  ".dist/savegame.json",
-		highscorePath: ".dist/highscores.json",
+		last:             time.Now(),
+		fixedStep:        time.Second / 60,
+		snapshotPath:     ".dist/snapshot.json",
+		replayPath:       ".dist/replay" + world.ReplayBinaryExt,
+		legacyReplayPath: ".dist/replay.json",
+		profilePath:      ".dist/player_profile.json",
+		saveGamePath:     ".dist/savegame.json",
+		highscorePath:    ".dist/highscores.json",
 	}
 
 	if p, err := loadProfile(g.profilePath); err == nil {
@@ -73,6 +85,9 @@ func New() *Game {
 			log.Printf("init profile: %v", err)
 		}
 	}
+	cfg := world.DefaultConfig()
+	cfg.Meta = metaBonuses(g.profile)
+	g.w = world.NewWorldWithConfig(2000, 2000, cfg, 1) // world size
 	if hs, err := loadHighscores(g.highscorePath); err == nil {
 		g.highscores = hs
 	} else {
@@ -117,8 +132,9 @@ func (g *Game) Update() error {
 	in := ReadInput()
 	restartPressed := ReadRestart()
 	pausePressed := ReadPaused()
-	choose0 := inpututil.IsKeyJustPressed(ebiten.Key1) || inpututil.IsKeyJustPressed(ebiten.KeyKP1)
-	choose1 := inpututil.IsKeyJustPressed(ebiten.Key2) || inpututil.IsKeyJustPressed(ebiten.KeyKP2)
+	choose, banish := ReadChooseUpgrade()
+	reroll := ReadRerollUpgrades()
+	skip := ReadSkipUpgrade()
 
 	if ReadSaveSnapshot() && g.saveReply == nil {
 		g.saveReply = make(chan error, 1)
@@ -161,7 +177,10 @@ func (g *Game) Update() error {
 			log.Printf("start replay: %v", err)
 		}
 	}
-	if ReadContinuePaused() && g.w.Paused && !g.w.GameOver {
+	if g.replayMode {
+		g.handleReplayTransport()
+	}
+	if ReadContinuePaused() && g.w.Paused && !g.w.GameOver && !g.replayMode {
 		g.w.Enqueue(world.MsgTogglePause{})
 	}
 	if ReadCycleCharacter() && !g.replayMode {
@@ -170,6 +189,12 @@ func (g *Game) Update() error {
 	if ReadCycleCustomization() && !g.replayMode {
 		g.cycleCustomization()
 	}
+	if g.w.GameOver && !g.replayMode {
+		g.handleShop()
+	}
+	if restartPressed && !g.replayMode {
+		g.applyMetaBeforeRestart()
+	}
 	// if in.Down || in.Left || in.Right || in.Up {
 
 	// 	fmt.Println("input values", in)
@@ -178,29 +203,18 @@ func (g *Game) Update() error {
 	// fixed-step simulation
 	for g.accum >= g.fixedStep {
 		if g.replayMode {
-			if g.replayFrameIdx >= len(g.replay.Frames) {
-				log.Printf("replay complete: frames=%d", len(g.replay.Frames))
-				g.replayMode = false
-				g.replayFrameIdx = 0
-				g.resetReplayRecording()
-				break
-			}
-
-			frame := g.replay.Frames[g.replayFrameIdx]
-			g.enqueueReplayFrame(frame)
-			g.replayFrameIdx++
+			g.replayPlayer.Advance()
+			g.logReplayDesync()
 		} else {
 			frame := world.ReplayFrame{
 				Tick:        g.replayTick,
 				Input:       in,
-				Choose:      -1,
+				Choose:      choose,
 				TogglePause: pausePressed,
 				Restart:     restartPressed,
-			}
-			if choose0 {
-				frame.Choose = 0
-			} else if choose1 {
-				frame.Choose = 1
+				Reroll:      reroll,
+				Skip:        skip,
+				Banish:      banish,
 			}
 			g.enqueueReplayFrame(frame)
 			g.replay.Frames = append(g.replay.Frames, frame)
@@ -208,11 +222,14 @@ func (g *Game) Update() error {
 
 			restartPressed = false
 			pausePressed = false
-			choose0 = false
-			choose1 = false
-		}
+			choose, banish = -1, false
+			reroll = false
+			skip = false
 
-		g.w.Tick(float32(g.fixedStep.Seconds()))
+			g.w.Tick(float32(g.fixedStep.Seconds()))
+			g.replay.RecordCheckpoint(g.w, frame.Tick)
+			g.replay.RecordKeyframe(g.w)
+		}
 		g.accum -= g.fixedStep
 	}
 	g.emitWorldDeltas(now)
@@ -223,20 +240,38 @@ func (g *Game) Update() error {
 }
 
 func (g *Game) Draw(screen *ebiten.Image) {
-	g.w.Draw(screen, g.assets)
+	g.screenW, g.screenH = screen.Bounds().Dx(), screen.Bounds().Dy()
+	snap := g.w.BuildSnapshot()
+	render.Draw(screen, &snap, g.assets)
+	if g.replayMode {
+		p := g.replayPlayer
+		render.DrawReplayTransport(screen, render.ReplayTransport{
+			Tick:        p.Tick(),
+			Len:         p.Len(),
+			StepSeconds: g.fixedStep.Seconds(),
+			Speed:       p.Speed(),
+			Paused:      p.Paused(),
+			Desync:      p.Desync() != nil,
+		})
+	}
 	best := "-"
 	if len(g.highscores.Entries) > 0 {
 		top := g.highscores.Entries[0]
 		best = fmt.Sprintf("%d (%s)", top.Score, top.Name)
 	}
 	status := fmt.Sprintf(
-		"Player: %s  Character: %s  Style: %s\nBest Score: %s\nF1: cycle character  F2: cycle style  F7: stop+save  F8: load save  C: continue paused",
+		"Player: %s  Character: %s  Style: %s  Gold: %d\nBest Score: %s\nF1: cycle character  F2: cycle style  F7: stop+save  F8: load save  C: continue paused",
 		g.profile.Name,
 		characterDisplayName(g.profile.Character),
 		g.profile.Customization,
+		g.profile.Gold,
 		best,
 	)
 	ebitenutil.DebugPrintAt(screen, status, 8, screen.Bounds().Dy()-60)
+	if g.w.GameOver && !g.replayMode {
+		// stacked above the status lines, one 16px debug line per row
+		ebitenutil.DebugPrintAt(screen, g.shopText(), 8, screen.Bounds().Dy()-60-16*(len(shopItems)+2))
+	}
 }
 
 func (g *Game) Layout(outsideW, outsideH int) (int, int) {
@@ -288,6 +323,24 @@ func (g *Game) emitWorldDeltas(at time.Time) {
 			g.lastDamage = stats.DamageTaken
 		}
 	}
+
+	var shed, late uint64
+	for _, s := range g.w.JobStats() {
+		shed += s.Rejected + s.Expired + s.Dropped
+		late += s.Late
+	}
+	// restarts start fresh pools
+	if shed < g.lastJobShed || late < g.lastJobLate {
+		g.lastJobShed, g.lastJobLate = shed, late
+	}
+	if shed > g.lastJobShed {
+		g.sendTelemetry(telemetry.Event{Kind: "job_shed", I: int(shed - g.lastJobShed), At: at})
+		g.lastJobShed = shed
+	}
+	if late > g.lastJobLate {
+		g.sendTelemetry(telemetry.Event{Kind: "job_late", I: int(late - g.lastJobLate), At: at})
+		g.lastJobLate = late
+	}
 }
 
 func (g *Game) sendTelemetry(ev telemetry.Event) {
@@ -328,16 +381,7 @@ func (g *Game) pollPersistenceReplies() {
 }
 
 func (g *Game) enqueueReplayFrame(frame world.ReplayFrame) {
-	g.w.Enqueue(world.MsgInput{Input: frame.Input})
-	if frame.Restart {
-		g.w.Enqueue(world.MsgRestart{})
-	}
-	if frame.TogglePause {
-		g.w.Enqueue(world.MsgTogglePause{})
-	}
-	if frame.Choose == 0 || frame.Choose == 1 {
-		g.w.Enqueue(world.MsgChooseUpgrade{Choice: frame.Choose})
-	}
+	g.w.EnqueueFrame(frame)
 }
 
 func (g *Game) resetReplayRecording() {
@@ -360,21 +404,93 @@ func (g *Game) resetReplayRecording() {
 }
 
 func (g *Game) startReplayFromFile() error {
-	rep, err := world.LoadReplayFile(g.replayPath)
+	path := g.replayPath
+	if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) && g.legacyReplayPath != "" {
+		path = g.legacyReplayPath
+	}
+	rep, err := world.LoadReplayFile(path)
 	if err != nil {
 		return err
 	}
-	if err := g.w.ApplySnapshot(rep.Initial); err != nil {
+	p, err := NewReplayPlayer(g.w, rep)
+	if err != nil {
 		return err
 	}
 
-	g.replay = rep
+	g.replayPlayer = p
 	g.replayMode = true
-	g.replayFrameIdx = 0
-	g.replayTick = 0
+	g.replayDesyncLogged = false
 	return nil
 }
 
+// stopReplay leaves playback where it is and starts a fresh recording from
+// that state.
+func (g *Game) stopReplay() {
+	log.Printf("replay stopped at tick %d/%d", g.replayPlayer.Tick(), g.replayPlayer.Len())
+	g.replayMode = false
+	g.replayPlayer = nil
+	// a replay stopped at game over ends a run that is not the player's
+	g.gameOverSaved = g.w.GameOver
+	g.resetReplayRecording()
+}
+
+// replaySeekStep is how far the arrow keys jump during playback.
+const replaySeekStep = 5 * 60
+
+func (g *Game) handleReplayTransport() {
+	p := g.replayPlayer
+	if ReadReplayExit() {
+		g.stopReplay()
+		return
+	}
+	if ReadReplayTogglePlay() {
+		p.SetPaused(!p.Paused())
+	}
+	if ReadReplayFaster() {
+		p.SetSpeed(p.Speed() * 2)
+	}
+	if ReadReplaySlower() {
+		p.SetSpeed(p.Speed() / 2)
+	}
+
+	target, seek := 0, true
+	switch {
+	case ReadReplayStepForward():
+		p.SetPaused(true)
+		p.Step()
+		seek = false
+	case ReadReplayStepBack():
+		p.SetPaused(true)
+		target = p.Tick() - 1
+	case ReadReplaySeekForward():
+		target = p.Tick() + replaySeekStep
+	case ReadReplaySeekBack():
+		target = p.Tick() - replaySeekStep
+	case ReadReplayRestart():
+		target = 0
+	default:
+		seek = false
+	}
+	if x, y, ok := ReadClick(); ok {
+		if frac, hit := render.ReplayBarFraction(g.screenW, g.screenH, x, y); hit {
+			target, seek = int(frac*float32(p.Len())), true
+		}
+	}
+	if seek {
+		if err := p.Seek(target); err != nil {
+			log.Printf("replay seek: %v", err)
+		}
+	}
+	g.logReplayDesync()
+}
+
+func (g *Game) logReplayDesync() {
+	if err := g.replayPlayer.Desync(); err != nil && !g.replayDesyncLogged {
+		log.Printf("%v", err)
+		g.replayDesyncLogged = true
+	}
+}
+
 func (g *Game) saveCurrentGame() error {
 	sg := SaveGame{
 		Version:  saveGameVersion,
@@ -393,7 +509,10 @@ func (g *Game) loadSavedGame() error {
 	if err := g.w.ApplySnapshot(sg.Snapshot); err != nil {
 		return err
 	}
+	// gold and shop levels belong to the profile on disk, not the save
+	gold, shop := g.profile.Gold, g.profile.Shop
 	g.profile = sg.Profile
+	g.profile.Gold, g.profile.Shop = gold, shop
 	g.requestCharacterAssets(g.profile.Character)
 	g.gameOverSaved = g.w.GameOver
 	g.resetReplayRecording()
@@ -423,7 +542,13 @@ func (g *Game) cycleCustomization() {
 	}
 }
 
+// captureHighscoreOnGameOver banks the run's gold and records a highscore
+// once per game over. Watching a replay banks nothing: the run was banked
+// when it was played, and seeking back would bank it again.
 func (g *Game) captureHighscoreOnGameOver() {
+	if g.replayMode {
+		return
+	}
 	if !g.w.GameOver {
 		g.gameOverSaved = false
 		return
@@ -443,6 +568,10 @@ func (g *Game) captureHighscoreOnGameOver() {
 		TimeSurvived:  s.TimeSurvived,
 		Score:         calcScore(s),
 	}
+	g.profile.Gold += s.Player.Gold
+	if err := saveProfile(g.profilePath, g.profile); err != nil {
+		log.Printf("save profile: %v", err)
+	}
 	g.highscores.Entries = append(g.highscores.Entries, entry)
 	sortHighscores(g.highscores.Entries)
 	if len(g.highscores.Entries) > 20 {
@@ -454,6 +583,60 @@ func (g *Game) captureHighscoreOnGameOver() {
 	g.gameOverSaved = true
 }
 
+// handleShop reads the game-over shop keys: F3 moves the selection, F4 buys
+// the selected item and F12 refunds everything bought.
+func (g *Game) handleShop() {
+	changed := false
+	switch {
+	case ReadShopNext():
+		g.shopSel = (g.shopSel + 1) % len(shopItems)
+	case ReadShopBuy():
+		if err := buyShopItem(&g.profile, shopItems[g.shopSel]); err != nil {
+			log.Printf("shop: %v", err)
+		} else {
+			changed = true
+		}
+	case ReadShopRefund():
+		changed = refundShop(&g.profile) > 0
+	}
+	if changed {
+		if err := saveProfile(g.profilePath, g.profile); err != nil {
+			log.Printf("save profile: %v", err)
+		}
+	}
+}
+
+// applyMetaBeforeRestart hands changed shop bonuses to the world before a
+// restart rebuilds the player. Recording restarts from here so the replay's
+// initial config carries the bonuses the new run is played with.
+func (g *Game) applyMetaBeforeRestart() {
+	meta := metaBonuses(g.profile)
+	if meta == g.w.Cfg.Meta || !(g.w.GameOver || g.w.Paused) {
+		return
+	}
+	g.w.SetMeta(meta)
+	g.resetReplayRecording()
+}
+
+func (g *Game) shopText() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "SHOP  gold %d  (F3: next  F4: buy  F12: refund all)\n", g.profile.Gold)
+	for i, item := range shopItems {
+		def := shopDefs[item]
+		level := g.profile.Shop[item]
+		price := "max"
+		if cost, ok := shopCost(item, level); ok {
+			price = fmt.Sprintf("%d gold", cost)
+		}
+		cursor := "  "
+		if i == g.shopSel {
+			cursor = "> "
+		}
+		fmt.Fprintf(&b, "%s%s %d/%d - %s (%s)\n", cursor, def.Name, level, def.MaxLevel, def.Desc, price)
+	}
+	return b.String()
+}
+
 func (g *Game) requestCharacterAssets(characterID string) {
 	if characterID == "" {
 		return
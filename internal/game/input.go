@@ -25,6 +25,31 @@ func ReadPaused() bool {
 	return inpututil.IsKeyJustPressed(ebiten.KeySpace)
 }
 
+// ReadChooseUpgrade returns the level-up option picked with 1-4, or -1.
+// Holding Shift turns the pick into a banish.
+func ReadChooseUpgrade() (choice int, banish bool) {
+	keys := [][2]ebiten.Key{
+		{ebiten.Key1, ebiten.KeyKP1},
+		{ebiten.Key2, ebiten.KeyKP2},
+		{ebiten.Key3, ebiten.KeyKP3},
+		{ebiten.Key4, ebiten.KeyKP4},
+	}
+	for i, k := range keys {
+		if inpututil.IsKeyJustPressed(k[0]) || inpututil.IsKeyJustPressed(k[1]) {
+			return i, ebiten.IsKeyPressed(ebiten.KeyShift)
+		}
+	}
+	return -1, false
+}
+
+func ReadRerollUpgrades() bool {
+	return inpututil.IsKeyJustPressed(ebiten.KeyQ)
+}
+
+func ReadSkipUpgrade() bool {
+	return inpututil.IsKeyJustPressed(ebiten.KeyX)
+}
+
 func ReadSaveSnapshot() bool {
 	return inpututil.IsKeyJustPressed(ebiten.KeyF5)
 }
@@ -60,3 +85,64 @@ func ReadCycleCharacter() bool {
 func ReadCycleCustomization() bool {
 	return inpututil.IsKeyJustPressed(ebiten.KeyF2)
 }
+
+// Shop keys; only read on the game-over screen.
+
+func ReadShopNext() bool {
+	return inpututil.IsKeyJustPressed(ebiten.KeyF3)
+}
+
+func ReadShopBuy() bool {
+	return inpututil.IsKeyJustPressed(ebiten.KeyF4)
+}
+
+func ReadShopRefund() bool {
+	return inpututil.IsKeyJustPressed(ebiten.KeyF12)
+}
+
+// Replay transport keys; only read while a replay is playing.
+
+func ReadReplayTogglePlay() bool {
+	return inpututil.IsKeyJustPressed(ebiten.KeySpace)
+}
+
+func ReadReplayStepForward() bool {
+	return inpututil.IsKeyJustPressed(ebiten.KeyPeriod)
+}
+
+func ReadReplayStepBack() bool {
+	return inpututil.IsKeyJustPressed(ebiten.KeyComma)
+}
+
+func ReadReplayFaster() bool {
+	return inpututil.IsKeyJustPressed(ebiten.KeyBracketRight)
+}
+
+func ReadReplaySlower() bool {
+	return inpututil.IsKeyJustPressed(ebiten.KeyBracketLeft)
+}
+
+func ReadReplaySeekForward() bool {
+	return inpututil.IsKeyJustPressed(ebiten.KeyArrowRight)
+}
+
+func ReadReplaySeekBack() bool {
+	return inpututil.IsKeyJustPressed(ebiten.KeyArrowLeft)
+}
+
+func ReadReplayRestart() bool {
+	return inpututil.IsKeyJustPressed(ebiten.KeyHome)
+}
+
+func ReadReplayExit() bool {
+	return inpututil.IsKeyJustPressed(ebiten.KeyEscape)
+}
+
+// ReadClick reports a left click this frame and the cursor position.
+func ReadClick() (x, y int, ok bool) {
+	if !inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+		return 0, 0, false
+	}
+	x, y = ebiten.CursorPosition()
+	return x, y, true
+}
@@ -9,15 +9,27 @@ import (
 	"strings"
 	"time"
 
+	"horde-lab/internal/shared/migrate"
 	"horde-lab/internal/world"
 )
 
 const (
-	profileVersion   = 1
+	profileVersion   = 2
 	saveGameVersion  = 1
 	highscoreVersion = 1
 )
 
+// Migration registries run on the raw JSON before decoding. A savegame embeds
+// a profile and a snapshot, each migrated by its own registry.
+var profileMigrations = migrate.New("profile", profileVersion, "version").
+	Register(1, migrateProfileV1)
+
+var highscoreMigrations = migrate.New("highscores", highscoreVersion, "version")
+
+var saveGameMigrations = migrate.New("savegame", saveGameVersion, "version").
+	Nest("profile", profileMigrations).
+	Nest("snapshot", world.SnapshotMigrations)
+
 var characterChoices = []string{
 	"daniel_kim",
 	"hana_choi",
@@ -34,11 +46,15 @@ var customizationChoices = []string{
 	"Emerald",
 }
 
+// PlayerProfile persists across runs. Gold banks what runs earned; Shop
+// holds the level of each bought meta upgrade.
 type PlayerProfile struct {
-	Version       int    `json:"version"`
-	Name          string `json:"name"`
-	Character     string `json:"character"`
-	Customization string `json:"customization"`
+	Version       int              `json:"version"`
+	Name          string           `json:"name"`
+	Character     string           `json:"character"`
+	Customization string           `json:"customization"`
+	Gold          int              `json:"gold"`
+	Shop          map[ShopItem]int `json:"shop,omitempty"`
 }
 
 type SaveGame struct {
@@ -73,6 +89,14 @@ func defaultProfile() PlayerProfile {
 	}
 }
 
+// migrateProfileV1 starts v1 profiles with no banked gold.
+func migrateProfileV1(doc migrate.Doc) error {
+	if _, ok := doc["gold"]; !ok {
+		doc["gold"] = migrate.Uint64Number(0)
+	}
+	return nil
+}
+
 func characterDisplayName(id string) string {
 	if id == "" {
 		return "Unknown"
@@ -122,6 +146,9 @@ func loadProfile(path string) (PlayerProfile, error) {
 	if err != nil {
 		return PlayerProfile{}, err
 	}
+	if blob, err = profileMigrations.MigrateJSON(blob); err != nil {
+		return PlayerProfile{}, err
+	}
 	var p PlayerProfile
 	if err := json.Unmarshal(blob, &p); err != nil {
 		return PlayerProfile{}, err
@@ -135,6 +162,7 @@ func loadProfile(path string) (PlayerProfile, error) {
 	if !slices.Contains(customizationChoices, p.Customization) {
 		p.Customization = customizationChoices[0]
 	}
+	normalizeShop(&p)
 	if p.Version == 0 {
 		p.Version = profileVersion
 	}
@@ -151,6 +179,9 @@ func loadSaveGame(path string) (SaveGame, error) {
 	if err != nil {
 		return SaveGame{}, err
 	}
+	if blob, err = saveGameMigrations.MigrateJSON(blob); err != nil {
+		return SaveGame{}, err
+	}
 	var sg SaveGame
 	if err := json.Unmarshal(blob, &sg); err != nil {
 		return SaveGame{}, err
@@ -171,6 +202,9 @@ func loadHighscores(path string) (HighscoreFile, error) {
 	if err != nil {
 		return HighscoreFile{}, err
 	}
+	if blob, err = highscoreMigrations.MigrateJSON(blob); err != nil {
+		return HighscoreFile{}, err
+	}
 	var hs HighscoreFile
 	if err := json.Unmarshal(blob, &hs); err != nil {
 		return HighscoreFile{}, err
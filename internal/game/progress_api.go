@@ -1,6 +1,10 @@
 package game
 
-import "horde-lab/internal/world"
+import (
+	"slices"
+
+	"horde-lab/internal/world"
+)
 
 func DefaultProfile() PlayerProfile {
 	return defaultProfile()
@@ -37,3 +41,23 @@ func CalcScore(s world.Snapshot) int {
 func SortHighscores(entries []HighscoreEntry) {
 	sortHighscores(entries)
 }
+
+func ShopItems() []ShopItem {
+	return slices.Clone(shopItems)
+}
+
+func ShopCost(item ShopItem, level int) (int, bool) {
+	return shopCost(item, level)
+}
+
+func BuyShopItem(p *PlayerProfile, item ShopItem) error {
+	return buyShopItem(p, item)
+}
+
+func RefundShop(p *PlayerProfile) int {
+	return refundShop(p)
+}
+
+func MetaBonuses(p PlayerProfile) world.MetaBonuses {
+	return metaBonuses(p)
+}
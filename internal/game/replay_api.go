@@ -26,33 +26,61 @@ func RecordReplay(w *world.World, fixedStep time.Duration, frames []world.Replay
 		g.replay.Frames = append(g.replay.Frames, frame)
 		g.replayTick++
 		g.w.Tick(float32(g.fixedStep.Seconds()))
+		g.replay.RecordCheckpoint(g.w, frame.Tick)
+		g.replay.RecordKeyframe(g.w)
 	}
 
 	return g.replay, nil
 }
 
+// PlayReplay runs rep on w and verifies the recorded checkpoints. After the
+// first mismatch it plays on to the next recorded keyframe, if any, and
+// returns a *world.DesyncError whose Fields diff that keyframe's snapshot.
 func PlayReplay(w *world.World, rep world.ReplayFile) error {
-	if w == nil {
-		return fmt.Errorf("world is nil")
+	p, err := NewReplayPlayer(w, rep)
+	if err != nil {
+		return err
 	}
-	if rep.Header.FixedStepSeconds <= 0 {
-		return fmt.Errorf("replay fixed step must be positive")
+	for p.Step() {
+		if d, ok := p.Desync().(*world.DesyncError); ok && d.FieldsFrame != 0 {
+			return d
+		}
 	}
-	if err := w.ApplySnapshot(rep.Initial); err != nil {
+	return p.Desync()
+}
+
+// WatchReplay plays rep on w from the start passes times the way in-game
+// playback does, with the game-over bookkeeping after every tick. Profile
+// and highscores are read from and saved to the given paths.
+func WatchReplay(w *world.World, rep world.ReplayFile, profilePath, highscorePath string, passes int) error {
+	profile, err := loadProfile(profilePath)
+	if err != nil {
+		return err
+	}
+	highscores, err := loadHighscores(highscorePath)
+	if err != nil {
+		return err
+	}
+	p, err := NewReplayPlayer(w, rep)
+	if err != nil {
 		return err
 	}
-
 	g := &Game{
-		w:      w,
-		replay: rep,
+		w:             w,
+		profilePath:   profilePath,
+		highscorePath: highscorePath,
+		profile:       profile,
+		highscores:    highscores,
+		replayMode:    true,
+		replayPlayer:  p,
 	}
-
-	for g.replayFrameIdx < len(g.replay.Frames) {
-		frame := g.replay.Frames[g.replayFrameIdx]
-		g.enqueueReplayFrame(frame)
-		g.replayFrameIdx++
-		g.w.Tick(rep.Header.FixedStepSeconds)
+	for range passes {
+		if err := p.Seek(0); err != nil {
+			return err
+		}
+		for p.Step() {
+			g.captureHighscoreOnGameOver()
+		}
 	}
-
 	return nil
 }
@@ -0,0 +1,185 @@
+package game
+
+import (
+	"fmt"
+	"slices"
+
+	"horde-lab/internal/world"
+)
+
+// MaxReplaySpeed is the fastest playback rate in ticks per fixed step.
+const MaxReplaySpeed = 8
+
+// ReplayPlayer drives a replay on a world with transport controls. Seeking
+// restores the nearest keyframe at or before the target and re-simulates
+// forward; keyframes missing from the file are captured while playing, so
+// rewinding stays cheap for older recordings too.
+type ReplayPlayer struct {
+	w   *world.World
+	rep world.ReplayFile
+	dt  float32
+
+	frame  int // frames already applied; frames[frame] runs next
+	speed  int
+	paused bool
+
+	keyframes []world.ReplayKeyframe
+	verifier  *world.ReplayVerifier
+	desync    error
+}
+
+// NewReplayPlayer applies the replay's initial snapshot to w and positions
+// playback at tick 0.
+func NewReplayPlayer(w *world.World, rep world.ReplayFile) (*ReplayPlayer, error) {
+	if w == nil {
+		return nil, fmt.Errorf("world is nil")
+	}
+	if rep.Header.FixedStepSeconds <= 0 {
+		return nil, fmt.Errorf("replay fixed step must be positive")
+	}
+
+	keyframes := make([]world.ReplayKeyframe, 0, len(rep.Keyframes)+1)
+	keyframes = append(keyframes, world.ReplayKeyframe{Frame: 0, Snapshot: rep.Initial})
+	for _, kf := range rep.Keyframes {
+		if kf.Frame > 0 && kf.Frame <= len(rep.Frames) {
+			keyframes = append(keyframes, kf)
+		}
+	}
+	slices.SortStableFunc(keyframes, func(a, b world.ReplayKeyframe) int { return a.Frame - b.Frame })
+	keyframes = slices.CompactFunc(keyframes, func(a, b world.ReplayKeyframe) bool { return a.Frame == b.Frame })
+
+	p := &ReplayPlayer{
+		w:         w,
+		rep:       rep,
+		dt:        rep.Header.FixedStepSeconds,
+		speed:     1,
+		keyframes: keyframes,
+	}
+	if err := p.restore(keyframes[0]); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Tick is the number of frames played so far.
+func (p *ReplayPlayer) Tick() int { return p.frame }
+
+// Len is the number of frames in the replay.
+func (p *ReplayPlayer) Len() int { return len(p.rep.Frames) }
+
+// Done reports whether every frame has been played.
+func (p *ReplayPlayer) Done() bool { return p.frame >= len(p.rep.Frames) }
+
+func (p *ReplayPlayer) Speed() int   { return p.speed }
+func (p *ReplayPlayer) Paused() bool { return p.paused }
+
+// SetSpeed sets how many ticks Advance plays, clamped to [1, MaxReplaySpeed].
+func (p *ReplayPlayer) SetSpeed(speed int) {
+	p.speed = min(max(speed, 1), MaxReplaySpeed)
+}
+
+func (p *ReplayPlayer) SetPaused(paused bool) { p.paused = paused }
+
+// Desync returns the first *world.DesyncError seen during playback, if any.
+func (p *ReplayPlayer) Desync() error { return p.desync }
+
+// Advance plays Speed ticks unless playback is paused. Call it once per
+// fixed step.
+func (p *ReplayPlayer) Advance() {
+	if p.paused {
+		return
+	}
+	for range p.speed {
+		if !p.Step() {
+			return
+		}
+	}
+}
+
+// Step plays exactly one tick, even while paused. It returns false at the
+// end of the replay.
+func (p *ReplayPlayer) Step() bool {
+	if p.Done() {
+		return false
+	}
+	frame := p.rep.Frames[p.frame]
+	p.w.EnqueueFrame(frame)
+	p.w.Tick(p.dt)
+	p.frame++
+
+	if err := p.verifier.Check(p.w, frame.Tick); err != nil && p.desync == nil {
+		p.desync = err
+	}
+	if d, ok := p.desync.(*world.DesyncError); ok && d.FieldsFrame == 0 {
+		if kf, ok := p.recordedKeyframe(p.frame); ok {
+			d.DiffKeyframe(kf, p.w)
+		}
+	}
+	if p.frame%world.ReplayKeyframeInterval == 0 {
+		p.captureKeyframe()
+	}
+	return true
+}
+
+// Seek moves playback so that tick frames have been played, clamped to the
+// replay length. Rewinding, or jumping past a later keyframe, restores the
+// nearest keyframe at or before tick and re-simulates the rest.
+func (p *ReplayPlayer) Seek(tick int) error {
+	tick = min(max(tick, 0), len(p.rep.Frames))
+	if tick == p.frame {
+		return nil
+	}
+
+	kf := p.keyframes[p.keyframeAtOrBefore(tick)]
+	if tick < p.frame || kf.Frame > p.frame {
+		if err := p.restore(kf); err != nil {
+			return err
+		}
+	}
+	for p.frame < tick {
+		p.Step()
+	}
+	return nil
+}
+
+// keyframeAtOrBefore returns the index of the last keyframe with Frame <= tick.
+// keyframes[0] is the initial snapshot, so the result is always valid.
+func (p *ReplayPlayer) keyframeAtOrBefore(tick int) int {
+	i, found := slices.BinarySearchFunc(p.keyframes, tick, func(kf world.ReplayKeyframe, t int) int {
+		return kf.Frame - t
+	})
+	if found {
+		return i
+	}
+	return i - 1
+}
+
+func (p *ReplayPlayer) restore(kf world.ReplayKeyframe) error {
+	if err := p.w.ApplySnapshot(kf.Snapshot); err != nil {
+		return fmt.Errorf("restore replay keyframe %d: %w", kf.Frame, err)
+	}
+	p.frame = kf.Frame
+	// Checkpoints before frame are skipped by the verifier itself.
+	p.verifier = world.NewReplayVerifier(p.rep.Checkpoints)
+	return nil
+}
+
+// recordedKeyframe returns the keyframe the replay file holds for frame.
+// Captured keyframes come from this playback, so they cannot show a desync.
+func (p *ReplayPlayer) recordedKeyframe(frame int) (world.ReplayKeyframe, bool) {
+	for _, kf := range p.rep.Keyframes {
+		if kf.Frame == frame {
+			return kf, true
+		}
+	}
+	return world.ReplayKeyframe{}, false
+}
+
+func (p *ReplayPlayer) captureKeyframe() {
+	i := p.keyframeAtOrBefore(p.frame)
+	if p.keyframes[i].Frame == p.frame {
+		return
+	}
+	kf := world.ReplayKeyframe{Frame: p.frame, Snapshot: p.w.BuildSnapshot()}
+	p.keyframes = slices.Insert(p.keyframes, i+1, kf)
+}
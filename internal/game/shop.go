@@ -0,0 +1,117 @@
+package game
+
+import (
+	"fmt"
+	"math"
+
+	"horde-lab/internal/world"
+)
+
+// ShopItem names a permanent upgrade bought with banked gold between runs.
+type ShopItem string
+
+const (
+	ShopMaxHP   ShopItem = "max_hp"
+	ShopDamage  ShopItem = "damage"
+	ShopMagnet  ShopItem = "magnet"
+	ShopRevival ShopItem = "revival"
+	ShopReroll  ShopItem = "reroll"
+)
+
+// shopItems is the display and selection order of the shop.
+var shopItems = []ShopItem{ShopMaxHP, ShopDamage, ShopMagnet, ShopRevival, ShopReroll}
+
+// shopDef prices an item: level n (0-based) costs BaseCost*CostGrowth^n.
+type shopDef struct {
+	Name       string
+	Desc       string
+	BaseCost   int
+	CostGrowth float64
+	MaxLevel   int
+	apply      func(m *world.MetaBonuses, level int)
+}
+
+var shopDefs = map[ShopItem]shopDef{
+	ShopMaxHP: {Name: "Vitality", Desc: "+10 starting max HP", BaseCost: 40, CostGrowth: 1.5, MaxLevel: 5,
+		apply: func(m *world.MetaBonuses, level int) { m.MaxHP = 10 * float32(level) }},
+	ShopDamage: {Name: "Might", Desc: "+5% damage", BaseCost: 60, CostGrowth: 1.6, MaxLevel: 5,
+		apply: func(m *world.MetaBonuses, level int) { m.Damage = 0.05 * float32(level) }},
+	ShopMagnet: {Name: "Attractorb", Desc: "+8 pickup radius", BaseCost: 50, CostGrowth: 1.5, MaxLevel: 3,
+		apply: func(m *world.MetaBonuses, level int) { m.Magnet = 8 * float32(level) }},
+	ShopRevival: {Name: "Second Chance", Desc: "Revive once at half HP", BaseCost: 500, CostGrowth: 1, MaxLevel: 1,
+		apply: func(m *world.MetaBonuses, level int) { m.Revivals = level }},
+	ShopReroll: {Name: "Reroll", Desc: "+1 level-up reroll", BaseCost: 80, CostGrowth: 2, MaxLevel: 3,
+		apply: func(m *world.MetaBonuses, level int) { m.Rerolls = level }},
+}
+
+// shopCost is the price of raising item from level to level+1; ok is false
+// for unknown items and maxed levels.
+func shopCost(item ShopItem, level int) (cost int, ok bool) {
+	def, found := shopDefs[item]
+	if !found || level < 0 || level >= def.MaxLevel {
+		return 0, false
+	}
+	return int(math.Round(float64(def.BaseCost) * math.Pow(def.CostGrowth, float64(level)))), true
+}
+
+// buyShopItem spends the profile's gold on the next level of item.
+func buyShopItem(p *PlayerProfile, item ShopItem) error {
+	level := p.Shop[item]
+	cost, ok := shopCost(item, level)
+	if !ok {
+		return fmt.Errorf("shop item %q cannot be upgraded past level %d", item, level)
+	}
+	if p.Gold < cost {
+		return fmt.Errorf("shop item %q costs %d gold, have %d", item, cost, p.Gold)
+	}
+	if p.Shop == nil {
+		p.Shop = make(map[ShopItem]int, len(shopItems))
+	}
+	p.Gold -= cost
+	p.Shop[item] = level + 1
+	return nil
+}
+
+// refundShop sells every bought level back at full price and returns the
+// gold refunded.
+func refundShop(p *PlayerProfile) int {
+	total := 0
+	for item, level := range p.Shop {
+		for l := range level {
+			cost, _ := shopCost(item, l)
+			total += cost
+		}
+	}
+	p.Gold += total
+	p.Shop = nil
+	return total
+}
+
+// metaBonuses folds the profile's shop levels into the bonuses a new run
+// starts with.
+func metaBonuses(p PlayerProfile) world.MetaBonuses {
+	var m world.MetaBonuses
+	for _, item := range shopItems {
+		if level := p.Shop[item]; level > 0 {
+			shopDefs[item].apply(&m, level)
+		}
+	}
+	return m
+}
+
+// normalizeShop drops unknown items and clamps levels to what can be bought.
+func normalizeShop(p *PlayerProfile) {
+	p.Gold = max(p.Gold, 0)
+	for item, level := range p.Shop {
+		def, ok := shopDefs[item]
+		switch {
+		case !ok || level <= 0:
+			delete(p.Shop, item)
+		case level > def.MaxLevel:
+			p.Shop[item] = def.MaxLevel
+		}
+	}
+	if len(p.Shop) == 0 {
+		p.Shop = nil
+	}
+}
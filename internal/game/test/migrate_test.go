@@ -0,0 +1,64 @@
+package game_test
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"horde-lab/internal/game"
+	"horde-lab/internal/world"
+)
+
+func TestGoldenSaveGameV1Loads(t *testing.T) {
+	sg, err := game.LoadSaveGame(filepath.Join("testdata", "savegame_v1.json"))
+	if err != nil {
+		t.Fatalf("LoadSaveGame failed: %v", err)
+	}
+	if sg.Profile.Name != "Hunter" || sg.Profile.Character != "mina_kang" {
+		t.Fatalf("unexpected profile: %#v", sg.Profile)
+	}
+	if sg.Snapshot.Version != world.SnapshotVersion {
+		t.Fatalf("snapshot version = %d, want %d", sg.Snapshot.Version, world.SnapshotVersion)
+	}
+
+	golden := world.NewWorld(1, 1)
+	defer golden.Close()
+	if err := golden.LoadSnapshot(filepath.Join("testdata", "snapshot_v2.json")); err != nil {
+		t.Fatalf("LoadSnapshot failed: %v", err)
+	}
+	loaded := world.NewWorld(1, 1)
+	defer loaded.Close()
+	if err := loaded.ApplySnapshot(sg.Snapshot); err != nil {
+		t.Fatalf("ApplySnapshot(savegame) failed: %v", err)
+	}
+	got, want := loaded.BuildSnapshot(), golden.BuildSnapshot()
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("savegame snapshot mismatch\n got: %#v\nwant: %#v", got, want)
+	}
+}
+
+func TestGoldenProfileV1Loads(t *testing.T) {
+	p, err := game.LoadProfile(filepath.Join("testdata", "profile_v1.json"))
+	if err != nil {
+		t.Fatalf("LoadProfile failed: %v", err)
+	}
+	want := game.PlayerProfile{
+		Version:       game.DefaultProfile().Version,
+		Name:          "Hunter",
+		Character:     "mina_kang",
+		Customization: "Crimson",
+	}
+	if !reflect.DeepEqual(p, want) {
+		t.Fatalf("profile mismatch\n got: %#v\nwant: %#v", p, want)
+	}
+}
+
+func TestGoldenHighscoresV1Loads(t *testing.T) {
+	hs, err := game.LoadHighscores(filepath.Join("testdata", "highscores_v1.json"))
+	if err != nil {
+		t.Fatalf("LoadHighscores failed: %v", err)
+	}
+	if len(hs.Entries) != 2 || hs.Entries[0].Score != 2649 || hs.Entries[1].Kills != 20 {
+		t.Fatalf("unexpected highscore entries: %#v", hs.Entries)
+	}
+}
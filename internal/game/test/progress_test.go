@@ -34,7 +34,7 @@ func TestSaveGameRoundTrip(t *testing.T) {
 	defer w.Close()
 
 	w.Player.Level = 3
-	w.Player.Weapon = world.WeaponSpear
+	w.Player.Weapons = append(w.Player.Weapons, world.WeaponSlot{Kind: world.WeaponSpear, Level: 2})
 	w.TimeSurvived = 42.5
 
 	want := game.SaveGame{
@@ -0,0 +1,139 @@
+package game_test
+
+import (
+	"testing"
+	"time"
+
+	"horde-lab/internal/game"
+	"horde-lab/internal/shared/input"
+	"horde-lab/internal/world"
+)
+
+// longReplay records enough frames to embed keyframes, plus the state hash
+// after every frame for comparison.
+func longReplay(t *testing.T, n int) (world.ReplayFile, []uint64) {
+	t.Helper()
+
+	frames := make([]world.ReplayFrame, n)
+	for i := range frames {
+		frames[i] = world.ReplayFrame{Tick: uint64(i), Choose: 0}
+		switch (i / 90) % 4 {
+		case 0:
+			frames[i].Input = input.State{Right: true}
+		case 1:
+			frames[i].Input = input.State{Down: true}
+		case 2:
+			frames[i].Input = input.State{Left: true}
+		default:
+			frames[i].Input = input.State{Up: true}
+		}
+	}
+
+	w := world.NewWorld(2000, 2000)
+	defer w.Close()
+	rep, err := game.RecordReplay(w, time.Second/60, frames)
+	if err != nil {
+		t.Fatalf("RecordReplay failed: %v", err)
+	}
+
+	ref := world.NewWorld(1, 1)
+	defer ref.Close()
+	if err := ref.ApplySnapshot(rep.Initial); err != nil {
+		t.Fatalf("ApplySnapshot failed: %v", err)
+	}
+	hashes := make([]uint64, n+1)
+	hashes[0] = ref.StateHash()
+	for i, frame := range frames {
+		ref.EnqueueFrame(frame)
+		ref.Tick(rep.Header.FixedStepSeconds)
+		hashes[i+1] = ref.StateHash()
+	}
+	return rep, hashes
+}
+
+func TestRecordReplayEmbedsKeyframes(t *testing.T) {
+	rep, hashes := longReplay(t, 2*world.ReplayKeyframeInterval+30)
+
+	if len(rep.Keyframes) != 2 {
+		t.Fatalf("keyframes = %d, want 2", len(rep.Keyframes))
+	}
+	for _, kf := range rep.Keyframes {
+		w := world.NewWorld(1, 1)
+		if err := w.ApplySnapshot(kf.Snapshot); err != nil {
+			t.Fatalf("ApplySnapshot(keyframe %d) failed: %v", kf.Frame, err)
+		}
+		if got := w.StateHash(); got != hashes[kf.Frame] {
+			t.Fatalf("keyframe %d hash = %#x, want %#x", kf.Frame, got, hashes[kf.Frame])
+		}
+		w.Close()
+	}
+}
+
+func TestReplayPlayerSeekMatchesStraightPlayback(t *testing.T) {
+	rep, hashes := longReplay(t, 2*world.ReplayKeyframeInterval+30)
+
+	w := world.NewWorld(1, 1)
+	defer w.Close()
+	p, err := game.NewReplayPlayer(w, rep)
+	if err != nil {
+		t.Fatalf("NewReplayPlayer failed: %v", err)
+	}
+
+	// forward past a keyframe, back across one, then to the very end
+	for _, tick := range []int{1000, 350, 350, 601, 20, len(rep.Frames), len(rep.Frames) + 50} {
+		if err := p.Seek(tick); err != nil {
+			t.Fatalf("Seek(%d) failed: %v", tick, err)
+		}
+		want := min(tick, len(rep.Frames))
+		if p.Tick() != want {
+			t.Fatalf("Seek(%d) left tick at %d", tick, p.Tick())
+		}
+		if got := w.StateHash(); got != hashes[want] {
+			t.Fatalf("state after Seek(%d) = %#x, want %#x", tick, got, hashes[want])
+		}
+	}
+	if !p.Done() || p.Desync() != nil {
+		t.Fatalf("done=%v desync=%v after seeking to the end", p.Done(), p.Desync())
+	}
+}
+
+func TestReplayPlayerSpeedAndStep(t *testing.T) {
+	rep, hashes := longReplay(t, 120)
+
+	w := world.NewWorld(1, 1)
+	defer w.Close()
+	p, err := game.NewReplayPlayer(w, rep)
+	if err != nil {
+		t.Fatalf("NewReplayPlayer failed: %v", err)
+	}
+
+	p.SetSpeed(4)
+	p.Advance()
+	if p.Tick() != 4 {
+		t.Fatalf("4x advance reached tick %d, want 4", p.Tick())
+	}
+
+	p.SetSpeed(100)
+	if p.Speed() != game.MaxReplaySpeed {
+		t.Fatalf("speed = %d, want clamp to %d", p.Speed(), game.MaxReplaySpeed)
+	}
+
+	p.SetPaused(true)
+	p.Advance()
+	if p.Tick() != 4 {
+		t.Fatalf("paused advance moved to tick %d", p.Tick())
+	}
+	if !p.Step() || p.Tick() != 5 {
+		t.Fatalf("Step while paused reached tick %d, want 5", p.Tick())
+	}
+	if got := w.StateHash(); got != hashes[5] {
+		t.Fatalf("state after step = %#x, want %#x", got, hashes[5])
+	}
+
+	if err := p.Seek(len(rep.Frames)); err != nil {
+		t.Fatalf("Seek to end failed: %v", err)
+	}
+	if p.Step() {
+		t.Fatal("Step at the end should report false")
+	}
+}
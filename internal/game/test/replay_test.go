@@ -1,7 +1,9 @@
 package game_test
 
 import (
+	"errors"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -72,3 +74,76 @@ func deterministicReplayFrames() []world.ReplayFrame {
 
 	return frames
 }
+
+func TestPlayReplayReportsDesyncAtTamperedCheckpoint(t *testing.T) {
+	const fixedStep = time.Second / 60
+
+	recordedWorld := world.NewWorld(2000, 2000)
+	defer recordedWorld.Close()
+
+	rep, err := game.RecordReplay(recordedWorld, fixedStep, deterministicReplayFrames())
+	if err != nil {
+		t.Fatalf("RecordReplay failed: %v", err)
+	}
+	if len(rep.Checkpoints) < 2 {
+		t.Fatalf("expected recorded checkpoints, got %d", len(rep.Checkpoints))
+	}
+
+	tampered := rep.Checkpoints[1]
+	rep.Checkpoints[1].Digest.Hash ^= 1
+	rep.Checkpoints[1].Digest.Summary.EnemyCount++
+
+	replayedWorld := world.NewWorld(1, 1)
+	defer replayedWorld.Close()
+
+	err = game.PlayReplay(replayedWorld, rep)
+	var desync *world.DesyncError
+	if !errors.As(err, &desync) {
+		t.Fatalf("expected *world.DesyncError, got %v", err)
+	}
+	if desync.Tick != tampered.Tick {
+		t.Fatalf("desync tick = %d, want %d", desync.Tick, tampered.Tick)
+	}
+	if len(desync.Diff) == 0 || !strings.HasPrefix(desync.Diff[0], "hash:") {
+		t.Fatalf("diff should start with the overall hash, got %q", desync.Diff)
+	}
+}
+
+func TestPlayReplayDiffsSnapshotsAtNextKeyframe(t *testing.T) {
+	const fixedStep = time.Second / 60
+
+	frames := make([]world.ReplayFrame, 0, 2*world.ReplayKeyframeInterval)
+	for tick := range uint64(2 * world.ReplayKeyframeInterval) {
+		frames = append(frames, world.ReplayFrame{Tick: tick, Choose: -1, Input: input.State{Right: tick%240 < 120, Left: tick%240 >= 120}})
+	}
+
+	recordedWorld := world.NewWorld(2000, 2000)
+	defer recordedWorld.Close()
+	rep, err := game.RecordReplay(recordedWorld, fixedStep, frames)
+	if err != nil {
+		t.Fatalf("RecordReplay failed: %v", err)
+	}
+	if len(rep.Keyframes) != 2 {
+		t.Fatalf("expected 2 recorded keyframes, got %d", len(rep.Keyframes))
+	}
+
+	// walk down for a while instead, so the replay no longer matches
+	for i := 100; i < 110; i++ {
+		rep.Frames[i].Input = input.State{Down: true}
+	}
+
+	replayedWorld := world.NewWorld(1, 1)
+	defer replayedWorld.Close()
+
+	err = game.PlayReplay(replayedWorld, rep)
+	var desync *world.DesyncError
+	if !errors.As(err, &desync) {
+		t.Fatalf("expected *world.DesyncError, got %v", err)
+	}
+	if desync.Tick != 119 {
+		t.Fatalf("desync tick = %d, want 119", desync.Tick)
+	}
+	if desync.FieldsFrame != world.ReplayKeyframeInterval || len(desync.Fields) == 0 {
+		t.Fatalf("expected a snapshot diff at frame %d, got frame %d %q", world.ReplayKeyframeInterval, desync.FieldsFrame, desync.Fields)
+	}
+}
@@ -0,0 +1,172 @@
+package game_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"horde-lab/internal/game"
+	"horde-lab/internal/world"
+)
+
+func TestShopCostsEscalateAndCap(t *testing.T) {
+	prev := 0
+	for level := range 5 {
+		cost, ok := game.ShopCost(game.ShopMaxHP, level)
+		if !ok || cost <= prev {
+			t.Fatalf("level %d costs %d (ok=%v), previous %d", level, cost, ok, prev)
+		}
+		prev = cost
+	}
+	if _, ok := game.ShopCost(game.ShopMaxHP, 5); ok {
+		t.Fatal("expected max HP to cap at level 5")
+	}
+	if _, ok := game.ShopCost("anvil", 0); ok {
+		t.Fatal("expected unknown items to have no price")
+	}
+}
+
+func TestShopBuyAndRefund(t *testing.T) {
+	p := game.DefaultProfile()
+	if err := game.BuyShopItem(&p, game.ShopRevival); err == nil {
+		t.Fatal("expected a purchase without gold to fail")
+	}
+
+	p.Gold = 1000
+	for _, item := range []game.ShopItem{game.ShopMaxHP, game.ShopMaxHP, game.ShopDamage, game.ShopRevival, game.ShopReroll} {
+		if err := game.BuyShopItem(&p, item); err != nil {
+			t.Fatalf("buy %s: %v", item, err)
+		}
+	}
+	if err := game.BuyShopItem(&p, game.ShopRevival); err == nil {
+		t.Fatal("expected the maxed revival to refuse another level")
+	}
+	want := world.MetaBonuses{MaxHP: 20, Damage: 0.05, Revivals: 1, Rerolls: 1}
+	if got := game.MetaBonuses(p); got != want {
+		t.Fatalf("meta bonuses %+v, want %+v", got, want)
+	}
+
+	spent := 1000 - p.Gold
+	if got := game.RefundShop(&p); got != spent || p.Gold != 1000 {
+		t.Fatalf("refunded %d of %d spent, gold now %d", got, spent, p.Gold)
+	}
+	if p.Shop != nil || game.MetaBonuses(p) != (world.MetaBonuses{}) {
+		t.Fatalf("refund left shop levels: %+v", p.Shop)
+	}
+}
+
+func TestProfileShopSurvivesSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "profile.json")
+	p := game.DefaultProfile()
+	p.Gold = 37
+	p.Shop = map[game.ShopItem]int{game.ShopMagnet: 2}
+	if err := game.SaveProfile(path, p); err != nil {
+		t.Fatalf("SaveProfile failed: %v", err)
+	}
+	got, err := game.LoadProfile(path)
+	if err != nil {
+		t.Fatalf("LoadProfile failed: %v", err)
+	}
+	if !reflect.DeepEqual(got, p) {
+		t.Fatalf("profile mismatch\n got: %#v\nwant: %#v", got, p)
+	}
+
+	raw := []byte(`{"version":2,"name":"Hunter","gold":-5,"shop":{"anvil":3,"magnet":9,"reroll":0}}`)
+	if err := os.WriteFile(path, raw, 0o644); err != nil {
+		t.Fatalf("write profile fixture: %v", err)
+	}
+	got, err = game.LoadProfile(path)
+	if err != nil {
+		t.Fatalf("LoadProfile failed: %v", err)
+	}
+	if got.Gold != 0 || !reflect.DeepEqual(got.Shop, map[game.ShopItem]int{game.ShopMagnet: 3}) {
+		t.Fatalf("expected normalized gold and shop, got %d %+v", got.Gold, got.Shop)
+	}
+}
+
+func TestMetaBonusesReplayIdentically(t *testing.T) {
+	const fixedStep = time.Second / 60
+
+	p := game.DefaultProfile()
+	p.Gold = 2000
+	for _, item := range game.ShopItems() {
+		if err := game.BuyShopItem(&p, item); err != nil {
+			t.Fatalf("buy %s: %v", item, err)
+		}
+	}
+	cfg := world.DefaultConfig()
+	cfg.Meta = game.MetaBonuses(p)
+	recorded := world.NewWorldWithConfig(2000, 2000, cfg, 3)
+	defer recorded.Close()
+
+	rep, err := game.RecordReplay(recorded, fixedStep, deterministicReplayFrames())
+	if err != nil {
+		t.Fatalf("RecordReplay failed: %v", err)
+	}
+	if rep.Initial.Cfg.Meta != cfg.Meta {
+		t.Fatalf("replay recorded meta %+v, want %+v", rep.Initial.Cfg.Meta, cfg.Meta)
+	}
+
+	// the player watching the replay owns no upgrades
+	replayed := world.NewWorld(1, 1)
+	defer replayed.Close()
+	if err := game.PlayReplay(replayed, rep); err != nil {
+		t.Fatalf("PlayReplay failed: %v", err)
+	}
+	if got, want := replayed.BuildSnapshot(), recorded.BuildSnapshot(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("meta replay did not reproduce final snapshot\n got: %#v\nwant: %#v", got, want)
+	}
+}
+
+func TestWatchingReplayBanksNoGold(t *testing.T) {
+	const fixedStep = time.Second / 60
+
+	dir := t.TempDir()
+	profilePath := filepath.Join(dir, "profile.json")
+	highscorePath := filepath.Join(dir, "highscores.json")
+	if err := game.SaveProfile(profilePath, game.DefaultProfile()); err != nil {
+		t.Fatalf("SaveProfile failed: %v", err)
+	}
+	if err := game.SaveHighscores(highscorePath, game.HighscoreFile{Version: 1}); err != nil {
+		t.Fatalf("SaveHighscores failed: %v", err)
+	}
+
+	// a run that ends with gold in hand a few ticks in
+	recorded := world.NewWorld(2000, 2000)
+	defer recorded.Close()
+	recorded.Player.Gold = 40
+	recorded.Enemies = []world.Enemy{{ID: 1, Kind: world.EnemyNormal, Pos: recorded.Player.Pos, R: 9, HP: 1e6, MaxHP: 1e6, TouchDamage: 1e6}}
+	frames := make([]world.ReplayFrame, 30)
+	for i := range frames {
+		frames[i] = world.ReplayFrame{Tick: uint64(i), Choose: -1}
+	}
+	rep, err := game.RecordReplay(recorded, fixedStep, frames)
+	if err != nil {
+		t.Fatalf("RecordReplay failed: %v", err)
+	}
+	if !recorded.GameOver {
+		t.Fatal("expected the recorded run to end")
+	}
+
+	watcher := world.NewWorld(1, 1)
+	defer watcher.Close()
+	if err := game.WatchReplay(watcher, rep, profilePath, highscorePath, 2); err != nil {
+		t.Fatalf("WatchReplay failed: %v", err)
+	}
+	if !watcher.GameOver {
+		t.Fatal("expected the replay to reach game over")
+	}
+	p, err := game.LoadProfile(profilePath)
+	if err != nil {
+		t.Fatalf("LoadProfile failed: %v", err)
+	}
+	hs, err := game.LoadHighscores(highscorePath)
+	if err != nil {
+		t.Fatalf("LoadHighscores failed: %v", err)
+	}
+	if p.Gold != 0 || len(hs.Entries) != 0 {
+		t.Fatalf("watching banked %d gold and %d highscores", p.Gold, len(hs.Entries))
+	}
+}
@@ -0,0 +1,234 @@
+package jobs
+
+import (
+	"math"
+	"sync"
+)
+
+// FlowGrid is the walkable map a flow field is built over: Cols x Rows cells
+// of CellSize, row-major, with cell (0, 0) at (OriginX, OriginY).
+type FlowGrid struct {
+	OriginX  float32
+	OriginY  float32
+	CellSize float32
+	Cols     int
+	Rows     int
+	Blocked  []bool
+}
+
+// Cell returns the cell under a position.
+func (g *FlowGrid) Cell(x, y float32) (col, row int, ok bool) {
+	col = int(math.Floor(float64((x - g.OriginX) / g.CellSize)))
+	row = int(math.Floor(float64((y - g.OriginY) / g.CellSize)))
+	return col, row, col >= 0 && row >= 0 && col < g.Cols && row < g.Rows
+}
+
+func (g *FlowGrid) center(col, row int) (float32, float32) {
+	return g.OriginX + (float32(col)+0.5)*g.CellSize, g.OriginY + (float32(row)+0.5)*g.CellSize
+}
+
+// FlowFieldRequest asks for the field leading to the goal cell over Grid.
+// Version names the grid: equal versions must mean equal grids. The pool
+// stores the field in Cache.
+type FlowFieldRequest struct {
+	Version uint64
+	Grid    *FlowGrid
+	GoalCol int
+	GoalRow int
+	Cache   *FlowCache
+}
+
+// FlowField holds every cell's path cost to the goal, in tenths of a cell
+// (10 straight, 14 diagonal); -1 marks blocked or unreachable cells.
+type FlowField struct {
+	Version uint64
+	Grid    *FlowGrid
+	GoalCol int
+	GoalRow int
+	Dist    []int32
+}
+
+const (
+	flowStraight = 10
+	flowDiagonal = 14
+)
+
+// flowNeighbours lists the 8 neighbour offsets, orthogonal first, in the
+// fixed order ties are broken by.
+var flowNeighbours = [8][2]int{
+	{1, 0}, {0, 1}, {-1, 0}, {0, -1},
+	{1, 1}, {-1, 1}, {-1, -1}, {1, -1},
+}
+
+// ComputeFlowField runs Dijkstra out from the goal cell. Costs are integers,
+// so the field is identical however it is scheduled. It returns nil when
+// the goal lies outside the grid.
+func ComputeFlowField(req FlowFieldRequest) *FlowField {
+	g := req.Grid
+	if g == nil || req.GoalCol < 0 || req.GoalRow < 0 || req.GoalCol >= g.Cols || req.GoalRow >= g.Rows {
+		return nil
+	}
+	f := &FlowField{
+		Version: req.Version,
+		Grid:    g,
+		GoalCol: req.GoalCol,
+		GoalRow: req.GoalRow,
+		Dist:    make([]int32, g.Cols*g.Rows),
+	}
+	for i := range f.Dist {
+		f.Dist[i] = -1
+	}
+
+	goal := req.GoalRow*g.Cols + req.GoalCol
+	f.Dist[goal] = 0
+	q := flowQueue{{idx: int32(goal)}}
+	for len(q) > 0 {
+		it := q.pop()
+		if it.dist != f.Dist[it.idx] {
+			continue // superseded
+		}
+		col, row := int(it.idx)%g.Cols, int(it.idx)/g.Cols
+		for k, d := range flowNeighbours {
+			nc, nr := col+d[0], row+d[1]
+			if !g.open(nc, nr) {
+				continue
+			}
+			cost := int32(flowStraight)
+			if k >= 4 {
+				// no cutting corners past a blocked cell
+				if !g.open(col+d[0], row) || !g.open(col, row+d[1]) {
+					continue
+				}
+				cost = flowDiagonal
+			}
+			ni := nr*g.Cols + nc
+			if nd := it.dist + cost; f.Dist[ni] < 0 || nd < f.Dist[ni] {
+				f.Dist[ni] = nd
+				q.push(flowItem{idx: int32(ni), dist: nd})
+			}
+		}
+	}
+	return f
+}
+
+func (g *FlowGrid) open(col, row int) bool {
+	return col >= 0 && row >= 0 && col < g.Cols && row < g.Rows && !g.Blocked[row*g.Cols+col]
+}
+
+// Direction samples the field at a position: the unit step towards the
+// neighbouring cell closest to the goal. ok is false outside the grid, on
+// unreachable cells and wherever the straight line is already a shortest
+// path, so open ground keeps the plain chase.
+func (f *FlowField) Direction(x, y float32) (dx, dy float32, ok bool) {
+	g := f.Grid
+	col, row, in := g.Cell(x, y)
+	if !in {
+		return 0, 0, false
+	}
+	here := f.Dist[row*g.Cols+col]
+	if here >= 0 && here == octile(col-f.GoalCol, row-f.GoalRow) {
+		return 0, 0, false
+	}
+	// movers pressed against a blocker can stand in a blocked cell; they
+	// take the best open neighbour, corners included
+	free := g.open(col, row)
+
+	best, bestCol, bestRow := here, -1, -1
+	for k, d := range flowNeighbours {
+		nc, nr := col+d[0], row+d[1]
+		if !g.open(nc, nr) {
+			continue
+		}
+		if k >= 4 && free && (!g.open(col+d[0], row) || !g.open(col, row+d[1])) {
+			continue
+		}
+		if nd := f.Dist[nr*g.Cols+nc]; nd >= 0 && (best < 0 || nd < best) {
+			best, bestCol, bestRow = nd, nc, nr
+		}
+	}
+	if bestCol < 0 {
+		return 0, 0, false
+	}
+	cx, cy := g.center(bestCol, bestRow)
+	dx, dy = normalize(cx-x, cy-y)
+	return dx, dy, dx != 0 || dy != 0
+}
+
+func octile(dc, dr int) int32 {
+	dc, dr = max(dc, -dc), max(dr, -dr)
+	return int32(flowStraight*max(dc, dr) + (flowDiagonal-flowStraight)*min(dc, dr))
+}
+
+// WarmFlowField is the flow-field job: it builds the field into req.Cache.
+func WarmFlowField(req FlowFieldRequest) *FlowField {
+	return req.Cache.Field(req)
+}
+
+// FlowCache keeps the latest field, so the flow job, the intent workers and
+// the synchronous fallback build it once per grid version and goal cell.
+// A nil cache computes every time.
+type FlowCache struct {
+	mu   sync.Mutex
+	last *FlowField
+}
+
+func (c *FlowCache) Field(req FlowFieldRequest) *FlowField {
+	if c == nil {
+		return ComputeFlowField(req)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if f := c.last; f != nil && f.Version == req.Version && f.GoalCol == req.GoalCol && f.GoalRow == req.GoalRow {
+		return f
+	}
+	f := ComputeFlowField(req)
+	if f != nil {
+		c.last = f
+	}
+	return f
+}
+
+type flowItem struct {
+	idx  int32
+	dist int32
+}
+
+// flowQueue is a binary min-heap on dist.
+type flowQueue []flowItem
+
+func (q *flowQueue) push(it flowItem) {
+	*q = append(*q, it)
+	h := *q
+	for i := len(h) - 1; i > 0; {
+		p := (i - 1) / 2
+		if h[p].dist <= h[i].dist {
+			break
+		}
+		h[p], h[i] = h[i], h[p]
+		i = p
+	}
+}
+
+func (q *flowQueue) pop() flowItem {
+	h := *q
+	top := h[0]
+	last := len(h) - 1
+	h[0] = h[last]
+	h = h[:last]
+	for i := 0; ; {
+		l, r, m := 2*i+1, 2*i+2, i
+		if l < len(h) && h[l].dist < h[m].dist {
+			m = l
+		}
+		if r < len(h) && h[r].dist < h[m].dist {
+			m = r
+		}
+		if m == i {
+			break
+		}
+		h[i], h[m] = h[m], h[i]
+		i = m
+	}
+	*q = h
+	return top
+}
@@ -2,7 +2,7 @@ package jobs
 
 import (
 	"math"
-	"sync"
+	"slices"
 )
 
 type EnemyRole uint8
@@ -29,6 +29,7 @@ type EnemySnapshot struct {
 	X       float32
 	Y       float32
 	Radius  float32
+	Stunned bool // stunned or frozen: holds in place
 }
 
 type IntentRequest struct {
@@ -36,6 +37,13 @@ type IntentRequest struct {
 	PlayerX float32
 	PlayerY float32
 	Enemies []EnemySnapshot
+
+	// Grid, when set, steers enemies along a flow field to the player's
+	// cell. Fields are cached in Flows per GridVersion and player cell, so
+	// one is rebuilt only when the grid changes or the player changes cells.
+	Grid        *FlowGrid
+	GridVersion uint64
+	Flows       *FlowCache
 }
 
 type EnemyIntent struct {
@@ -45,6 +53,10 @@ type EnemyIntent struct {
 	SpeedScale     float32
 	PreferredRange float32
 	Mode           IntentMode
+	// PathX, PathY is the flow field's heading where it leaves the straight
+	// line to the player; zero otherwise.
+	PathX float32
+	PathY float32
 }
 
 type IntentResult struct {
@@ -52,84 +64,64 @@ type IntentResult struct {
 	Intents []EnemyIntent
 }
 
-type IntentPool struct {
-	Req  chan IntentRequest
-	Res  chan IntentResult
-	quit chan struct{}
-
-	closeOnce sync.Once
-	wg        sync.WaitGroup
-}
-
-func NewIntentPool(workerCount, queueSize int) *IntentPool {
-	if workerCount < 1 {
-		workerCount = 1
-	}
-	if queueSize < 1 {
-		queueSize = 1
-	}
-
-	p := &IntentPool{
-		Req:  make(chan IntentRequest, queueSize),
-		Res:  make(chan IntentResult, queueSize),
-		quit: make(chan struct{}),
-	}
+// bucketMinEnemies is the crowd size where cell bucketing beats the plain
+// pairwise separation scan. Both paths produce bit-identical results.
+const bucketMinEnemies = 48
 
-	p.wg.Add(workerCount)
-	for range workerCount {
-		go p.worker()
+// ComputeIntents derives one intent per enemy. Separation uses cell buckets
+// for larger crowds and a pairwise scan for small ones.
+func ComputeIntents(req IntentRequest) IntentResult {
+	if len(req.Enemies) >= bucketMinEnemies {
+		return ComputeIntentsBucketed(req)
 	}
-
-	return p
+	return ComputeIntentsLinear(req)
 }
 
-func (p *IntentPool) Close() {
-	p.closeOnce.Do(func() {
-		close(p.quit)
-		p.wg.Wait()
+// ComputeIntentsLinear is the reference O(n^2) implementation.
+func ComputeIntentsLinear(req IntentRequest) IntentResult {
+	return computeIntents(req, func(i int, radius float32) (float32, float32) {
+		return separation(req.Enemies, i, radius)
 	})
 }
 
-func (p *IntentPool) worker() {
-	defer p.wg.Done()
-
-	for {
-		select {
-		case <-p.quit:
-			return
-
-		case req := <-p.Req:
-			res := ComputeIntents(req)
-
-			// Never block worker shutdown on a full result queue.
-			select {
-			case <-p.quit:
-				return
-			case p.Res <- res:
-			default:
-			}
-		}
-	}
+// ComputeIntentsBucketed buckets enemies into a uniform grid sized to the
+// largest separation radius, so each enemy only scans its 3x3 neighbourhood.
+func ComputeIntentsBucketed(req IntentRequest) IntentResult {
+	b := newSeparationBuckets(req.Enemies)
+	return computeIntents(req, b.separation)
 }
 
-func ComputeIntents(req IntentRequest) IntentResult {
+func computeIntents(req IntentRequest, sepFn func(selfIdx int, radius float32) (float32, float32)) IntentResult {
 	out := IntentResult{
 		Tick:    req.Tick,
 		Intents: make([]EnemyIntent, len(req.Enemies)),
 	}
+	flow := req.flowField()
 
 	for i, e := range req.Enemies {
+		if e.Stunned {
+			// still pushes neighbours apart, but does not move itself
+			out.Intents[i] = EnemyIntent{EnemyID: e.EnemyID, Mode: IntentModeHold}
+			continue
+		}
+
 		dx := req.PlayerX - e.X
 		dy := req.PlayerY - e.Y
 
 		dist := distance(dx, dy)
 		chaseX, chaseY := normalize(dx, dy)
+		var pathX, pathY float32
+		if flow != nil {
+			if fx, fy, ok := flow.Direction(e.X, e.Y); ok {
+				chaseX, chaseY = fx, fy
+				pathX, pathY = fx, fy
+			}
+		}
 		if chaseX == 0 && chaseY == 0 {
 			chaseX, chaseY = fallbackDirection(e.EnemyID)
 		}
 
-		sepRadius := maxf(24.0, e.Radius*3.2)
-		sepX, sepY := separation(req.Enemies, i, sepRadius)
+		sepX, sepY := sepFn(i, separationRadius(e))
 
 		mode := IntentModePursue
 		preferred := float32(65.0)
@@ -196,12 +188,31 @@ func ComputeIntents(req IntentRequest) IntentResult {
 			SpeedScale:     clampf(speedScale, 0.2, 1.5),
 			PreferredRange: preferred,
 			Mode:           mode,
+			PathX:          pathX,
+			PathY:          pathY,
 		}
 	}
 
 	return out
 }
 
+// flowField returns the field for the player's current cell, or nil when
+// the request carries no grid.
+func (req IntentRequest) flowField() *FlowField {
+	if req.Grid == nil {
+		return nil
+	}
+	col, row, ok := req.Grid.Cell(req.PlayerX, req.PlayerY)
+	if !ok {
+		return nil
+	}
+	return req.Flows.Field(FlowFieldRequest{Version: req.GridVersion, Grid: req.Grid, GoalCol: col, GoalRow: row})
+}
+
+func separationRadius(e EnemySnapshot) float32 {
+	return maxf(24.0, e.Radius*3.2)
+}
+
 func separation(enemies []EnemySnapshot, selfIdx int, radius float32) (float32, float32) {
 	self := enemies[selfIdx]
 	r2 := radius * radius
@@ -211,20 +222,123 @@ func separation(enemies []EnemySnapshot, selfIdx int, radius float32) (float32,
 		if i == selfIdx {
 			continue
 		}
+		sx, sy = accumulateSeparation(self, other, r2, sx, sy)
+	}
 
-		dx := self.X - other.X
-		dy := self.Y - other.Y
-		d2 := dx*dx + dy*dy
-		if d2 == 0 || d2 > r2 {
-			continue
-		}
+	return normalize(sx, sy)
+}
 
-		inv := float32(1.0 / math.Sqrt(float64(d2)))
-		weight := 1 - (d2 / r2)
-		sx += dx * inv * weight
-		sy += dy * inv * weight
+func accumulateSeparation(self, other EnemySnapshot, r2, sx, sy float32) (float32, float32) {
+	dx := self.X - other.X
+	dy := self.Y - other.Y
+	d2 := dx*dx + dy*dy
+	if d2 == 0 || d2 > r2 {
+		return sx, sy
 	}
 
+	inv := float32(1.0 / math.Sqrt(float64(d2)))
+	weight := 1 - (d2 / r2)
+	return sx + dx*inv*weight, sy + dy*inv*weight
+}
+
+// maxBucketCells bounds the dense bucket grid. Requests spread wider than
+// this fall back to the pairwise scan instead of allocating a huge grid.
+const maxBucketCells = 1 << 20
+
+// separationBuckets indexes enemies into a dense grid in CSR layout: cell c
+// owns order[start[c]:start[c+1]], with indices ascending inside each cell.
+// The cell size is the largest separation radius in the request, so every
+// neighbour within range lives in the 3x3 block around an enemy's own cell.
+type separationBuckets struct {
+	enemies []EnemySnapshot
+	inv     float32
+	minX    int32
+	minY    int32
+	cols    int32
+	rows    int32
+	cell    []int32
+	start   []int32
+	order   []int32
+	scratch []int32
+}
+
+func newSeparationBuckets(enemies []EnemySnapshot) *separationBuckets {
+	cellSize := float32(24)
+	for _, e := range enemies {
+		cellSize = maxf(cellSize, separationRadius(e))
+	}
+	b := &separationBuckets{enemies: enemies, inv: 1 / cellSize}
+	if len(enemies) == 0 {
+		return b
+	}
+
+	minX, minY := b.coord(enemies[0].X), b.coord(enemies[0].Y)
+	maxX, maxY := minX, minY
+	for _, e := range enemies[1:] {
+		cx, cy := b.coord(e.X), b.coord(e.Y)
+		minX, maxX = min(minX, cx), max(maxX, cx)
+		minY, maxY = min(minY, cy), max(maxY, cy)
+	}
+	cols := int64(maxX) - int64(minX) + 1
+	rows := int64(maxY) - int64(minY) + 1
+	if cols*rows > maxBucketCells {
+		return b
+	}
+	b.minX, b.minY = minX, minY
+	b.cols, b.rows = int32(cols), int32(rows)
+
+	// counting sort keeps indices ascending within each cell
+	b.cell = make([]int32, len(enemies))
+	b.start = make([]int32, cols*rows+1)
+	for i, e := range enemies {
+		c := (b.coord(e.Y)-minY)*b.cols + (b.coord(e.X) - minX)
+		b.cell[i] = c
+		b.start[c+1]++
+	}
+	for c := 1; c < len(b.start); c++ {
+		b.start[c] += b.start[c-1]
+	}
+	fill := make([]int32, cols*rows)
+	copy(fill, b.start)
+	b.order = make([]int32, len(enemies))
+	for i, c := range b.cell {
+		b.order[fill[c]] = int32(i)
+		fill[c]++
+	}
+	b.scratch = make([]int32, 0, 64)
+	return b
+}
+
+func (b *separationBuckets) coord(v float32) int32 {
+	return int32(math.Floor(float64(v * b.inv)))
+}
+
+func (b *separationBuckets) separation(selfIdx int, radius float32) (float32, float32) {
+	if b.start == nil {
+		return separation(b.enemies, selfIdx, radius)
+	}
+	self := b.enemies[selfIdx]
+	c := b.cell[selfIdx]
+	cx, cy := c%b.cols, c/b.cols
+
+	b.scratch = b.scratch[:0]
+	for y := max(cy-1, 0); y <= min(cy+1, b.rows-1); y++ {
+		row := y * b.cols
+		lo := b.start[row+max(cx-1, 0)]
+		hi := b.start[row+min(cx+1, b.cols-1)+1]
+		b.scratch = append(b.scratch, b.order[lo:hi]...)
+	}
+	// Sum in index order so float accumulation matches the pairwise scan.
+	slices.Sort(b.scratch)
+
+	r2 := radius * radius
+	var sx, sy float32
+	for _, i := range b.scratch {
+		if int(i) == selfIdx {
+			continue
+		}
+		sx, sy = accumulateSeparation(self, b.enemies[i], r2, sx, sy)
+	}
 	return normalize(sx, sy)
 }
 
@@ -0,0 +1,332 @@
+package jobs
+
+import (
+	"math"
+	"sync"
+	"sync/atomic"
+)
+
+// Kind names a job type in metrics.
+type Kind string
+
+const (
+	KindIntents   Kind = "intents"
+	KindFlowField Kind = "flow_field"
+	KindWave      Kind = "wave"
+	KindSave      Kind = "save"
+)
+
+// Priority picks a lane. Idle workers take the highest non-empty lane.
+type Priority uint8
+
+const (
+	PriorityHigh Priority = iota
+	PriorityNormal
+	PriorityLow
+
+	numPriorities
+)
+
+// Workers runs the jobs of every pool bound to it on one set of goroutines.
+// A single worker runs each lane in submission order.
+type Workers struct {
+	lanes [numPriorities]chan func()
+	tick  atomic.Uint64
+	quit  chan struct{}
+
+	// closed turns Submit away once Close has begun, so no job is queued
+	// after the workers' final drain
+	mu     sync.RWMutex
+	closed bool
+
+	closeOnce sync.Once
+	wg        sync.WaitGroup
+}
+
+func NewWorkers(workerCount, queueSize int) *Workers {
+	if workerCount < 1 {
+		workerCount = 1
+	}
+	if queueSize < 1 {
+		queueSize = 1
+	}
+
+	w := &Workers{quit: make(chan struct{})}
+	for i := range w.lanes {
+		w.lanes[i] = make(chan func(), queueSize)
+	}
+
+	w.wg.Add(workerCount)
+	for range workerCount {
+		go w.worker()
+	}
+
+	return w
+}
+
+// Close expires every job with a deadline, runs the rest of the queue and
+// stops the workers. Submit rejects jobs from then on.
+func (w *Workers) Close() {
+	w.closeOnce.Do(func() {
+		w.mu.Lock()
+		w.closed = true
+		w.mu.Unlock()
+		w.tick.Store(math.MaxUint64)
+		close(w.quit)
+		w.wg.Wait()
+	})
+}
+
+// SetTick advances the clock job deadlines are checked against.
+func (w *Workers) SetTick(tick uint64) {
+	w.tick.Store(tick)
+}
+
+func (w *Workers) worker() {
+	defer w.wg.Done()
+
+	for {
+		if job, ok := w.next(); ok {
+			job()
+			continue
+		}
+		select {
+		case <-w.quit:
+			for job, ok := w.next(); ok; job, ok = w.next() {
+				job()
+			}
+			return
+		case job := <-w.lanes[PriorityHigh]:
+			job()
+		case job := <-w.lanes[PriorityNormal]:
+			job()
+		case job := <-w.lanes[PriorityLow]:
+			job()
+		}
+	}
+}
+
+// next takes a queued job from the highest non-empty lane without blocking.
+func (w *Workers) next() (func(), bool) {
+	for _, lane := range w.lanes {
+		select {
+		case job := <-lane:
+			return job, true
+		default:
+		}
+	}
+	return nil, false
+}
+
+// Job is one request for a pool. Tick tags its result; a job still queued
+// after the workers' clock passes Deadline is skipped. A zero Deadline never
+// expires.
+type Job[Req any] struct {
+	Tick     uint64
+	Deadline uint64
+	Priority Priority
+	Req      Req
+}
+
+type Result[Res any] struct {
+	Tick uint64
+	Res  Res
+}
+
+// PoolStats counts what happened to a pool's jobs. Rejected jobs found their
+// lane full or the workers closed, Expired ones missed their deadline and
+// Dropped results found Res full; Late results were computed by the consumer
+// instead (see Pending).
+type PoolStats struct {
+	Kind      Kind
+	Submitted uint64
+	Rejected  uint64
+	Expired   uint64
+	Completed uint64
+	Dropped   uint64
+	Late      uint64
+}
+
+// Pool runs one kind of job on shared Workers and delivers tick-tagged
+// results on Res. It never blocks the submitter or a worker: work it cannot
+// take or deliver is counted in Stats.
+type Pool[Req, Res any] struct {
+	Kind Kind
+	Res  chan Result[Res] // nil for a fire-and-forget pool
+
+	workers *Workers
+	run     func(Req) Res
+
+	// epoch rises on Flush; jobs from an older epoch deliver nothing
+	mu    sync.Mutex
+	epoch uint64
+
+	submitted, rejected, expired, completed, dropped atomic.Uint64
+}
+
+// NewPool binds a job kind to workers. A results size of 0 makes a
+// fire-and-forget pool whose results are discarded.
+func NewPool[Req, Res any](workers *Workers, kind Kind, results int, run func(Req) Res) *Pool[Req, Res] {
+	p := &Pool[Req, Res]{Kind: kind, workers: workers, run: run}
+	if results > 0 {
+		p.Res = make(chan Result[Res], results)
+	}
+	return p
+}
+
+// Submit queues a job, reporting false when its lane is full or the
+// workers are closed.
+func (p *Pool[Req, Res]) Submit(job Job[Req]) bool {
+	p.submitted.Add(1)
+	p.mu.Lock()
+	epoch := p.epoch
+	p.mu.Unlock()
+
+	w := p.workers
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if !w.closed {
+		select {
+		case w.lanes[min(job.Priority, PriorityLow)] <- func() { p.do(job, epoch) }:
+			return true
+		default:
+		}
+	}
+	p.rejected.Add(1)
+	return false
+}
+
+func (p *Pool[Req, Res]) do(job Job[Req], epoch uint64) {
+	if job.Deadline > 0 && p.workers.tick.Load() > job.Deadline {
+		p.expired.Add(1)
+		return
+	}
+	res := p.run(job.Req)
+	p.completed.Add(1)
+	if p.Res == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if epoch != p.epoch {
+		return
+	}
+	select {
+	case p.Res <- Result[Res]{Tick: job.Tick, Res: res}:
+	default:
+		p.dropped.Add(1)
+	}
+}
+
+// Flush discards waiting results and those of jobs already submitted, for
+// callers whose tick tags no longer mean the same state.
+func (p *Pool[Req, Res]) Flush() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.epoch++
+	for p.Res != nil {
+		select {
+		case <-p.Res:
+		default:
+			return
+		}
+	}
+}
+
+func (p *Pool[Req, Res]) Stats() PoolStats {
+	return PoolStats{
+		Kind:      p.Kind,
+		Submitted: p.submitted.Load(),
+		Rejected:  p.rejected.Load(),
+		Expired:   p.expired.Load(),
+		Completed: p.completed.Load(),
+		Dropped:   p.dropped.Load(),
+	}
+}
+
+// Pending keeps the contract deterministic callers need from a pool: the
+// result for a tick is the same whether a worker delivered it in time or
+// not. Callers Track every request they submit and Take results by tick;
+// a result that has not arrived is computed on the spot from the tracked
+// request.
+type Pending[Req, Res any] struct {
+	run     func(Req) Res
+	pending map[uint64]Req
+	ready   map[uint64]Res
+	late    uint64
+}
+
+func NewPending[Req, Res any](run func(Req) Res) *Pending[Req, Res] {
+	return &Pending[Req, Res]{
+		run:     run,
+		pending: make(map[uint64]Req, 8),
+		ready:   make(map[uint64]Res, 8),
+	}
+}
+
+// Track records the request submitted for tick.
+func (p *Pending[Req, Res]) Track(tick uint64, req Req) {
+	p.pending[tick] = req
+}
+
+// Deliver stores the result for tick.
+func (p *Pending[Req, Res]) Deliver(tick uint64, res Res) {
+	p.ready[tick] = res
+}
+
+// Collect delivers every result waiting on pool, skipping those tagged
+// before oldest.
+func (p *Pending[Req, Res]) Collect(pool *Pool[Req, Res], oldest uint64) {
+	for {
+		select {
+		case r := <-pool.Res:
+			if r.Tick >= oldest {
+				p.ready[r.Tick] = r.Res
+			}
+		default:
+			return
+		}
+	}
+}
+
+// Take returns and forgets the result for tick, computing it now if it
+// was tracked but has not arrived. ok is false when nothing was submitted.
+func (p *Pending[Req, Res]) Take(tick uint64) (res Res, ok bool) {
+	if res, ok = p.ready[tick]; ok {
+		delete(p.ready, tick)
+		delete(p.pending, tick)
+		return res, true
+	}
+	req, ok := p.pending[tick]
+	if !ok {
+		return res, false
+	}
+	delete(p.pending, tick)
+	p.late++
+	return p.run(req), true
+}
+
+// Prune forgets requests and results tagged before tick.
+func (p *Pending[Req, Res]) Prune(before uint64) {
+	for tick := range p.pending {
+		if tick < before {
+			delete(p.pending, tick)
+		}
+	}
+	for tick := range p.ready {
+		if tick < before {
+			delete(p.ready, tick)
+		}
+	}
+}
+
+// Reset forgets everything in flight.
+func (p *Pending[Req, Res]) Reset() {
+	clear(p.pending)
+	clear(p.ready)
+}
+
+// Late counts the results Take had to compute itself.
+func (p *Pending[Req, Res]) Late() uint64 {
+	return p.late
+}
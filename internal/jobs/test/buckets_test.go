@@ -0,0 +1,68 @@
+package jobs_test
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"horde-lab/internal/jobs"
+)
+
+func TestComputeIntentsBucketedMatchesLinear(t *testing.T) {
+	for _, n := range []int{1, 7, 64, 500} {
+		req := crowdRequest(n)
+
+		want := jobs.ComputeIntentsLinear(req)
+		got := jobs.ComputeIntentsBucketed(req)
+
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("bucketed intents diverged from linear scan for %d enemies", n)
+		}
+	}
+}
+
+func BenchmarkComputeIntentsLinear500(b *testing.B) {
+	benchmarkIntents(b, 500, jobs.ComputeIntentsLinear)
+}
+func BenchmarkComputeIntentsBucketed500(b *testing.B) {
+	benchmarkIntents(b, 500, jobs.ComputeIntentsBucketed)
+}
+func BenchmarkComputeIntentsLinear2000(b *testing.B) {
+	benchmarkIntents(b, 2000, jobs.ComputeIntentsLinear)
+}
+func BenchmarkComputeIntentsBucketed2000(b *testing.B) {
+	benchmarkIntents(b, 2000, jobs.ComputeIntentsBucketed)
+}
+
+func benchmarkIntents(b *testing.B, n int, fn func(jobs.IntentRequest) jobs.IntentResult) {
+	req := crowdRequest(n)
+	b.ResetTimer()
+	for range b.N {
+		_ = fn(req)
+	}
+}
+
+// crowdRequest lays enemies out on a sunflower spiral around the player so
+// density is realistic and the layout is fully deterministic.
+func crowdRequest(n int) jobs.IntentRequest {
+	req := jobs.IntentRequest{
+		Tick:    1,
+		PlayerX: 1000,
+		PlayerY: 1000,
+		Enemies: make([]jobs.EnemySnapshot, n),
+	}
+	roles := []jobs.EnemyRole{jobs.EnemyRoleNormal, jobs.EnemyRoleRunner, jobs.EnemyRoleTank}
+	radii := []float32{9, 7, 14}
+	for i := range n {
+		ang := float64(i) * 2.399963
+		r := 30 + 16*math.Sqrt(float64(i))
+		req.Enemies[i] = jobs.EnemySnapshot{
+			EnemyID: i + 1,
+			Role:    roles[i%3],
+			X:       1000 + float32(math.Cos(ang)*r),
+			Y:       1000 + float32(math.Sin(ang)*r),
+			Radius:  radii[i%3],
+		}
+	}
+	return req
+}
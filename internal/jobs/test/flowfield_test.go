@@ -0,0 +1,99 @@
+package jobs_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"horde-lab/internal/jobs"
+)
+
+// wallGrid is 10x10 cells of 10 units with a wall down column 5 that leaves
+// rows 8 and 9 open.
+func wallGrid() *jobs.FlowGrid {
+	g := &jobs.FlowGrid{CellSize: 10, Cols: 10, Rows: 10, Blocked: make([]bool, 100)}
+	for r := range 8 {
+		g.Blocked[r*10+5] = true
+	}
+	return g
+}
+
+func TestFlowFieldRoutesAroundWall(t *testing.T) {
+	f := jobs.ComputeFlowField(jobs.FlowFieldRequest{Grid: wallGrid(), GoalCol: 8, GoalRow: 2})
+
+	if f.Dist[2*10+8] != 0 || f.Dist[2*10+5] != -1 {
+		t.Fatalf("goal cost %d, wall cost %d", f.Dist[2*10+8], f.Dist[2*10+5])
+	}
+	// straight through would cost 60; around the wall's end costs more
+	if d := f.Dist[2*10+2]; d <= 60 {
+		t.Fatalf("path cost behind the wall = %d", d)
+	}
+
+	dx, dy, ok := f.Direction(25, 25)
+	if !ok || dy <= 0 || dy < dx {
+		t.Fatalf("expected to head down around the wall, got (%.2f, %.2f) ok=%v", dx, dy, ok)
+	}
+	if _, _, ok := f.Direction(75, 15); ok {
+		t.Fatal("expected a plain chase with a clear straight line")
+	}
+	if _, _, ok := f.Direction(-5, 15); ok {
+		t.Fatal("expected no heading outside the grid")
+	}
+}
+
+func TestFlowCacheRebuildsOnlyForNewKeys(t *testing.T) {
+	var c jobs.FlowCache
+	req := jobs.FlowFieldRequest{Version: 1, Grid: wallGrid(), GoalCol: 8, GoalRow: 2}
+
+	a := c.Field(req)
+	if c.Field(req) != a {
+		t.Fatal("expected the cached field for the same version and goal")
+	}
+	req.GoalRow = 3
+	b := c.Field(req)
+	if b == a || b.GoalRow != 3 {
+		t.Fatal("expected a new field for another goal cell")
+	}
+	req.Version = 2
+	if c.Field(req) == b {
+		t.Fatal("expected a new field for another grid version")
+	}
+}
+
+func TestComputeIntentsFollowFlowField(t *testing.T) {
+	req := jobs.IntentRequest{
+		Tick:    3,
+		PlayerX: 85,
+		PlayerY: 25,
+		Enemies: []jobs.EnemySnapshot{
+			{EnemyID: 1, Role: jobs.EnemyRoleNormal, X: 25, Y: 25, Radius: 4},
+			{EnemyID: 2, Role: jobs.EnemyRoleNormal, X: 75, Y: 35, Radius: 4},
+		},
+		Grid:        wallGrid(),
+		GridVersion: 1,
+		Flows:       &jobs.FlowCache{},
+	}
+
+	want := jobs.ComputeIntents(req)
+	if in := want.Intents[0]; in.PathY <= 0 || in.MoveY <= 0 {
+		t.Fatalf("expected the walled-off enemy to follow the path, got %+v", in)
+	}
+	if in := want.Intents[1]; in.PathX != 0 || in.PathY != 0 {
+		t.Fatalf("expected a straight chase in the open, got %+v", in)
+	}
+
+	workers := jobs.NewWorkers(2, 4)
+	defer workers.Close()
+	flows := jobs.NewPool(workers, jobs.KindFlowField, 0, jobs.WarmFlowField)
+	intents := jobs.NewPool(workers, jobs.KindIntents, 4, jobs.ComputeIntents)
+	flows.Submit(jobs.Job[jobs.FlowFieldRequest]{Req: jobs.FlowFieldRequest{Version: 1, Grid: req.Grid, GoalCol: 8, GoalRow: 2, Cache: req.Flows}})
+	intents.Submit(jobs.Job[jobs.IntentRequest]{Tick: 3, Req: req})
+	select {
+	case got := <-intents.Res:
+		if !reflect.DeepEqual(got.Res, want) {
+			t.Fatalf("pooled intents differ from the synchronous ones:\n got: %+v\nwant: %+v", got, want)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for intents")
+	}
+}
@@ -56,9 +56,31 @@ func TestComputeIntents(t *testing.T) {
 	}
 }
 
+func TestComputeIntentsStunnedEnemiesHold(t *testing.T) {
+	req := jobs.IntentRequest{
+		PlayerX: 200,
+		Enemies: []jobs.EnemySnapshot{
+			{EnemyID: 1, Role: jobs.EnemyRoleRunner, X: 0, Y: 0, Radius: 7, Stunned: true},
+			{EnemyID: 2, Role: jobs.EnemyRoleNormal, X: 10, Y: 5, Radius: 9},
+		},
+	}
+
+	got := jobs.ComputeIntents(req)
+
+	held := got.Intents[0]
+	if held.Mode != jobs.IntentModeHold || held.MoveX != 0 || held.MoveY != 0 || held.SpeedScale != 0 {
+		t.Fatalf("expected stunned enemy to hold, got %+v", held)
+	}
+	// the stunned enemy still pushes its neighbour away
+	if got.Intents[1].MoveY <= 0 || got.Intents[1].Mode == jobs.IntentModeHold {
+		t.Fatalf("expected neighbour to pursue with separation, got %+v", got.Intents[1])
+	}
+}
+
 func TestIntentPoolDeliversResults(t *testing.T) {
-	pool := jobs.NewIntentPool(2, 8)
-	defer pool.Close()
+	workers := jobs.NewWorkers(2, 8)
+	defer workers.Close()
+	pool := jobs.NewPool(workers, jobs.KindIntents, 8, jobs.ComputeIntents)
 
 	req := jobs.IntentRequest{
 		Tick:    7,
@@ -69,11 +91,12 @@ func TestIntentPoolDeliversResults(t *testing.T) {
 		},
 	}
 
-	pool.Req <- req
+	pool.Submit(jobs.Job[jobs.IntentRequest]{Tick: req.Tick, Req: req})
 
 	select {
-	case res := <-pool.Res:
-		if res.Tick != 7 {
+	case out := <-pool.Res:
+		res := out.Res
+		if out.Tick != 7 || res.Tick != 7 {
 			t.Fatalf("tick mismatch: got %d want %d", res.Tick, 7)
 		}
 		if len(res.Intents) != 1 {
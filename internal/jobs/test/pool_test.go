@@ -0,0 +1,176 @@
+package jobs_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"horde-lab/internal/jobs"
+)
+
+// blockWorker occupies the only worker until the returned func is called,
+// so later submissions queue up.
+func blockWorker(t *testing.T, workers *jobs.Workers) (release func()) {
+	t.Helper()
+	started, gate := make(chan struct{}), make(chan struct{})
+	block := jobs.NewPool(workers, "block", 0, func(struct{}) struct{} {
+		close(started)
+		<-gate
+		return struct{}{}
+	})
+	block.Submit(jobs.Job[struct{}]{Priority: jobs.PriorityHigh})
+	select {
+	case <-started:
+	case <-time.After(time.Second):
+		t.Fatal("worker never picked up the blocking job")
+	}
+	return func() { close(gate) }
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for the pool")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestPoolRunsHigherPrioritiesFirst(t *testing.T) {
+	workers := jobs.NewWorkers(1, 4)
+	defer workers.Close()
+	release := blockWorker(t, workers)
+
+	var mu sync.Mutex
+	var order []jobs.Priority
+	pool := jobs.NewPool(workers, "order", 0, func(p jobs.Priority) struct{} {
+		mu.Lock()
+		order = append(order, p)
+		mu.Unlock()
+		return struct{}{}
+	})
+	for _, p := range []jobs.Priority{jobs.PriorityLow, jobs.PriorityNormal, jobs.PriorityHigh, jobs.PriorityLow} {
+		pool.Submit(jobs.Job[jobs.Priority]{Priority: p, Req: p})
+	}
+	release()
+	waitFor(t, func() bool { return pool.Stats().Completed == 4 })
+
+	want := []jobs.Priority{jobs.PriorityHigh, jobs.PriorityNormal, jobs.PriorityLow, jobs.PriorityLow}
+	mu.Lock()
+	defer mu.Unlock()
+	for i := range want {
+		if order[i] != want[i] {
+			t.Fatalf("ran priorities %v, want %v", order, want)
+		}
+	}
+}
+
+func TestPoolCountsBackpressureAndExpiry(t *testing.T) {
+	workers := jobs.NewWorkers(1, 2)
+	defer workers.Close()
+	release := blockWorker(t, workers)
+
+	pool := jobs.NewPool(workers, "double", 1, func(n int) int { return 2 * n })
+	submit := func(tick, deadline uint64, p jobs.Priority) bool {
+		return pool.Submit(jobs.Job[int]{Tick: tick, Deadline: deadline, Priority: p, Req: int(tick)})
+	}
+	if !submit(1, 5, jobs.PriorityNormal) || !submit(2, 0, jobs.PriorityNormal) {
+		t.Fatal("expected room for two normal jobs")
+	}
+	if submit(3, 0, jobs.PriorityNormal) {
+		t.Fatal("expected the full lane to reject a third job")
+	}
+	if !submit(4, 0, jobs.PriorityLow) {
+		t.Fatal("expected the low lane to take a job")
+	}
+	workers.SetTick(6)
+	release()
+	waitFor(t, func() bool { s := pool.Stats(); return s.Completed+s.Expired == 3 })
+
+	got := pool.Stats()
+	want := jobs.PoolStats{Kind: "double", Submitted: 4, Rejected: 1, Expired: 1, Completed: 2, Dropped: 1}
+	if got != want {
+		t.Fatalf("stats = %+v, want %+v", got, want)
+	}
+	if res := <-pool.Res; res.Tick != 2 || res.Res != 4 {
+		t.Fatalf("unexpected result %+v", res)
+	}
+}
+
+func TestPoolFlushDiscardsInFlightResults(t *testing.T) {
+	workers := jobs.NewWorkers(1, 4)
+	defer workers.Close()
+	release := blockWorker(t, workers)
+
+	pool := jobs.NewPool(workers, "echo", 4, func(n int) int { return n })
+	pool.Submit(jobs.Job[int]{Tick: 1, Req: 1})
+	pool.Flush()
+	pool.Submit(jobs.Job[int]{Tick: 2, Req: 2})
+	release()
+	waitFor(t, func() bool { return pool.Stats().Completed == 2 })
+
+	if res := <-pool.Res; res.Tick != 2 {
+		t.Fatalf("got result for tick %d, want only tick 2", res.Tick)
+	}
+	select {
+	case res := <-pool.Res:
+		t.Fatalf("flushed result delivered: %+v", res)
+	default:
+	}
+}
+
+func TestWorkersCloseFinishesUndatedJobs(t *testing.T) {
+	workers := jobs.NewWorkers(1, 4)
+	release := blockWorker(t, workers)
+
+	pool := jobs.NewPool(workers, "echo", 4, func(n int) int { return n })
+	pool.Submit(jobs.Job[int]{Tick: 1, Deadline: 100, Req: 1})
+	pool.Submit(jobs.Job[int]{Tick: 2, Req: 2})
+	release()
+	workers.Close()
+
+	if s := pool.Stats(); s.Completed != 1 || s.Expired != 1 {
+		t.Fatalf("stats after close = %+v", s)
+	}
+	if res := <-pool.Res; res.Tick != 2 {
+		t.Fatalf("unexpected result %+v", res)
+	}
+	if pool.Submit(jobs.Job[int]{Tick: 3, Req: 3}) {
+		t.Fatal("expected closed workers to reject a job")
+	}
+	if s := pool.Stats(); s.Rejected != 1 {
+		t.Fatalf("stats after a late submit = %+v", s)
+	}
+}
+
+func TestPendingComputesLateResults(t *testing.T) {
+	calls := 0
+	p := jobs.NewPending(func(n int) int {
+		calls++
+		return n * 10
+	})
+	p.Track(1, 1)
+	p.Track(2, 2)
+	p.Deliver(2, 99)
+
+	if got, ok := p.Take(1); !ok || got != 10 {
+		t.Fatalf("Take(1) = %d, %v; want the computed 10", got, ok)
+	}
+	if got, ok := p.Take(2); !ok || got != 99 {
+		t.Fatalf("Take(2) = %d, %v; want the delivered 99", got, ok)
+	}
+	if _, ok := p.Take(3); ok {
+		t.Fatal("expected nothing for an untracked tick")
+	}
+	if calls != 1 || p.Late() != 1 {
+		t.Fatalf("computed %d results, late %d; want 1 each", calls, p.Late())
+	}
+
+	p.Track(4, 4)
+	p.Prune(5)
+	if _, ok := p.Take(4); ok {
+		t.Fatal("expected pruned request to be gone")
+	}
+}
@@ -0,0 +1,29 @@
+package render
+
+import (
+	"image/color"
+	"strconv"
+	"strings"
+)
+
+// hexColors caches parsed "#rrggbb" strings from content files. Drawing
+// happens on the Ebiten goroutine only, so no lock is needed.
+var hexColors = map[string]color.RGBA{}
+
+// hexColor parses "#rrggbb" or "#rrggbbaa"; anything else is magenta so a
+// typo in a content file is obvious on screen.
+func hexColor(s string) color.RGBA {
+	if c, ok := hexColors[s]; ok {
+		return c
+	}
+	c := color.RGBA{255, 0, 255, 255}
+	h := strings.TrimPrefix(s, "#")
+	if len(h) == 6 {
+		h += "ff"
+	}
+	if v, err := strconv.ParseUint(h, 16, 32); err == nil && len(h) == 8 {
+		c = color.RGBA{uint8(v >> 24), uint8(v >> 16), uint8(v >> 8), uint8(v)}
+	}
+	hexColors[s] = c
+	return c
+}
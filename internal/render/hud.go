@@ -1 +1,174 @@
 package render
+
+import (
+	"fmt"
+	"image/color"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+
+	"horde-lab/internal/world"
+)
+
+// drawHUD prints the run status in the top-left corner (screen space).
+func drawHUD(screen *ebiten.Image, s *world.Snapshot) {
+	hud := fmt.Sprintf(
+		"HP: %.0f/%.0f\nLV: %d  XP: %.0f/%.0f\nWave: %d %s (%.1fs)\nKills: %d  Gold: %d\nEnemies: %d  Obstacles: %d\nOrbs: %d  Drops: %d  Pickups: %d\nSpawnEvery: %.2fs\nTime: %.1fs",
+		s.Player.HP, s.Player.MaxHP,
+		s.Player.Level, s.Player.XP, s.Player.XPToNext,
+		s.Wave.Index, s.Wave.Label, maxf(0, s.Wave.StartTime+s.Wave.Duration-s.TimeSurvived),
+		s.Stats.EnemiesKilled, s.Player.Gold,
+		len(s.Enemies), len(s.Obstacles), len(s.Orbs), len(s.Drops), len(s.Pickups),
+		s.SpawnEvery,
+		s.TimeSurvived,
+	)
+
+	ebitenutil.DebugPrintAt(screen, hud, 8, 8)
+	drawWeaponSlots(screen, s)
+	drawBossBar(screen, s)
+}
+
+// drawBossBar draws the boss's name, HP and phase markers across the top of
+// the screen while a boss is alive.
+func drawBossBar(screen *ebiten.Image, s *world.Snapshot) {
+	if !s.Boss.Active {
+		return
+	}
+	var boss *world.Enemy
+	for i := range s.Enemies {
+		if s.Enemies[i].ID == s.Boss.EnemyID {
+			boss = &s.Enemies[i]
+			break
+		}
+	}
+	a, ok := s.Cfg.Archetype(s.Boss.Kind)
+	if boss == nil || !ok || a.Boss == nil {
+		return
+	}
+
+	sw := float32(screen.Bounds().Dx())
+	w, h := sw*0.5, float32(10)
+	x, y := (sw-w)*0.5, float32(24)
+	frac := max(boss.HP/boss.MaxHP, 0)
+
+	vector.FillRect(screen, x-2, y-2, w+4, h+4, color.RGBA{0, 0, 0, 180}, false)
+	vector.FillRect(screen, x, y, w, h, color.RGBA{60, 20, 30, 255}, false)
+	vector.FillRect(screen, x, y, w*frac, h, color.RGBA{200, 40, 70, 255}, false)
+	// ticks where later phases begin
+	for _, p := range a.Boss.Phases[1:] {
+		px := x + w*p.HPBelow
+		vector.StrokeLine(screen, px, y-2, px, y+h+2, 1, color.RGBA{255, 230, 160, 255}, false)
+	}
+
+	label := fmt.Sprintf("%s  %.0f/%.0f  Phase %d/%d", a.Name, boss.HP, boss.MaxHP, s.Boss.Phase+1, len(a.Boss.Phases))
+	ebitenutil.DebugPrintAt(screen, label, int(x), int(y)-16)
+}
+
+// drawWeaponSlots draws one box per inventory slot along the bottom-left
+// edge: name, level and a bar that fills as the weapon's cooldown runs out.
+// Empty slots are drawn as outlines.
+func drawWeaponSlots(screen *ebiten.Image, s *world.Snapshot) {
+	const (
+		slotW, slotH = 96, 34
+		gap          = 6
+	)
+	y := float32(screen.Bounds().Dy()) - slotH - 8
+	for i := range max(s.Cfg.WeaponSlots, len(s.Player.Weapons)) {
+		x := float32(8 + i*(slotW+gap))
+		if i >= len(s.Player.Weapons) {
+			vector.StrokeRect(screen, x, y, slotW, slotH, 1, color.RGBA{90, 90, 100, 200}, false)
+			continue
+		}
+		slot := s.Player.Weapons[i]
+		vector.FillRect(screen, x, y, slotW, slotH, color.RGBA{0, 0, 0, 150}, false)
+		vector.StrokeRect(screen, x, y, slotW, slotH, 1, color.RGBA{200, 200, 210, 220}, false)
+
+		ready := float32(1)
+		if cd := s.Player.WeaponCooldown(slot); slot.Timer > 0 && cd > 0 {
+			ready = 1 - min(slot.Timer/cd, 1)
+		}
+		vector.FillRect(screen, x+3, y+slotH-6, (slotW-6)*ready, 3, color.RGBA{220, 180, 60, 255}, false)
+
+		ebitenutil.DebugPrintAt(screen, world.WeaponName(slot.Kind), int(x)+4, int(y)+2)
+		level := fmt.Sprintf("Lv %d", slot.Level)
+		if slot.MaxLevel() {
+			level = "Lv MAX"
+		}
+		ebitenutil.DebugPrintAt(screen, level, int(x)+4, int(y)+14)
+	}
+
+	if len(s.Player.Passives) > 0 {
+		names := make([]string, len(s.Player.Passives))
+		for i, p := range s.Player.Passives {
+			names[i] = fmt.Sprintf("%s %d", world.PassiveName(p.Kind), p.Level)
+		}
+		ebitenutil.DebugPrintAt(screen, "Passives: "+strings.Join(names, ", "), 8, int(y)-18)
+	}
+	if len(s.Player.Statuses) > 0 {
+		names := make([]string, len(s.Player.Statuses))
+		for i, st := range s.Player.Statuses {
+			names[i] = fmt.Sprintf("%s %.1fs", world.StatusName(st.Kind), st.Remaining)
+			if st.Stacks > 1 {
+				names[i] = fmt.Sprintf("%s x%d %.1fs", world.StatusName(st.Kind), st.Stacks, st.Remaining)
+			}
+		}
+		ebitenutil.DebugPrintAt(screen, "Status: "+strings.Join(names, ", "), 8, int(y)-34)
+	}
+}
+
+// drawOverlays draws modal panels. Priority: GameOver > Upgrade > Paused.
+func drawOverlays(screen *ebiten.Image, s *world.Snapshot, assets AssetProvider) {
+	sw, sh := screen.Bounds().Dx(), screen.Bounds().Dy()
+
+	// Game over overlay
+	if s.GameOver {
+		px, py, _, _ := drawModalPanel(screen, assets, float32(sw), float32(sh), 0.62, 0.46)
+		x := int(px + 24)
+		y := int(py + 22)
+		ebitenutil.DebugPrintAt(screen, "GAME OVER", x, y)
+		ebitenutil.DebugPrintAt(screen, "Press R to restart", x, y+22)
+		ebitenutil.DebugPrintAt(screen, fmt.Sprintf("Time Survived: %.1fs", s.TimeSurvived), x, y+50)
+		ebitenutil.DebugPrintAt(screen, fmt.Sprintf("Level Reached: %d", s.Player.Level), x, y+72)
+		ebitenutil.DebugPrintAt(screen, fmt.Sprintf("Kills: %d", s.Stats.EnemiesKilled), x, y+94)
+		ebitenutil.DebugPrintAt(screen, fmt.Sprintf("Enemies Spawned: %d", s.Stats.EnemiesSpawned), x, y+116)
+		ebitenutil.DebugPrintAt(screen, fmt.Sprintf("Damage Taken: %.0f", s.Stats.DamageTaken), x, y+138)
+		ebitenutil.DebugPrintAt(screen, fmt.Sprintf("XP Collected: %.0f", s.Stats.XPCollected), x, y+160)
+		return
+	}
+
+	// Upgrade menu overlay
+	if s.Upgrade.Active {
+		m := s.Upgrade
+		px, py, _, _ := drawModalPanel(screen, assets, float32(sw), float32(sh), 0.60, 0.52)
+		titles := make([]string, len(m.Options))
+		for i, o := range m.Options {
+			titles[i] = o.Title
+		}
+		drawUpgradeChoiceButtons(screen, assets, titles)
+
+		// menu text
+		x := int(px + 24)
+		y := int(py + 22)
+		ebitenutil.DebugPrintAt(screen, "LEVEL UP! Choose an upgrade:", x, y)
+		for i, o := range m.Options {
+			ebitenutil.DebugPrintAt(screen, o.Title, x, y+22+i*42)
+			ebitenutil.DebugPrintAt(screen, "  "+o.Desc, x, y+42+i*42)
+		}
+		y += 22 + len(m.Options)*42
+		ebitenutil.DebugPrintAt(screen, fmt.Sprintf("Press 1-%d", len(m.Options)), x, y)
+		ebitenutil.DebugPrintAt(screen, fmt.Sprintf("Q: Reroll (%d)  X: Skip (%d)  Shift+N: Banish (%d)", m.Rerolls, m.Skips, m.Banishes), x, y+20)
+	}
+
+	// Pause overlay
+	if s.Paused {
+		px, py, _, _ := drawModalPanel(screen, assets, float32(sw), float32(sh), 0.58, 0.36)
+		drawPauseButtons(screen, assets)
+		x := int(px + 24)
+		y := int(py + 22)
+		ebitenutil.DebugPrintAt(screen, "PAUSED", x, y)
+		ebitenutil.DebugPrintAt(screen, "Space/C: Continue", x, y+24)
+		ebitenutil.DebugPrintAt(screen, "R: Restart", x, y+44)
+	}
+}
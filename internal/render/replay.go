@@ -0,0 +1,75 @@
+package render
+
+import (
+	"fmt"
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// ReplayTransport is the playback state shown by DrawReplayTransport.
+type ReplayTransport struct {
+	Tick        int
+	Len         int
+	StepSeconds float64
+	Speed       int
+	Paused      bool
+	Desync      bool
+}
+
+// replayBar lays out the clickable progress bar; the transport panel wraps it.
+func replayBar(sw, sh int) (x, y, w, h float32) {
+	w = float32(sw) * 0.6
+	h = 10
+	x = (float32(sw) - w) * 0.5
+	y = float32(sh) - 96
+	return x, y, w, h
+}
+
+// ReplayBarFraction maps a click at (px, py) to a position in [0, 1] along
+// the progress bar. ok is false when the click misses the bar.
+func ReplayBarFraction(sw, sh, px, py int) (frac float32, ok bool) {
+	x, y, w, h := replayBar(sw, sh)
+	fx, fy := float32(px), float32(py)
+	// a few pixels of slack make the thin bar easier to hit
+	if fx < x || fx > x+w || fy < y-6 || fy > y+h+6 {
+		return 0, false
+	}
+	return (fx - x) / w, true
+}
+
+// DrawReplayTransport draws the progress bar, playback state and key hints
+// at the bottom of the screen.
+func DrawReplayTransport(screen *ebiten.Image, t ReplayTransport) {
+	sw, sh := screen.Bounds().Dx(), screen.Bounds().Dy()
+	x, y, w, h := replayBar(sw, sh)
+
+	vector.FillRect(screen, x-12, y-30, w+24, h+58, color.RGBA{0, 0, 0, 170}, false)
+	vector.FillRect(screen, x, y, w, h, color.RGBA{60, 60, 68, 255}, false)
+	if t.Len > 0 {
+		fill := w * float32(t.Tick) / float32(t.Len)
+		vector.FillRect(screen, x, y, fill, h, color.RGBA{220, 180, 60, 255}, false)
+	}
+
+	state := "PLAY"
+	switch {
+	case t.Tick >= t.Len:
+		state = "END"
+	case t.Paused:
+		state = "PAUSED"
+	}
+	status := fmt.Sprintf("REPLAY %s %dx  %.1fs / %.1fs  tick %d/%d",
+		state, t.Speed,
+		float64(t.Tick)*t.StepSeconds, float64(t.Len)*t.StepSeconds,
+		t.Tick, t.Len,
+	)
+	if t.Desync {
+		status += "  DESYNC"
+	}
+	ebitenutil.DebugPrintAt(screen, status, int(x), int(y)-24)
+	ebitenutil.DebugPrintAt(screen,
+		"Space: play/pause  ,/.: step  [/]: speed  Left/Right: 5s  Home: start  Esc: exit",
+		int(x), int(y+h)+6)
+}
@@ -0,0 +1,90 @@
+package render
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+func drawImageFitted(dst *ebiten.Image, img *ebiten.Image, x, y, w, h float32) {
+	b := img.Bounds()
+	iw := float32(b.Dx())
+	ih := float32(b.Dy())
+	if iw <= 0 || ih <= 0 {
+		return
+	}
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Scale(float64(w/iw), float64(h/ih))
+	op.GeoM.Translate(float64(x), float64(y))
+	dst.DrawImage(img, op)
+}
+
+func drawModalPanel(screen *ebiten.Image, assets AssetProvider, sw, sh, wr, hr float32) (x, y, w, h float32) {
+	vector.FillRect(screen, 0, 0, sw, sh, color.RGBA{0, 0, 0, 160}, false)
+	pw := sw * wr
+	ph := sh * hr
+	px := (sw - pw) * 0.5
+	py := (sh - ph) * 0.5
+	if panel := assets.Get("ui_panel"); panel != nil {
+		drawImageFitted(screen, panel, px, py, pw, ph)
+		return px, py, pw, ph
+	}
+	vector.FillRect(screen, px, py, pw, ph, color.RGBA{28, 28, 32, 230}, false)
+	return px, py, pw, ph
+}
+
+func drawUpgradeChoiceButtons(screen *ebiten.Image, assets AssetProvider, titles []string) {
+	if len(titles) == 0 {
+		return
+	}
+	sw := float32(screen.Bounds().Dx())
+	sh := float32(screen.Bounds().Dy())
+	gap := sw * 0.02
+	bw := min(sw*0.20, (sw*0.84-gap*float32(len(titles)-1))/float32(len(titles)))
+	bh := sh * 0.065
+	y := sh * 0.66
+	x := (sw - bw*float32(len(titles)) - gap*float32(len(titles)-1)) * 0.5
+	for i, title := range titles {
+		bx := x + float32(i)*(bw+gap)
+		key := "ui_button_hover"
+		if i == 0 {
+			key = "ui_button_selected"
+			if c := assets.Get("ui_cursor"); c != nil {
+				drawImageFitted(screen, c, bx-18, y+bh*0.5-12, 18, 24)
+			}
+		}
+		drawButton(screen, assets, bx, y, bw, bh, key)
+		ebitenutil.DebugPrintAt(screen, title, int(bx)+12, int(y)+12)
+	}
+}
+
+func drawPauseButtons(screen *ebiten.Image, assets AssetProvider) {
+	sw := float32(screen.Bounds().Dx())
+	sh := float32(screen.Bounds().Dy())
+	bw := sw * 0.22
+	bh := sh * 0.065
+	y1 := sh * 0.58
+	y2 := y1 + bh + 10
+	x := (sw - bw) * 0.5
+	drawButton(screen, assets, x, y1, bw, bh, "ui_button_selected")
+	drawButton(screen, assets, x, y2, bw, bh, "ui_button_normal")
+	ebitenutil.DebugPrintAt(screen, "C / Space: Continue", int(x)+14, int(y1)+12)
+	ebitenutil.DebugPrintAt(screen, "R: Restart", int(x)+14, int(y2)+12)
+}
+
+func drawButton(screen *ebiten.Image, assets AssetProvider, x, y, w, h float32, key string) {
+	if b := assets.Get(key); b != nil {
+		drawImageFitted(screen, b, x, y, w, h)
+		return
+	}
+	vector.FillRect(screen, x, y, w, h, color.RGBA{54, 54, 60, 220}, false)
+}
+
+func maxf(a, b float32) float32 {
+	if a > b {
+		return a
+	}
+	return b
+}
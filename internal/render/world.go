@@ -0,0 +1,611 @@
+package render
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+
+	"horde-lab/internal/world"
+)
+
+// AssetProvider resolves decoded images by key. Missing or still-loading
+// assets return nil and callers fall back to vector shapes.
+type AssetProvider interface {
+	Get(key string) *ebiten.Image
+}
+
+// Draw renders one frame from a world snapshot. It never touches the live
+// world, so the simulation package stays free of Ebiten.
+func Draw(screen *ebiten.Image, s *world.Snapshot, assets AssetProvider) {
+	screen.Fill(color.RGBA{15, 15, 18, 255})
+
+	// camera centered on player
+	sw, sh := screen.Bounds().Dx(), screen.Bounds().Dy()
+	camX := float32(sw)/2 - s.Player.Pos.X
+	camY := float32(sh)/2 - s.Player.Pos.Y
+
+	// offset camera for damage shake
+	camX += s.ShakeOff.X
+	camY += s.ShakeOff.Y
+
+	// world background; an endless field has no edge to draw
+	floor := biomePalette(s.Terrain.Biome)[world.TileFloor]
+	if s.Cfg.ChunkSize > 0 {
+		screen.Fill(floor)
+	} else {
+		vector.FillRect(
+			screen,
+			camX, camY,
+			s.W, s.H,
+			floor,
+			false, // anti-alias
+		)
+	}
+	drawTerrain(screen, s, camX, camY)
+
+	drawObstacles(screen, s, camX, camY)
+	drawOrbs(screen, s, camX, camY)
+	drawWeaponDrops(screen, s, camX, camY)
+	drawPickups(screen, s, camX, camY)
+	drawEnemyShots(screen, s, camX, camY)
+	drawBossTelegraph(screen, s, camX, camY)
+	drawEnemies(screen, s, camX, camY)
+	drawPlayerShots(screen, s, camX, camY)
+	drawAttack(screen, s, camX, camY)
+	drawPlayer(screen, s, assets, camX, camY)
+
+	drawHUD(screen, s)
+	drawOverlays(screen, s, assets)
+}
+
+// biomePalettes colors each tile kind per biome; no terrain keeps the old
+// flat floor.
+var biomePalettes = map[world.Biome][]color.RGBA{
+	world.BiomeGraveyard: {
+		world.TileFloor:  {34, 36, 40, 255},
+		world.TileRough:  {40, 44, 40, 255},
+		world.TileWall:   {70, 66, 72, 255},
+		world.TileWater:  {26, 38, 58, 255},
+		world.TileBridge: {72, 58, 44, 255},
+	},
+	world.BiomeForest: {
+		world.TileFloor:  {30, 42, 30, 255},
+		world.TileRough:  {38, 52, 32, 255},
+		world.TileWall:   {22, 62, 34, 255},
+		world.TileWater:  {24, 52, 70, 255},
+		world.TileBridge: {92, 70, 46, 255},
+	},
+	world.BiomeRuins: {
+		world.TileFloor:  {46, 42, 36, 255},
+		world.TileRough:  {56, 50, 42, 255},
+		world.TileWall:   {96, 86, 72, 255},
+		world.TileWater:  {30, 56, 66, 255},
+		world.TileBridge: {84, 76, 64, 255},
+	},
+}
+
+var flatPalette = []color.RGBA{world.TileFloor: {30, 30, 36, 255}}
+
+func biomePalette(b world.Biome) []color.RGBA {
+	if p, ok := biomePalettes[b]; ok {
+		return p
+	}
+	return flatPalette
+}
+
+// terrainCache holds the last generated map; snapshots only carry the
+// params. Drawing happens on the Ebiten goroutine only, so no lock.
+var terrainCache *world.TileMap
+
+// drawTerrain draws the non-floor tiles in view over the floor fill.
+func drawTerrain(screen *ebiten.Image, s *world.Snapshot, camX, camY float32) {
+	if s.Terrain.TileSize <= 0 {
+		return
+	}
+	if terrainCache == nil || terrainCache.Params != s.Terrain {
+		terrainCache = world.GenerateTerrain(s.Terrain)
+	}
+	m := terrainCache
+	if m == nil {
+		return
+	}
+	palette := biomePalette(s.Terrain.Biome)
+	ts := s.Terrain.TileSize
+	// draw in map-local coordinates; streamed windows start off the origin
+	off := m.Offset()
+	camX += off.X
+	camY += off.Y
+	sw, sh := float32(screen.Bounds().Dx()), float32(screen.Bounds().Dy())
+	c0, r0 := max(0, int(-camX/ts)), max(0, int(-camY/ts))
+	c1, r1 := min(m.Params.Cols-1, int((sw-camX)/ts)), min(m.Params.Rows-1, int((sh-camY)/ts))
+	for r := r0; r <= r1; r++ {
+		for c := c0; c <= c1; c++ {
+			tile := m.At(c, r)
+			if tile == world.TileFloor {
+				continue
+			}
+			x, y := camX+float32(c)*ts, camY+float32(r)*ts
+			vector.FillRect(screen, x, y, ts, ts, palette[tile], false)
+			if tile == world.TileBridge {
+				// planks across the bridge
+				for k := float32(1); k < 4; k++ {
+					vector.StrokeLine(screen, x, y+ts*k/4, x+ts, y+ts*k/4, 1, palette[world.TileWater], false)
+				}
+			}
+		}
+	}
+}
+
+func drawObstacles(screen *ebiten.Image, s *world.Snapshot, camX, camY float32) {
+	fill := color.RGBA{58, 54, 50, 255}
+	rim := color.RGBA{110, 104, 94, 255}
+	for i := range s.Obstacles {
+		obstacle := &s.Obstacles[i]
+		ox := camX + obstacle.Pos.X
+		oy := camY + obstacle.Pos.Y
+		if obstacle.Shape == world.ShapeCircle {
+			vector.FillCircle(screen, ox, oy, obstacle.R, fill, false)
+			vector.StrokeCircle(screen, ox, oy, obstacle.R+2, 2, rim, false)
+			vector.StrokeCircle(screen, ox, oy, obstacle.R*0.55, 1, color.RGBA{88, 82, 72, 180}, false)
+			continue
+		}
+
+		var path vector.Path
+		for k, v := range obstacle.Vertices() {
+			if k == 0 {
+				path.MoveTo(camX+v.X, camY+v.Y)
+			} else {
+				path.LineTo(camX+v.X, camY+v.Y)
+			}
+		}
+		path.Close()
+		op := &vector.DrawPathOptions{}
+		op.ColorScale.ScaleWithColor(fill)
+		vector.FillPath(screen, &path, nil, op)
+		op = &vector.DrawPathOptions{}
+		op.ColorScale.ScaleWithColor(rim)
+		vector.StrokePath(screen, &path, &vector.StrokeOptions{Width: 2, LineJoin: vector.LineJoinRound}, op)
+	}
+}
+
+// orbTiers picks an orb's look by value, highest threshold first; merged
+// gems grow and change color as they absorb more XP.
+var orbTiers = []struct {
+	minValue float32
+	scale    float32
+	fill     color.RGBA
+}{
+	{250, 1.8, color.RGBA{235, 70, 80, 255}},
+	{50, 1.5, color.RGBA{90, 220, 120, 255}},
+	{10, 1.25, color.RGBA{90, 170, 255, 255}},
+	{0, 1, color.RGBA{240, 210, 80, 255}},
+}
+
+func drawOrbs(screen *ebiten.Image, s *world.Snapshot, camX, camY float32) {
+	for _, o := range s.Orbs {
+		tier := orbTiers[len(orbTiers)-1]
+		for _, t := range orbTiers {
+			if o.Value >= t.minValue {
+				tier = t
+				break
+			}
+		}
+		x, y, r := camX+o.Pos.X, camY+o.Pos.Y, o.R*tier.scale
+		vector.FillCircle(screen, x, y, r, tier.fill, false)
+		if tier.scale > 1 {
+			vector.StrokeCircle(screen, x, y, r, 1, color.RGBA{255, 255, 255, 160}, false)
+		}
+	}
+}
+
+func drawWeaponDrops(screen *ebiten.Image, s *world.Snapshot, camX, camY float32) {
+	for _, d := range s.Drops {
+		dx := camX + d.Pos.X
+		dy := camY + d.Pos.Y
+		switch d.Kind {
+		case world.WeaponSpear:
+			vector.StrokeLine(screen, dx-d.R, dy+d.R, dx+d.R, dy-d.R, 2, color.RGBA{110, 220, 255, 255}, false)
+		case world.WeaponNova:
+			vector.FillCircle(screen, dx, dy, d.R, color.RGBA{230, 90, 220, 220}, false)
+			vector.StrokeCircle(screen, dx, dy, d.R+2, 1, color.RGBA{255, 160, 250, 255}, false)
+		case world.WeaponFang, world.WeaponTwinFang:
+			vector.FillRect(screen, dx-d.R*0.5, dy-d.R, d.R, d.R*2, color.RGBA{255, 120, 120, 255}, false)
+		default:
+			vector.FillRect(screen, dx-d.R, dy-d.R*0.35, d.R*2, d.R*0.7, color.RGBA{255, 220, 120, 255}, false)
+		}
+	}
+}
+
+// drawPickups gives each pickup kind its own shape in its content color.
+func drawPickups(screen *ebiten.Image, s *world.Snapshot, camX, camY float32) {
+	colors := make(map[world.PickupKind]color.RGBA, len(s.Cfg.Pickups))
+	for _, d := range s.Cfg.Pickups {
+		colors[d.Kind] = hexColor(d.Color)
+	}
+	dark := color.RGBA{20, 20, 24, 255}
+	for _, p := range s.Pickups {
+		px := camX + p.Pos.X
+		py := camY + p.Pos.Y
+		c := colors[p.Kind]
+		switch p.Kind {
+		case world.PickupChest:
+			vector.FillRect(screen, px-p.R, py-p.R*0.7, p.R*2, p.R*1.4, c, false)
+			vector.StrokeLine(screen, px-p.R, py-p.R*0.15, px+p.R, py-p.R*0.15, 2, dark, false)
+			vector.FillRect(screen, px-2, py-p.R*0.35, 4, 5, color.RGBA{250, 240, 200, 255}, false)
+		case world.PickupFood:
+			vector.FillCircle(screen, px-p.R*0.2, py, p.R*0.8, c, false)
+			vector.StrokeLine(screen, px+p.R*0.3, py, px+p.R, py-p.R*0.6, 2, color.RGBA{245, 235, 220, 255}, false)
+		case world.PickupVacuum:
+			vector.StrokeCircle(screen, px, py, p.R, 2, c, false)
+			vector.StrokeCircle(screen, px, py, p.R*0.5, 1.5, c, false)
+		case world.PickupBomb:
+			vector.FillCircle(screen, px, py, p.R, dark, false)
+			vector.StrokeCircle(screen, px, py, p.R, 1.5, c, false)
+			vector.StrokeLine(screen, px+p.R*0.6, py-p.R*0.6, px+p.R*1.1, py-p.R*1.2, 1.5, color.RGBA{255, 170, 60, 255}, false)
+		default:
+			vector.FillCircle(screen, px, py, p.R, c, false)
+			vector.StrokeCircle(screen, px, py, p.R, 1, dark, false)
+		}
+	}
+}
+
+func drawEnemyShots(screen *ebiten.Image, s *world.Snapshot, camX, camY float32) {
+	for _, s := range s.Shots {
+		sx := camX + s.Pos.X
+		sy := camY + s.Pos.Y
+		vector.FillCircle(screen, sx, sy, s.R, color.RGBA{255, 95, 95, 230}, false)
+		vector.StrokeCircle(screen, sx, sy, s.R+1, 1, color.RGBA{255, 200, 200, 255}, false)
+	}
+}
+
+// drawPlayerShots draws the player's projectiles: orbiters as rings, sickles
+// as spinning crosses and bolts as a dot with a short trail.
+func drawPlayerShots(screen *ebiten.Image, s *world.Snapshot, camX, camY float32) {
+	for _, p := range s.PlayerShots {
+		px := camX + p.Pos.X
+		py := camY + p.Pos.Y
+		switch p.Style {
+		case world.AttackOrbit:
+			vector.FillCircle(screen, px, py, p.R, color.RGBA{140, 200, 255, 200}, false)
+			vector.StrokeCircle(screen, px, py, p.R+1, 1, color.RGBA{230, 245, 255, 255}, false)
+		case world.AttackBoomerang:
+			a := float64(p.Life) * 14
+			dx := float32(math.Cos(a)) * p.R
+			dy := float32(math.Sin(a)) * p.R
+			c := color.RGBA{200, 255, 160, 255}
+			vector.StrokeLine(screen, px-dx, py-dy, px+dx, py+dy, 3, c, false)
+			vector.StrokeLine(screen, px+dy, py-dx, px-dy, py+dx, 3, c, false)
+		default:
+			tail := p.Vel.Norm().Mul(p.R * 3)
+			vector.StrokeLine(screen, px-tail.X, py-tail.Y, px, py, 2, color.RGBA{255, 240, 150, 140}, false)
+			vector.FillCircle(screen, px, py, p.R, color.RGBA{255, 250, 210, 255}, false)
+		}
+	}
+}
+
+// drawBossTelegraph warns of the boss's next attack while it winds up: a
+// growing ring before bullet rings and summons, the dash lane before charges.
+func drawBossTelegraph(screen *ebiten.Image, s *world.Snapshot, camX, camY float32) {
+	if s.Boss.Stage != world.BossWindup {
+		return
+	}
+	atk, ok := s.Boss.CurrentAttack(&s.Cfg)
+	if !ok || atk.Windup <= 0 {
+		return
+	}
+	var boss world.Enemy
+	found := false
+	for _, e := range s.Enemies {
+		if e.ID == s.Boss.EnemyID {
+			boss, found = e, true
+			break
+		}
+	}
+	if !found {
+		return
+	}
+
+	bx, by := camX+boss.Pos.X, camY+boss.Pos.Y
+	t := 1 - min(max(s.Boss.Timer/atk.Windup, 0), 1)
+	alpha := uint8(80 + 150*t)
+	switch atk.Kind {
+	case world.BossCharge:
+		reach := atk.Speed * atk.Duration
+		ex, ey := bx+s.Boss.Aim.X*reach, by+s.Boss.Aim.Y*reach
+		vector.StrokeLine(screen, bx, by, ex, ey, boss.R*2, color.RGBA{255, 60, 60, alpha / 3}, false)
+		vector.StrokeLine(screen, bx, by, ex, ey, 1, color.RGBA{255, 120, 120, alpha}, false)
+	case world.BossSummon:
+		vector.StrokeCircle(screen, bx, by, boss.R*(1+t), 2, color.RGBA{160, 255, 160, alpha}, false)
+	default:
+		vector.StrokeCircle(screen, bx, by, boss.R*(1+2*t), 2, color.RGBA{255, 90, 90, alpha}, false)
+	}
+}
+
+// drawEnemies draws each enemy in its archetype's shape and colors.
+func drawEnemies(screen *ebiten.Image, s *world.Snapshot, camX, camY float32) {
+	styles := make(map[world.EnemyKind]world.EnemyDrawStyle, len(s.Cfg.Enemies))
+	for _, a := range s.Cfg.Enemies {
+		styles[a.ID] = a.Draw
+	}
+	affixColors := make(map[world.AffixKind]string, len(s.Cfg.Elites.Affixes))
+	for _, a := range s.Cfg.Elites.Affixes {
+		affixColors[a.Kind] = a.Color
+	}
+
+	for _, e := range s.Enemies {
+		ex := camX + e.Pos.X
+		ey := camY + e.Pos.Y
+
+		style, ok := styles[e.Kind]
+		if !ok {
+			style = fallbackEnemyStyle
+		}
+		clr := hexColor(style.Color)
+		if e.HitT > 0 {
+			clr = hexColor(style.HitColor)
+		}
+		accent := hexColor(style.Accent)
+
+		switch style.Shape {
+		case "diamond":
+			// Fast, elongated diamond-like enemy (stretched horizontally)
+			// Draw as two overlapping rectangles to form diamond
+			// Horizontal part
+			vector.FillRect(
+				screen,
+				ex-e.R*1.2, ey-e.R*0.4,
+				e.R*2.4, e.R*0.8,
+				clr,
+				false,
+			)
+
+			// Vertical part (smaller)
+			vector.FillRect(
+				screen,
+				ex-e.R*0.4, ey-e.R*0.8,
+				e.R*0.8, e.R*1.6,
+				clr,
+				false,
+			)
+
+			// Bright center core
+			vector.FillRect(
+				screen,
+				ex-e.R*0.25, ey-e.R*0.25,
+				e.R*0.5, e.R*0.5,
+				accent,
+				false,
+			)
+
+		case "plated":
+			// Large, beefy tank with armor plating
+			// Large square body
+			vector.FillRect(
+				screen,
+				ex-e.R, ey-e.R,
+				e.R*2, e.R*2,
+				clr,
+				false,
+			)
+
+			// Armor plates: horizontal lines
+			vector.FillRect(
+				screen,
+				ex-e.R*0.9, ey-e.R*0.4,
+				e.R*1.8, e.R*0.2,
+				accent,
+				false,
+			)
+			vector.FillRect(
+				screen,
+				ex-e.R*0.9, ey+e.R*0.2,
+				e.R*1.8, e.R*0.2,
+				accent,
+				false,
+			)
+
+			// Vertical center line
+			vector.FillRect(
+				screen,
+				ex-e.R*0.1, ey-e.R*0.9,
+				e.R*0.2, e.R*1.8,
+				accent,
+				false,
+			)
+
+			// Core/weak point
+			detail := accent
+			if style.Detail != "" {
+				detail = hexColor(style.Detail)
+			}
+			vector.FillRect(
+				screen,
+				ex-e.R*0.3, ey-e.R*0.3,
+				e.R*0.6, e.R*0.6,
+				detail,
+				false,
+			)
+		default: // "orb"
+			vector.FillCircle(
+				screen,
+				ex, ey,
+				e.R,
+				clr,
+				false,
+			)
+
+			// small "eye"
+			eyeR := e.R * 0.35
+			vector.FillCircle(
+				screen,
+				ex, ey,
+				eyeR,
+				accent,
+				false,
+			)
+		}
+		r := drawEliteOutline(screen, ex, ey, e, affixColors)
+		drawStatusRings(screen, ex, ey, r, e.Statuses)
+	}
+}
+
+// drawEliteOutline rings an elite once per affix, plus its remaining shield,
+// and returns the radius status rings should start outside of.
+func drawEliteOutline(screen *ebiten.Image, x, y float32, e world.Enemy, colors map[world.AffixKind]string) float32 {
+	r := e.R
+	if len(e.Affixes) == 0 {
+		return r
+	}
+	for _, a := range e.Affixes {
+		r += 2.5
+		vector.StrokeCircle(screen, x, y, r, 2, hexColor(colors[a.Kind]), false)
+	}
+	if e.Shield > 0 {
+		r += 2
+		vector.StrokeCircle(screen, x, y, r, 1, color.RGBA{200, 240, 255, 160}, false)
+	}
+	return r
+}
+
+var statusColors = map[world.StatusKind]color.RGBA{
+	world.StatusBurn:   {255, 140, 40, 230},
+	world.StatusPoison: {120, 220, 70, 230},
+	world.StatusSlow:   {110, 160, 255, 200},
+	world.StatusFreeze: {190, 240, 255, 255},
+	world.StatusStun:   {255, 240, 90, 230},
+	world.StatusVuln:   {230, 70, 200, 220},
+}
+
+// drawStatusRings outlines an entity once per active status, innermost first.
+func drawStatusRings(screen *ebiten.Image, x, y, r float32, statuses []world.StatusEffect) {
+	for i, st := range statuses {
+		vector.StrokeCircle(screen, x, y, r+2+float32(i)*2, 1, statusColors[st.Kind], false)
+	}
+}
+
+// fallbackEnemyStyle draws enemies whose archetype is missing from the config.
+var fallbackEnemyStyle = world.EnemyDrawStyle{Shape: "orb", Color: "#dc5050", HitColor: "#ffb4b4", Accent: "#962828"}
+
+// drawAttack fades the last attack effect out over lastAttackMax seconds.
+func drawAttack(screen *ebiten.Image, s *world.Snapshot, camX, camY float32) {
+	if s.LastAttackT > 0 {
+		const lastAttackMax float32 = 0.08
+		t := s.LastAttackT / lastAttackMax
+
+		if t < 0 {
+			t = 0
+		}
+
+		if t > 1 {
+			t = 1
+		}
+
+		alpha := uint8(255 * t)
+		switch s.LastAttackWeapon {
+		case world.WeaponNova:
+			vector.StrokeCircle(
+				screen,
+				camX+s.Player.Pos.X,
+				camY+s.Player.Pos.Y,
+				s.LastAttackRadius,
+				2,
+				color.RGBA{255, 130, 230, alpha},
+				false,
+			)
+		case world.WeaponVoidNova:
+			vector.StrokeCircle(screen, camX+s.Player.Pos.X, camY+s.Player.Pos.Y, s.LastAttackRadius, 3, color.RGBA{150, 90, 255, alpha}, false)
+			vector.StrokeCircle(screen, camX+s.Player.Pos.X, camY+s.Player.Pos.Y, s.LastAttackRadius*0.55, 1, color.RGBA{210, 170, 255, alpha}, false)
+		case world.WeaponGungnir:
+			vector.StrokeLine(screen, camX+s.Player.Pos.X, camY+s.Player.Pos.Y, camX+s.LastAttackPos.X, camY+s.LastAttackPos.Y, 6, color.RGBA{120, 230, 255, alpha / 2}, false)
+			vector.StrokeLine(screen, camX+s.Player.Pos.X, camY+s.Player.Pos.Y, camX+s.LastAttackPos.X, camY+s.LastAttackPos.Y, 2, color.RGBA{230, 250, 255, alpha}, false)
+		case world.WeaponSpear:
+			vector.StrokeLine(
+				screen,
+				camX+s.Player.Pos.X,
+				camY+s.Player.Pos.Y,
+				camX+s.LastAttackPos.X,
+				camY+s.LastAttackPos.Y,
+				3,
+				color.RGBA{120, 230, 255, alpha},
+				false,
+			)
+			vector.FillCircle(screen, camX+s.LastAttackPos.X, camY+s.LastAttackPos.Y, 3, color.RGBA{200, 245, 255, alpha}, false)
+		case world.WeaponFang:
+			midX := (s.Player.Pos.X + s.LastAttackPos.X) * 0.5
+			midY := (s.Player.Pos.Y + s.LastAttackPos.Y) * 0.5
+			vector.StrokeLine(screen, camX+s.Player.Pos.X, camY+s.Player.Pos.Y, camX+midX, camY+midY, 2, color.RGBA{255, 110, 110, alpha}, false)
+			vector.StrokeLine(screen, camX+midX, camY+midY, camX+s.LastAttackPos.X, camY+s.LastAttackPos.Y, 2, color.RGBA{255, 170, 170, alpha}, false)
+		default: // whip, chain lash
+			vector.StrokeLine(
+				screen,
+				camX+s.Player.Pos.X,
+				camY+s.Player.Pos.Y,
+				camX+s.LastAttackPos.X,
+				camY+s.LastAttackPos.Y,
+				2, // line width
+				color.RGBA{255, 255, 100, alpha},
+				false,
+			)
+			vector.StrokeLine(
+				screen,
+				camX+s.Player.Pos.X,
+				camY+s.Player.Pos.Y,
+				camX+(s.Player.Pos.X*0.35+s.LastAttackPos.X*0.65),
+				camY+(s.Player.Pos.Y*0.35+s.LastAttackPos.Y*0.65),
+				1,
+				color.RGBA{255, 220, 140, alpha},
+				false,
+			)
+		}
+	}
+}
+
+func drawPlayer(screen *ebiten.Image, s *world.Snapshot, assets AssetProvider, camX, camY float32) {
+	px := camX + s.Player.Pos.X
+	py := camY + s.Player.Pos.Y
+
+	playerImg := assets.Get("player_top")
+	if s.Player.Moving {
+		if walk := assets.Get("player_walk"); walk != nil {
+			playerImg = walk
+		}
+	}
+	if playerImg == nil {
+		playerImg = assets.Get("player")
+	}
+	if playerImg != nil {
+		b := playerImg.Bounds()
+		iw, ih := b.Dx(), b.Dy()
+
+		if iw > 0 && ih > 0 {
+			op := &ebiten.DrawImageOptions{}
+			op.GeoM.Translate(-float64(iw)/2, -float64(ih)/2)
+			op.GeoM.Scale(
+				float64((s.Player.R*3)/float32(iw)),
+				float64((s.Player.R*3)/float32(ih)),
+			)
+			op.GeoM.Translate(float64(px), float64(py))
+
+			if s.Player.HurtTimer > 0 {
+				op.ColorScale.Scale(1.25, 1.25, 1.25, 1.0)
+			}
+			screen.DrawImage(playerImg, op)
+		}
+	} else {
+		pclr := color.RGBA{80, 200, 120, 255}
+		if s.Player.HurtTimer > 0 {
+			pclr = color.RGBA{200, 240, 200, 255}
+		}
+		vector.FillCircle(
+			screen,
+			px,
+			py,
+			s.Player.R,
+			pclr,
+			false,
+		)
+	}
+	drawStatusRings(screen, px, py, s.Player.R, s.Player.Statuses)
+}
@@ -0,0 +1,246 @@
+// Package migrate upgrades versioned JSON documents before they are decoded
+// into current structs. Each registry holds vN -> vN+1 steps that run in
+// order on a generic document, so old files survive struct changes.
+package migrate
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Doc is a decoded JSON object. Numbers are kept as json.Number so 64-bit
+// integers (e.g. RNG state) survive a decode/encode round-trip.
+type Doc = map[string]any
+
+// Step upgrades a document from version N to N+1 in place. The registry
+// writes the new version number after the step returns.
+type Step func(doc Doc) error
+
+type Registry struct {
+	name    string
+	current int
+	path    []string
+	steps   map[int]Step
+	nested  []nestedRegistry
+}
+
+type nestedRegistry struct {
+	path  []string
+	inner *Registry
+}
+
+// New creates a registry for documents whose version lives at path (for
+// example "version" or "header", "version"). A missing or zero version is
+// treated as v1, the first persisted layout.
+func New(name string, current int, path ...string) *Registry {
+	if len(path) == 0 {
+		path = []string{"version"}
+	}
+	return &Registry{
+		name:    name,
+		current: current,
+		path:    path,
+		steps:   make(map[int]Step, 4),
+	}
+}
+
+// Register adds the step that upgrades version from to from+1.
+func (r *Registry) Register(from int, step Step) *Registry {
+	if _, dup := r.steps[from]; dup {
+		panic(fmt.Sprintf("migrate: duplicate %s step from v%d", r.name, from))
+	}
+	r.steps[from] = step
+	return r
+}
+
+// Nest runs inner on the object stored under key after this registry's own
+// steps, for documents that embed another versioned document.
+func (r *Registry) Nest(key string, inner *Registry) *Registry {
+	return r.NestPath(inner, key)
+}
+
+// NestPath is Nest for documents embedded deeper than one key. Arrays on the
+// path fan out, so ("keyframes", "snapshot") migrates the snapshot of every
+// keyframe.
+func (r *Registry) NestPath(inner *Registry, path ...string) *Registry {
+	r.nested = append(r.nested, nestedRegistry{path: path, inner: inner})
+	return r
+}
+
+// Current is the version the registry upgrades to.
+func (r *Registry) Current() int {
+	return r.current
+}
+
+// Migrate upgrades doc in place to the current version.
+func (r *Registry) Migrate(doc Doc) error {
+	v, err := r.version(doc)
+	if err != nil {
+		return err
+	}
+	if v > r.current {
+		return fmt.Errorf("%s version %d is newer than supported %d", r.name, v, r.current)
+	}
+	for ; v < r.current; v++ {
+		step, ok := r.steps[v]
+		if !ok {
+			return fmt.Errorf("no %s migration from v%d to v%d", r.name, v, v+1)
+		}
+		if err := step(doc); err != nil {
+			return fmt.Errorf("migrate %s v%d to v%d: %w", r.name, v, v+1, err)
+		}
+		if err := r.setVersion(doc, v+1); err != nil {
+			return err
+		}
+	}
+
+	for _, n := range r.nested {
+		if err := migrateAt(doc, n.path, n.inner); err != nil {
+			return fmt.Errorf("%s.%s: %w", r.name, strings.Join(n.path, "."), err)
+		}
+	}
+	return nil
+}
+
+// migrateAt walks path from v and migrates every object it reaches. Missing
+// keys and non-object values are skipped.
+func migrateAt(v any, path []string, inner *Registry) error {
+	switch v := v.(type) {
+	case []any:
+		for _, elem := range v {
+			if err := migrateAt(elem, path, inner); err != nil {
+				return err
+			}
+		}
+	case Doc:
+		if len(path) == 0 {
+			return inner.Migrate(v)
+		}
+		return migrateAt(v[path[0]], path[1:], inner)
+	}
+	return nil
+}
+
+// MigrateJSON decodes blob, upgrades it and re-encodes it for decoding into
+// the current struct.
+func (r *Registry) MigrateJSON(blob []byte) ([]byte, error) {
+	doc, err := Decode(blob)
+	if err != nil {
+		return nil, fmt.Errorf("decode %s: %w", r.name, err)
+	}
+	if err := r.Migrate(doc); err != nil {
+		return nil, err
+	}
+	return json.Marshal(doc)
+}
+
+// Decode parses a JSON object into a Doc, keeping numbers as json.Number.
+func Decode(blob []byte) (Doc, error) {
+	dec := json.NewDecoder(bytes.NewReader(blob))
+	dec.UseNumber()
+	var doc Doc
+	if err := dec.Decode(&doc); err != nil {
+		return nil, err
+	}
+	if doc == nil {
+		return nil, fmt.Errorf("document is not a JSON object")
+	}
+	return doc, nil
+}
+
+func (r *Registry) version(doc Doc) (int, error) {
+	parent, ok := r.parent(doc, false)
+	if !ok {
+		return 1, nil
+	}
+	raw, ok := parent[r.path[len(r.path)-1]]
+	if !ok || raw == nil {
+		return 1, nil
+	}
+	n, ok := raw.(json.Number)
+	if !ok {
+		return 0, fmt.Errorf("%s version is not a number: %v", r.name, raw)
+	}
+	v, err := strconv.Atoi(n.String())
+	if err != nil {
+		return 0, fmt.Errorf("%s version %q: %w", r.name, n, err)
+	}
+	if v == 0 {
+		v = 1
+	}
+	return v, nil
+}
+
+func (r *Registry) setVersion(doc Doc, v int) error {
+	parent, ok := r.parent(doc, true)
+	if !ok {
+		return fmt.Errorf("%s version path is not an object", r.name)
+	}
+	parent[r.path[len(r.path)-1]] = json.Number(strconv.Itoa(v))
+	return nil
+}
+
+// parent walks to the object holding the version field, optionally creating
+// missing intermediate objects.
+func (r *Registry) parent(doc Doc, create bool) (Doc, bool) {
+	cur := doc
+	for _, key := range r.path[:len(r.path)-1] {
+		next, ok := cur[key].(Doc)
+		if !ok {
+			if !create || cur[key] != nil {
+				return nil, false
+			}
+			next = Doc{}
+			cur[key] = next
+		}
+		cur = next
+	}
+	return cur, true
+}
+
+// Int64 reads an integer field, treating a missing field as zero.
+func Int64(doc Doc, key string) (int64, error) {
+	raw, ok := doc[key]
+	if !ok || raw == nil {
+		return 0, nil
+	}
+	n, ok := raw.(json.Number)
+	if !ok {
+		return 0, fmt.Errorf("field %q is not a number", key)
+	}
+	return strconv.ParseInt(n.String(), 10, 64)
+}
+
+// Uint64 reads an unsigned integer field, treating a missing field as zero.
+func Uint64(doc Doc, key string) (uint64, error) {
+	raw, ok := doc[key]
+	if !ok || raw == nil {
+		return 0, nil
+	}
+	n, ok := raw.(json.Number)
+	if !ok {
+		return 0, fmt.Errorf("field %q is not a number", key)
+	}
+	return strconv.ParseUint(n.String(), 10, 64)
+}
+
+// Float64 reads a numeric field, treating a missing field as zero.
+func Float64(doc Doc, key string) (float64, error) {
+	raw, ok := doc[key]
+	if !ok || raw == nil {
+		return 0, nil
+	}
+	n, ok := raw.(json.Number)
+	if !ok {
+		return 0, fmt.Errorf("field %q is not a number", key)
+	}
+	return n.Float64()
+}
+
+// Uint64Number encodes v without going through float64.
+func Uint64Number(v uint64) json.Number {
+	return json.Number(strconv.FormatUint(v, 10))
+}
@@ -20,6 +20,11 @@ type Batch struct {
 	Dmg    float32
 	Frames int
 	AvgDt  float32
+
+	// world jobs the pool shed (rejected, expired or undelivered) and
+	// results the world computed itself because they were late
+	JobsShed int
+	JobsLate int
 }
 
 type Sink struct {
@@ -69,6 +74,8 @@ func (s *Sink) loop() {
 		dmg    float32
 		frames int
 		dtSum  float32
+		shed   int
+		late   int
 	)
 
 	for {
@@ -89,6 +96,10 @@ func (s *Sink) loop() {
 			case "frame":
 				frames++
 				dtSum += ev.F
+			case "job_shed":
+				shed += ev.I
+			case "job_late":
+				late += ev.I
 			}
 
 		case <-ticker.C:
@@ -102,6 +113,9 @@ func (s *Sink) loop() {
 				Dmg:    dmg,
 				Frames: frames,
 				AvgDt:  avgDt,
+
+				JobsShed: shed,
+				JobsLate: late,
 			})
 
 			// reset batch
@@ -109,6 +123,8 @@ func (s *Sink) loop() {
 			dmg = 0
 			frames = 0
 			dtSum = 0
+			shed = 0
+			late = 0
 		}
 	}
 }
@@ -120,6 +136,8 @@ func emitBatchLog(b Batch) {
 		slog.Float64("dmg", float64(b.Dmg)),
 		slog.Int("frames", b.Frames),
 		slog.Float64("avg_dt_s", float64(b.AvgDt)),
+		slog.Int("jobs_shed", b.JobsShed),
+		slog.Int("jobs_late", b.JobsLate),
 	)
 }
 
@@ -18,6 +18,8 @@ func TestSinkBatchesEvents(t *testing.T) {
 	s.In <- telemetry.Event{Kind: "damage", F: 3.5, At: time.Now()}
 	s.In <- telemetry.Event{Kind: "frame", F: 0.016, At: time.Now()}
 	s.In <- telemetry.Event{Kind: "frame", F: 0.018, At: time.Now()}
+	s.In <- telemetry.Event{Kind: "job_shed", I: 3, At: time.Now()}
+	s.In <- telemetry.Event{Kind: "job_late", I: 1, At: time.Now()}
 
 	deadline := time.After(700 * time.Millisecond)
 	for {
@@ -38,6 +40,9 @@ func TestSinkBatchesEvents(t *testing.T) {
 			if !approxEqual(b.AvgDt, 0.017) {
 				t.Fatalf("avg dt mismatch: got %.6f want %.6f", b.AvgDt, 0.017)
 			}
+			if b.JobsShed != 3 || b.JobsLate != 1 {
+				t.Fatalf("job counts mismatch: got shed %d late %d want 3 and 1", b.JobsShed, b.JobsLate)
+			}
 			return
 		case <-deadline:
 			t.Fatal("timed out waiting for telemetry batch")
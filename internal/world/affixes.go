@@ -0,0 +1,258 @@
+package world
+
+import (
+	"fmt"
+	"math"
+	"slices"
+)
+
+// AffixKind names an elite modifier. Content picks which affixes roll and how
+// strong they are; the behaviour behind each kind lives here.
+type AffixKind string
+
+const (
+	AffixArmored   AffixKind = "armored"   // incoming damage scaled by 1-Magnitude
+	AffixHasted    AffixKind = "hasted"    // speed scaled by 1+Magnitude
+	AffixSplitting AffixKind = "splitting" // splits into Count copies at Magnitude HP on death
+	AffixVampiric  AffixKind = "vampiric"  // heals Magnitude of the contact damage it deals
+	AffixShielded  AffixKind = "shielded"  // a shield of Magnitude×MaxHP soaks damage first
+	AffixExplosive AffixKind = "explosive" // deals Magnitude to the player within Radius on death
+)
+
+var affixKinds = []AffixKind{AffixArmored, AffixHasted, AffixSplitting, AffixVampiric, AffixShielded, AffixExplosive}
+
+// EliteConfig controls the elite roll made for every regular spawn. The
+// chance and the number of affixes grow with the wave index.
+type EliteConfig struct {
+	FromWave      int     `json:"from_wave"`
+	Chance        float32 `json:"chance"`
+	ChancePerWave float32 `json:"chance_per_wave"`
+	MaxChance     float32 `json:"max_chance"`
+	// One more affix every ExtraAffixEvery waves past FromWave (0 never),
+	// up to MaxAffixes.
+	ExtraAffixEvery int     `json:"extra_affix_every"`
+	MaxAffixes      int     `json:"max_affixes"`
+	HPMul           float32 `json:"hp_mul"`
+	XPMul           float32 `json:"xp_mul"`
+	DropBonus       float32 `json:"drop_bonus"` // added to the archetype's drop chance
+
+	Affixes []AffixDef `json:"affixes"`
+}
+
+// AffixDef is one rollable affix. Its magnitude grows by PerWave each wave
+// past FromWave, capped at MaxMagnitude when set.
+type AffixDef struct {
+	Kind         AffixKind `json:"kind"`
+	Name         string    `json:"name"`
+	Weight       int       `json:"weight"`
+	FromWave     int       `json:"from_wave"`
+	Magnitude    float32   `json:"magnitude"`
+	PerWave      float32   `json:"per_wave,omitempty"`
+	MaxMagnitude float32   `json:"max_magnitude,omitempty"`
+	Count        int       `json:"count,omitempty"`  // splitting
+	Radius       float32   `json:"radius,omitempty"` // explosive
+	Color        string    `json:"color"`            // outline, "#rrggbb"
+}
+
+// EnemyAffix is an affix rolled onto an enemy, with its magnitude fixed at
+// spawn.
+type EnemyAffix struct {
+	Kind      AffixKind
+	Magnitude float32
+}
+
+// ValidateEliteConfig checks chances, multipliers and that each affix kind is
+// known, listed once and has usable parameters.
+func ValidateEliteConfig(c EliteConfig) error {
+	switch {
+	case c.Chance < 0 || c.ChancePerWave < 0 || c.MaxChance < 0 || c.MaxChance > 1:
+		return fmt.Errorf("elite chances must be in [0, 1]")
+	case c.ExtraAffixEvery < 0 || c.MaxAffixes < 0:
+		return fmt.Errorf("elite affix counts must not be negative")
+	case c.HPMul < 0 || c.XPMul < 0 || c.DropBonus < 0:
+		return fmt.Errorf("elite multipliers must not be negative")
+	}
+	seen := make(map[AffixKind]bool, len(c.Affixes))
+	for _, a := range c.Affixes {
+		if !slices.Contains(affixKinds, a.Kind) {
+			return fmt.Errorf("unknown affix %q", a.Kind)
+		}
+		if seen[a.Kind] {
+			return fmt.Errorf("duplicate affix %q", a.Kind)
+		}
+		seen[a.Kind] = true
+		if a.Weight < 0 || a.Magnitude <= 0 || a.PerWave < 0 {
+			return fmt.Errorf("affix %q needs a positive magnitude and non-negative weight", a.Kind)
+		}
+		// the strongest magnitude the affix can reach; unbounded growth is +Inf
+		top := a.Magnitude
+		if a.MaxMagnitude > 0 {
+			top = max(top, a.MaxMagnitude)
+		} else if a.PerWave > 0 {
+			top = float32(math.Inf(1))
+		}
+		switch {
+		case a.Kind == AffixArmored && top >= 1:
+			return fmt.Errorf("affix %q must stay below 1", a.Kind)
+		case a.Kind == AffixSplitting && (a.Count <= 0 || top > 1):
+			return fmt.Errorf("affix %q needs a positive count and magnitude up to 1", a.Kind)
+		case a.Kind == AffixExplosive && a.Radius <= 0:
+			return fmt.Errorf("affix %q needs a positive radius", a.Kind)
+		}
+	}
+	return nil
+}
+
+// magnitude is the affix strength at wave.
+func (a AffixDef) magnitude(wave int) float32 {
+	m := a.Magnitude + a.PerWave*float32(max(wave-a.FromWave, 0))
+	if a.MaxMagnitude > 0 {
+		m = min(m, a.MaxMagnitude)
+	}
+	return m
+}
+
+// Affix looks up the content entry for an affix kind.
+func (c *EliteConfig) Affix(kind AffixKind) (*AffixDef, bool) {
+	for i := range c.Affixes {
+		if c.Affixes[i].Kind == kind {
+			return &c.Affixes[i], true
+		}
+	}
+	return nil, false
+}
+
+func affixOf(list []EnemyAffix, kind AffixKind) (EnemyAffix, bool) {
+	for _, a := range list {
+		if a.Kind == kind {
+			return a, true
+		}
+	}
+	return EnemyAffix{}, false
+}
+
+// rollElite may turn the enemy at idx into an elite. Before the elite wave no
+// RNG is drawn, so early waves keep their draw sequence.
+func (w *World) rollElite(idx int) {
+	c := &w.Cfg.Elites
+	wave := w.Wave.Index
+	if wave < c.FromWave || c.MaxAffixes <= 0 {
+		return
+	}
+	chance := min(c.Chance+c.ChancePerWave*float32(wave-c.FromWave), c.MaxChance)
+	if chance <= 0 || w.randFloat32() >= chance {
+		return
+	}
+
+	n := 1
+	if c.ExtraAffixEvery > 0 {
+		n += (wave - c.FromWave) / c.ExtraAffixEvery
+	}
+	n = min(n, c.MaxAffixes)
+
+	var pool []AffixDef
+	for _, a := range c.Affixes {
+		if a.Weight > 0 && wave >= a.FromWave {
+			pool = append(pool, a)
+		}
+	}
+	var affixes []EnemyAffix
+	for len(affixes) < n && len(pool) > 0 {
+		i := w.pickAffix(pool)
+		affixes = append(affixes, EnemyAffix{Kind: pool[i].Kind, Magnitude: pool[i].magnitude(wave)})
+		pool = slices.Delete(pool, i, i+1)
+	}
+	if len(affixes) > 0 {
+		w.makeElite(idx, affixes)
+	}
+}
+
+// pickAffix draws a weighted index into pool.
+func (w *World) pickAffix(pool []AffixDef) int {
+	total := 0
+	for _, a := range pool {
+		total += a.Weight
+	}
+	roll := w.randIntn(total)
+	for i, a := range pool {
+		if roll < a.Weight {
+			return i
+		}
+		roll -= a.Weight
+	}
+	return len(pool) - 1
+}
+
+// makeElite applies the elite multipliers and the spawn-time affixes.
+func (w *World) makeElite(idx int, affixes []EnemyAffix) {
+	c := &w.Cfg.Elites
+	e := &w.Enemies[idx]
+	e.Affixes = affixes
+	if c.HPMul > 0 {
+		e.MaxHP *= c.HPMul
+		e.HP = e.MaxHP
+	}
+	if c.XPMul > 0 {
+		e.XPValue *= c.XPMul
+	}
+	if a, ok := affixOf(affixes, AffixHasted); ok {
+		e.Speed *= 1 + a.Magnitude
+	}
+	if a, ok := affixOf(affixes, AffixShielded); ok {
+		e.Shield = a.Magnitude * e.MaxHP
+	}
+}
+
+// affixDamage applies armor and shields to a hit and returns what reaches HP.
+func affixDamage(e *Enemy, dmg float32) float32 {
+	if len(e.Affixes) == 0 {
+		return dmg
+	}
+	if a, ok := affixOf(e.Affixes, AffixArmored); ok {
+		dmg *= 1 - a.Magnitude
+	}
+	if e.Shield > 0 {
+		soaked := min(e.Shield, dmg)
+		e.Shield -= soaked
+		dmg -= soaked
+	}
+	return dmg
+}
+
+// affixContact heals a vampiric enemy for the contact damage it dealt.
+func affixContact(e *Enemy, dealt float32) {
+	if a, ok := affixOf(e.Affixes, AffixVampiric); ok {
+		e.HP = min(e.MaxHP, e.HP+dealt*a.Magnitude)
+	}
+}
+
+// affixDeath triggers on-death affixes of an enemy that died at pos.
+func (w *World) affixDeath(kind EnemyKind, pos Vec2, affixes []EnemyAffix) {
+	if a, ok := affixOf(affixes, AffixSplitting); ok {
+		def, _ := w.Cfg.Elites.Affix(AffixSplitting)
+		count := 2
+		if def != nil {
+			count = def.Count
+		}
+		arch := w.archetype(kind)
+		gap := 2 * math.Pi / float32(count)
+		for i := range count {
+			w.spawnEnemy(kind, pos.Add(polar(gap*float32(i), arch.Radius)))
+			e := &w.Enemies[len(w.Enemies)-1]
+			e.MaxHP = arch.HP * a.Magnitude
+			e.HP = e.MaxHP
+		}
+	}
+	if a, ok := affixOf(affixes, AffixExplosive); ok {
+		def, _ := w.Cfg.Elites.Affix(AffixExplosive)
+		if def == nil || w.Player.HurtTimer > 0 {
+			return
+		}
+		rr := def.Radius + w.Player.R
+		if dist2(pos, w.Player.Pos) <= rr*rr {
+			w.damagePlayer(w.Player.armored(a.Magnitude))
+			w.Player.HurtTimer = w.Cfg.PlayerHurtCooldown
+			w.ShakeT = w.Cfg.HitShakeDuration
+		}
+	}
+}
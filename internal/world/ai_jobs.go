@@ -1,114 +1,76 @@
 package world
 
-import (
-	"runtime"
-
-	"horde-lab/internal/jobs"
-)
+import "horde-lab/internal/jobs"
 
 type enemyMoveIntent struct {
 	Dir            Vec2
 	SpeedScale     float32
 	PreferredRange float32
 	Mode           jobs.IntentMode
-}
-
-func newAIPool() *jobs.IntentPool {
-	workers := runtime.NumCPU() / 2
-	if workers < 1 {
-		workers = 1
-	}
-	if workers > 4 {
-		workers = 4
-	}
-
-	return jobs.NewIntentPool(workers, 16)
+	Path           Vec2 // flow field heading; zero on a straight chase
 }
 
 func (w *World) drainAIResults() {
-	if w.aiPool == nil {
+	if w.pool == nil {
 		return
 	}
 
-	for {
-		select {
-		case res := <-w.aiPool.Res:
-			// Drop stale results that are older than the previous tick window.
-			if res.Tick+1 < w.aiTick {
-				continue
-			}
-			w.aiReadyResults[res.Tick] = res
-		default:
-			return
-		}
+	// Drop stale results that are older than the previous tick window.
+	var oldest uint64
+	if w.aiTick > 1 {
+		oldest = w.aiTick - 1
 	}
+	w.aiIntents.Collect(w.pool.intents, oldest)
+	w.waves.Collect(w.pool.waves, 0)
 }
 
+// consumeAIIntentsForTick takes the intents for tick; when workers were late
+// they are computed here from the exact request submitted for that tick.
 func (w *World) consumeAIIntentsForTick(tick uint64) map[int]enemyMoveIntent {
-	if res, ok := w.aiReadyResults[tick]; ok {
-		delete(w.aiReadyResults, tick)
-		delete(w.aiPendingRequests, tick)
-		return intentsFromResult(res)
-	}
-
-	// Deterministic fallback: compute synchronously from the exact snapshot
-	// that was submitted for this tick if workers were late.
-	if req, ok := w.aiPendingRequests[tick]; ok {
-		delete(w.aiPendingRequests, tick)
-		return intentsFromResult(jobs.ComputeIntents(req))
+	res, ok := w.aiIntents.Take(tick)
+	if !ok {
+		return nil
 	}
-
-	return nil
+	return intentsFromResult(res)
 }
 
 func (w *World) submitAIJob(tick uint64) {
-	if w.aiPool == nil || len(w.Enemies) == 0 {
+	if w.pool == nil || len(w.Enemies) == 0 {
 		return
 	}
+	w.pool.workers.SetTick(tick)
 
 	req := jobs.IntentRequest{
 		Tick:    tick,
 		PlayerX: w.Player.Pos.X,
 		PlayerY: w.Player.Pos.Y,
 		Enemies: make([]jobs.EnemySnapshot, len(w.Enemies)),
+
+		Grid:        w.currentFlowGrid(),
+		GridVersion: w.flowVersion,
+		Flows:       w.flows,
+	}
+	if req.Grid != nil {
+		w.submitFlowJob(tick, req.Grid)
 	}
 
 	for i, e := range w.Enemies {
 		req.Enemies[i] = jobs.EnemySnapshot{
 			EnemyID: e.ID,
-			Role:    roleFromEnemyKind(e.Kind),
+			Role:    w.archetype(e.Kind).aiRole(),
 			X:       e.Pos.X,
 			Y:       e.Pos.Y,
 			Radius:  e.R,
+			Stunned: statusHeld(e.Statuses),
 		}
 	}
 
-	w.aiPendingRequests[tick] = req
+	// Rejected or expired jobs are computed at consume time instead.
+	w.aiIntents.Track(tick, req)
+	w.pool.intents.Submit(jobs.Job[jobs.IntentRequest]{Tick: tick, Deadline: tick, Priority: jobs.PriorityHigh, Req: req})
 
-	select {
-	case w.aiPool.Req <- req:
-	default:
-		// Queue full: synchronous fallback at consume time will handle it.
-	}
-
-	w.pruneAIState(tick)
-}
-
-func (w *World) pruneAIState(currentTick uint64) {
-	if currentTick <= 8 {
-		return
-	}
-
-	cutoff := currentTick - 8
-	for tick := range w.aiPendingRequests {
-		if tick < cutoff {
-			delete(w.aiPendingRequests, tick)
-		}
-	}
-	for tick := range w.aiReadyResults {
-		if tick < cutoff {
-			delete(w.aiReadyResults, tick)
-		}
+	if tick > 8 {
+		w.aiIntents.Prune(tick - 8)
 	}
 }
 
@@ -127,18 +89,8 @@ func intentsFromResult(res jobs.IntentResult) map[int]enemyMoveIntent {
 			SpeedScale:     in.SpeedScale,
 			PreferredRange: in.PreferredRange,
 			Mode:           in.Mode,
+			Path:           Vec2{X: in.PathX, Y: in.PathY},
 		}
 	}
 	return out
 }
-
-func roleFromEnemyKind(kind EnemyKind) jobs.EnemyRole {
-	switch kind {
-	case EnemyRunner:
-		return jobs.EnemyRoleRunner
-	case EnemyTank:
-		return jobs.EnemyRoleTank
-	default:
-		return jobs.EnemyRoleNormal
-	}
-}
@@ -0,0 +1,252 @@
+package world
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+
+	"horde-lab/internal/jobs"
+)
+
+// EnemyKind is an archetype ID from the enemy content file.
+type EnemyKind string
+
+// IDs of the archetypes shipped in content/enemies.json. Gameplay code looks
+// archetypes up by ID and never switches on these.
+const (
+	EnemyNormal EnemyKind = "normal"
+	EnemyRunner EnemyKind = "runner"
+	EnemyTank   EnemyKind = "tank"
+)
+
+// EnemyArchetype is one enemy type as designers define it.
+type EnemyArchetype struct {
+	ID          EnemyKind `json:"id"`
+	Name        string    `json:"name"`
+	Radius      float32   `json:"radius"`
+	Speed       float32   `json:"speed"`
+	HP          float32   `json:"hp"`
+	TouchDamage float32   `json:"touch_damage"`
+	XP          float32   `json:"xp"`
+	DropChance  float32   `json:"drop_chance"`
+
+	// Role picks the AI behaviour: "normal", "runner" or "tank".
+	Role string `json:"role"`
+	// Ranged archetypes fire short-range shots while the player wields Nova.
+	Ranged bool `json:"ranged,omitempty"`
+	// OnHit statuses land on the player with contact damage and shots.
+	OnHit []StatusApply `json:"on_hit,omitempty"`
+
+	Draw      EnemyDrawStyle  `json:"draw"`
+	Spawn     SpawnCurve      `json:"spawn"`
+	Guarantee *GuaranteeCurve `json:"guarantee,omitempty"`
+	Surge     *WaveSurgeLabel `json:"surge,omitempty"`
+
+	// Boss archetypes never spawn from the wave mix; boss waves spawn them.
+	Boss *BossDef `json:"boss,omitempty"`
+}
+
+// EnemyDrawStyle tells the renderer how to draw an archetype. Colors are
+// "#rrggbb" hex strings.
+type EnemyDrawStyle struct {
+	Shape    string `json:"shape"` // "orb", "diamond" or "plated"
+	Color    string `json:"color"`
+	HitColor string `json:"hit_color"`
+	Accent   string `json:"accent"`
+	Detail   string `json:"detail,omitempty"`
+}
+
+// SpawnCurve gives an archetype's spawn weight in a wave:
+//
+//	Base + Step*((wave-BaseWave)/Every) + SeedBonus[seed%len]
+//
+// clamped to [Min, Max] (Max 0 means unbounded), and 0 before FromWave.
+type SpawnCurve struct {
+	FromWave  int   `json:"from_wave"`
+	BaseWave  int   `json:"base_wave"`
+	Base      int   `json:"base"`
+	Step      int   `json:"step"`
+	Every     int   `json:"every"`
+	Min       int   `json:"min,omitempty"`
+	Max       int   `json:"max,omitempty"`
+	SeedBonus []int `json:"seed_bonus,omitempty"`
+}
+
+// GuaranteeCurve forces a spawn of the archetype every N spawns while its
+// weight is positive: N = max(Min, Base + PerWave*wave + SeedBonus[seed%len]).
+type GuaranteeCurve struct {
+	Base      int   `json:"base"`
+	PerWave   int   `json:"per_wave"`
+	Min       int   `json:"min"`
+	SeedBonus []int `json:"seed_bonus,omitempty"`
+}
+
+// WaveSurgeLabel names a wave once the archetype's weight reaches MinWeight.
+type WaveSurgeLabel struct {
+	MinWeight int    `json:"min_weight"`
+	Label     string `json:"label"`
+}
+
+// EnemyContent is the layout of the enemy content file. Entry order matters:
+// earlier archetypes win spawn rolls first and name surge waves first.
+type EnemyContent struct {
+	Default EnemyKind        `json:"default"`
+	Enemies []EnemyArchetype `json:"enemies"`
+	Elites  EliteConfig      `json:"elites"`
+}
+
+//go:embed content/enemies.json
+var embeddedEnemies []byte
+
+var defaultEnemyContent = mustParseEnemyContent(embeddedEnemies)
+
+var enemyRoles = map[string]jobs.EnemyRole{
+	"normal": jobs.EnemyRoleNormal,
+	"runner": jobs.EnemyRoleRunner,
+	"tank":   jobs.EnemyRoleTank,
+}
+
+// DefaultEnemyContent returns a copy of the embedded enemy archetypes.
+func DefaultEnemyContent() EnemyContent {
+	c := defaultEnemyContent
+	c.Enemies = slices.Clone(c.Enemies)
+	c.Elites.Affixes = slices.Clone(c.Elites.Affixes)
+	return c
+}
+
+// LoadEnemyContent reads and validates an enemy content file, e.g. one a
+// designer is iterating on outside the embedded default.
+func LoadEnemyContent(path string) (EnemyContent, error) {
+	blob, err := os.ReadFile(path)
+	if err != nil {
+		return EnemyContent{}, fmt.Errorf("read enemy content: %w", err)
+	}
+	return ParseEnemyContent(blob)
+}
+
+func ParseEnemyContent(blob []byte) (EnemyContent, error) {
+	var c EnemyContent
+	if err := json.Unmarshal(blob, &c); err != nil {
+		return EnemyContent{}, fmt.Errorf("decode enemy content: %w", err)
+	}
+	if err := ValidateEnemyArchetypes(c.Default, c.Enemies); err != nil {
+		return EnemyContent{}, err
+	}
+	if err := ValidateEliteConfig(c.Elites); err != nil {
+		return EnemyContent{}, fmt.Errorf("elites: %w", err)
+	}
+	return c, nil
+}
+
+func mustParseEnemyContent(blob []byte) EnemyContent {
+	c, err := ParseEnemyContent(blob)
+	if err != nil {
+		panic("world: embedded enemy content: " + err.Error())
+	}
+	return c
+}
+
+// ValidateEnemyArchetypes checks IDs, stats and roles, and that def names
+// one of the archetypes.
+func ValidateEnemyArchetypes(def EnemyKind, enemies []EnemyArchetype) error {
+	if len(enemies) == 0 {
+		return fmt.Errorf("enemy content has no archetypes")
+	}
+	seen := make(map[EnemyKind]bool, len(enemies))
+	for i, a := range enemies {
+		switch {
+		case a.ID == "":
+			return fmt.Errorf("enemy archetype %d has no id", i)
+		case seen[a.ID]:
+			return fmt.Errorf("duplicate enemy archetype %q", a.ID)
+		case a.Radius <= 0 || a.HP <= 0:
+			return fmt.Errorf("enemy archetype %q needs positive radius and hp", a.ID)
+		case a.Spawn.Every < 0:
+			return fmt.Errorf("enemy archetype %q has negative spawn.every", a.ID)
+		}
+		if _, ok := enemyRoles[a.Role]; !ok {
+			return fmt.Errorf("enemy archetype %q has unknown role %q", a.ID, a.Role)
+		}
+		if err := ValidateStatusApplies(a.OnHit); err != nil {
+			return fmt.Errorf("enemy archetype %q on_hit: %w", a.ID, err)
+		}
+		seen[a.ID] = true
+	}
+	// summons may name archetypes listed after the boss, but not bosses
+	isBoss := make(map[EnemyKind]bool, len(enemies))
+	for _, a := range enemies {
+		isBoss[a.ID] = a.Boss != nil
+	}
+	for _, a := range enemies {
+		if a.Boss == nil {
+			continue
+		}
+		if err := a.Boss.validate(isBoss); err != nil {
+			return fmt.Errorf("boss %q: %w", a.ID, err)
+		}
+	}
+	if !seen[def] {
+		return fmt.Errorf("default enemy %q is not an archetype", def)
+	}
+	return nil
+}
+
+// Archetype looks up an archetype by ID.
+func (c *Config) Archetype(kind EnemyKind) (*EnemyArchetype, bool) {
+	for i := range c.Enemies {
+		if c.Enemies[i].ID == kind {
+			return &c.Enemies[i], true
+		}
+	}
+	return nil, false
+}
+
+// archetype returns the archetype for kind, falling back to the default one
+// (or the first) so a stale ID never crashes the simulation.
+func (w *World) archetype(kind EnemyKind) *EnemyArchetype {
+	if a, ok := w.Cfg.Archetype(kind); ok {
+		return a
+	}
+	if a, ok := w.Cfg.Archetype(w.Cfg.DefaultEnemy); ok {
+		return a
+	}
+	if len(w.Cfg.Enemies) > 0 {
+		return &w.Cfg.Enemies[0]
+	}
+	return &fallbackArchetype
+}
+
+// fallbackArchetype keeps a config with no archetypes simulating.
+var fallbackArchetype = EnemyArchetype{ID: EnemyNormal, Radius: 9, Speed: 120, HP: 50, Role: "normal"}
+
+func (a *EnemyArchetype) aiRole() jobs.EnemyRole {
+	return enemyRoles[a.Role]
+}
+
+// weight evaluates the spawn curve for a wave.
+func (s SpawnCurve) weight(wave, seedBias int) int {
+	if wave < s.FromWave {
+		return 0
+	}
+	v := s.Base + seedBonus(s.SeedBonus, seedBias)
+	if s.Every > 0 {
+		v += s.Step * ((wave - s.BaseWave) / s.Every)
+	}
+	if s.Max > 0 {
+		v = min(v, s.Max)
+	}
+	return max(v, s.Min, 0)
+}
+
+func (g GuaranteeCurve) every(wave, seedBias int) int {
+	return max(g.Min, g.Base+g.PerWave*wave+seedBonus(g.SeedBonus, seedBias), 1)
+}
+
+func seedBonus(bonus []int, seedBias int) int {
+	if len(bonus) == 0 {
+		return 0
+	}
+	return bonus[positiveModInt(seedBias, len(bonus))]
+}
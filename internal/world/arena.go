@@ -5,7 +5,10 @@ import (
 	"math/rand"
 )
 
-func generateObstacles(worldW, worldH float32, cfg Config, seed int64, anchor Vec2) []Obstacle {
+// generateObstacles scatters obstacles over the worldW x worldH area at origin
+// with rejection sampling, keeping clear of the start, each other and solid
+// terrain.
+func generateObstacles(origin Vec2, worldW, worldH float32, cfg Config, seed int64, anchor Vec2, terrain *TileMap) []Obstacle {
 	if cfg.ObstacleCount <= 0 || cfg.ObstacleRadiusMax <= 0 {
 		return nil
 	}
@@ -35,10 +38,10 @@ func generateObstacles(worldW, worldH float32, cfg Config, seed int64, anchor Ve
 		}
 
 		pos := Vec2{
-			X: radius + rng.Float32()*maxf(1, worldW-radius*2),
-			Y: radius + rng.Float32()*maxf(1, worldH-radius*2),
+			X: origin.X + radius + rng.Float32()*maxf(1, worldW-radius*2),
+			Y: origin.Y + radius + rng.Float32()*maxf(1, worldH-radius*2),
 		}
-		if dist2(pos, anchor) < squaref(radius+safeRadius) {
+		if dist2(pos, anchor) < squaref(radius+safeRadius) || terrain.overlapsSolid(pos, radius) {
 			continue
 		}
 
@@ -54,38 +57,74 @@ func generateObstacles(worldW, worldH float32, cfg Config, seed int64, anchor Ve
 			continue
 		}
 
-		out = append(out, Obstacle{Pos: pos, R: radius})
+		out = append(out, shapeObstacle(rng, cfg, pos, radius))
 	}
 
 	return out
 }
 
+// shapeObstacle rolls the obstacle's shape. Both shapes fit inside radius, so
+// the spacing above holds for any of them. Circle-only configs draw nothing
+// extra from rng and keep their old layouts.
+func shapeObstacle(rng *rand.Rand, cfg Config, pos Vec2, radius float32) Obstacle {
+	o := Obstacle{Pos: pos, R: radius}
+	if cfg.ObstacleRectChance <= 0 && cfg.ObstaclePolyChance <= 0 {
+		return o
+	}
+	roll := rng.Float32()
+	switch {
+	case roll < cfg.ObstacleRectChance:
+		// corners on the circle, between squat and tall
+		a := 0.45 + rng.Float64()*0.67
+		o.Shape = ShapeRect
+		o.Half = Vec2{X: radius * float32(math.Cos(a)), Y: radius * float32(math.Sin(a))}
+	case roll < cfg.ObstacleRectChance+cfg.ObstaclePolyChance:
+		n := 5 + rng.Intn(3)
+		step := 2 * math.Pi / float64(n)
+		start := rng.Float64() * step
+		o.Shape = ShapePolygon
+		o.Points = make([]Vec2, n)
+		for i := range o.Points {
+			a := start + (float64(i)+(rng.Float64()-0.5)*0.5)*step
+			o.Points[i] = polar(float32(a), radius)
+		}
+	}
+	return o
+}
+
 func (w *World) resolveEntityPosition(pos Vec2, r float32) Vec2 {
+	loX, loY := r, r
 	hiX := maxf(r, w.W-r)
 	hiY := maxf(r, w.H-r)
-	pos.X = clamp(pos.X, r, hiX)
-	pos.Y = clamp(pos.Y, r, hiY)
+	if w.chunked() {
+		loX, loY = -math.MaxFloat32, -math.MaxFloat32
+		hiX, hiY = math.MaxFloat32, math.MaxFloat32
+	}
+	pos.X = clamp(pos.X, loX, hiX)
+	pos.Y = clamp(pos.Y, loY, hiY)
+	if w.Terrain != nil {
+		pos = w.Terrain.nearestOpen(pos)
+	}
 
-	for range 2 {
+	for range 4 {
 		resolved := true
-		for _, obstacle := range w.Obstacles {
-			minDist := obstacle.R + r + w.Cfg.ObstaclePadding
-			delta := pos.Sub(obstacle.Pos)
-			d2 := delta.X*delta.X + delta.Y*delta.Y
-			if d2 >= minDist*minDist {
+		if w.Terrain != nil {
+			if moved, hit := w.Terrain.pushOut(pos, r); hit {
+				resolved = false
+				pos.X = clamp(moved.X, loX, hiX)
+				pos.Y = clamp(moved.Y, loY, hiY)
+			}
+		}
+		// obstacles push last: where one pins an entity against a wall, the
+		// entity may graze the wall but never sits inside the obstacle
+		for i := range w.Obstacles {
+			moved, hit := w.Obstacles[i].pushOut(pos, r+w.Cfg.ObstaclePadding)
+			if !hit {
 				continue
 			}
-
 			resolved = false
-			if d2 == 0 {
-				pos = Vec2{X: obstacle.Pos.X + minDist, Y: obstacle.Pos.Y}
-			} else {
-				d := float32(math.Sqrt(float64(d2)))
-				pos = obstacle.Pos.Add(delta.Mul(minDist / d))
-			}
-
-			pos.X = clamp(pos.X, r, hiX)
-			pos.Y = clamp(pos.Y, r, hiY)
+			pos.X = clamp(moved.X, loX, hiX)
+			pos.Y = clamp(moved.Y, loY, hiY)
 		}
 		if resolved {
 			break
@@ -95,10 +134,20 @@ func (w *World) resolveEntityPosition(pos Vec2, r float32) Vec2 {
 	return pos
 }
 
+// outOfBounds reports whether p left the arena; an endless field has no edge.
+func (w *World) outOfBounds(p Vec2) bool {
+	return !w.chunked() && (p.X < 0 || p.X > w.W || p.Y < 0 || p.Y > w.H)
+}
+
+// overlapsObstacle reports whether a circle touches an obstacle. Terrain is
+// not included; shots fly over walls and water.
 func (w *World) overlapsObstacle(pos Vec2, r float32) bool {
-	for _, obstacle := range w.Obstacles {
-		minDist := obstacle.R + r + w.Cfg.ObstaclePadding
-		if dist2(pos, obstacle.Pos) < minDist*minDist {
+	for i := range w.Obstacles {
+		o := &w.Obstacles[i]
+		if !o.near(pos, r+w.Cfg.ObstaclePadding) {
+			continue
+		}
+		if d, _ := o.Distance(pos); d < r+w.Cfg.ObstaclePadding {
 			return true
 		}
 	}
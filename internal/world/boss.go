@@ -0,0 +1,324 @@
+package world
+
+import (
+	"fmt"
+	"math"
+)
+
+// BossDef turns an archetype into a boss: HP-gated phases, each cycling a
+// scripted attack pattern.
+type BossDef struct {
+	Label    string      `json:"label"` // wave label while the boss wave runs
+	RewardXP float32     `json:"reward_xp"`
+	Phases   []BossPhase `json:"phases"`
+}
+
+// BossPhase starts once the boss's HP fraction drops to HPBelow. The first
+// phase uses 1; later phases must have strictly lower thresholds.
+type BossPhase struct {
+	HPBelow  float32      `json:"hp_below"`
+	SpeedMul float32      `json:"speed_mul"`
+	Pattern  []BossAttack `json:"pattern"`
+}
+
+// BossAttackKind names a scripted boss attack.
+type BossAttackKind string
+
+const (
+	BossRing   BossAttackKind = "ring"   // Count shots spread evenly around the boss
+	BossCharge BossAttackKind = "charge" // dash at the player for Duration
+	BossSummon BossAttackKind = "summon" // spawn Count Summon enemies around the boss
+)
+
+// BossAttack is one step of a phase pattern: a telegraphed Windup, the
+// attack, then Recover seconds before the next step.
+type BossAttack struct {
+	Kind    BossAttackKind `json:"kind"`
+	Windup  float32        `json:"windup"`
+	Recover float32        `json:"recover"`
+
+	Count    int     `json:"count,omitempty"`
+	Speed    float32 `json:"speed,omitempty"`
+	Damage   float32 `json:"damage,omitempty"`
+	Duration float32 `json:"duration,omitempty"` // charge time, or ring shot lifetime
+	Offset   float32 `json:"offset,omitempty"`   // ring rotation, in fractions of the shot spacing
+
+	Summon EnemyKind `json:"summon,omitempty"`
+}
+
+// BossStage is where the current attack is in its cycle.
+type BossStage uint8
+
+const (
+	BossWindup BossStage = iota
+	BossCharging
+	BossRecover
+)
+
+// BossState tracks the live boss fight. The zero value means no boss.
+type BossState struct {
+	Active  bool      `json:"active"`
+	EnemyID int       `json:"enemy_id"`
+	Kind    EnemyKind `json:"kind"`
+	Phase   int       `json:"phase"`
+	Step    int       `json:"step"`
+	Stage   BossStage `json:"stage"`
+	Timer   float32   `json:"timer"`
+	Aim     Vec2      `json:"aim"` // charge direction; tracks the player during windup
+}
+
+func (d *BossDef) validate(archetypes map[EnemyKind]bool) error {
+	if len(d.Phases) == 0 {
+		return fmt.Errorf("no phases")
+	}
+	prev := float32(math.Inf(1))
+	for i, p := range d.Phases {
+		switch {
+		case p.HPBelow <= 0 || p.HPBelow > 1:
+			return fmt.Errorf("phase %d hp_below must be in (0, 1]", i)
+		case p.HPBelow >= prev:
+			return fmt.Errorf("phase %d hp_below must be below the previous phase", i)
+		case len(p.Pattern) == 0:
+			return fmt.Errorf("phase %d has no attacks", i)
+		}
+		prev = p.HPBelow
+		for j, a := range p.Pattern {
+			if err := a.validate(archetypes); err != nil {
+				return fmt.Errorf("phase %d attack %d: %w", i, j, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (a BossAttack) validate(archetypes map[EnemyKind]bool) error {
+	if a.Windup < 0 || a.Recover < 0 {
+		return fmt.Errorf("negative windup or recover")
+	}
+	switch a.Kind {
+	case BossRing:
+		if a.Count <= 0 || a.Speed <= 0 || a.Duration <= 0 {
+			return fmt.Errorf("ring needs positive count, speed and duration")
+		}
+	case BossCharge:
+		if a.Speed <= 0 || a.Duration <= 0 {
+			return fmt.Errorf("charge needs positive speed and duration")
+		}
+	case BossSummon:
+		if a.Count <= 0 {
+			return fmt.Errorf("summon needs a positive count")
+		}
+		if isBoss, ok := archetypes[a.Summon]; !ok || isBoss {
+			return fmt.Errorf("summon %q is not a regular archetype", a.Summon)
+		}
+	default:
+		return fmt.Errorf("unknown attack %q", a.Kind)
+	}
+	return nil
+}
+
+// CurrentAttack returns the attack the boss is winding up, charging or
+// recovering from.
+func (b BossState) CurrentAttack(cfg *Config) (BossAttack, bool) {
+	if !b.Active {
+		return BossAttack{}, false
+	}
+	a, ok := cfg.Archetype(b.Kind)
+	if !ok || a.Boss == nil || b.Phase >= len(a.Boss.Phases) {
+		return BossAttack{}, false
+	}
+	pattern := a.Boss.Phases[b.Phase].Pattern
+	return pattern[b.Step%len(pattern)], true
+}
+
+// bossForWave picks the boss for a boss wave, rotating through the boss
+// archetypes in content order. It returns "" for regular waves.
+func bossForWave(cfg Config, index int) EnemyKind {
+	if cfg.BossEvery <= 0 || index%cfg.BossEvery != 0 {
+		return ""
+	}
+	var bosses []EnemyKind
+	for _, a := range cfg.Enemies {
+		if a.Boss != nil {
+			bosses = append(bosses, a.ID)
+		}
+	}
+	if len(bosses) == 0 {
+		return ""
+	}
+	return bosses[(index/cfg.BossEvery-1)%len(bosses)]
+}
+
+// spawnBoss spawns kind at the spawn radius. Each full rotation through the
+// bosses adds half their base HP. A boss still alive from an earlier wave
+// keeps the fight; no second boss spawns.
+func (w *World) spawnBoss(kind EnemyKind) {
+	a := w.archetype(kind)
+	if w.Boss.Active || a.Boss == nil {
+		return
+	}
+
+	rotations := 0
+	if n := w.bossCount(); n > 0 && w.Cfg.BossEvery > 0 {
+		rotations = (w.Wave.Index/w.Cfg.BossEvery - 1) / n
+	}
+
+	ang := w.randFloat32() * 2 * math.Pi
+	id := w.spawnEnemy(kind, w.Player.Pos.Add(polar(ang, w.Cfg.SpawnRadius)))
+	e := &w.Enemies[len(w.Enemies)-1]
+	e.MaxHP = a.HP * (1 + 0.5*float32(rotations))
+	e.HP = e.MaxHP
+
+	w.Boss = BossState{Active: true, EnemyID: id, Kind: kind}
+	w.enterBossPhase(e, a.Boss, 0)
+}
+
+func (w *World) bossCount() int {
+	n := 0
+	for _, a := range w.Cfg.Enemies {
+		if a.Boss != nil {
+			n++
+		}
+	}
+	return n
+}
+
+func (w *World) enterBossPhase(e *Enemy, def *BossDef, phase int) {
+	p := def.Phases[phase]
+	w.Boss.Phase = phase
+	w.Boss.Step = 0
+	w.Boss.Stage = BossWindup
+	w.Boss.Timer = p.Pattern[0].Windup
+
+	mul := p.SpeedMul
+	if mul <= 0 {
+		mul = 1
+	}
+	e.Speed = w.archetype(e.Kind).Speed * mul
+}
+
+func (w *World) bossIndex() int {
+	for i := range w.Enemies {
+		if w.Enemies[i].ID == w.Boss.EnemyID {
+			return i
+		}
+	}
+	return -1
+}
+
+// bossCharging reports whether enemy id is the boss mid-charge, which moves
+// it instead of the regular AI.
+func (w *World) bossCharging(id int) bool {
+	return w.Boss.Active && w.Boss.Stage == BossCharging && w.Boss.EnemyID == id
+}
+
+func (w *World) updateBoss(dt float32) {
+	if !w.Boss.Active {
+		return
+	}
+	idx := w.bossIndex()
+	def := w.archetype(w.Boss.Kind).Boss
+	if idx < 0 || def == nil || w.Boss.Phase >= len(def.Phases) {
+		w.Boss = BossState{}
+		return
+	}
+	e := &w.Enemies[idx]
+
+	// HP thresholds can skip phases on a big hit; jump to the deepest one
+	next := w.Boss.Phase
+	for i := w.Boss.Phase + 1; i < len(def.Phases); i++ {
+		if e.HP <= e.MaxHP*def.Phases[i].HPBelow {
+			next = i
+		}
+	}
+	if next != w.Boss.Phase {
+		w.enterBossPhase(e, def, next)
+	}
+
+	// stun and freeze pause the pattern
+	if statusHeld(e.Statuses) {
+		return
+	}
+
+	b := &w.Boss
+	pattern := def.Phases[b.Phase].Pattern
+	atk := pattern[b.Step%len(pattern)]
+	b.Timer -= dt
+
+	switch b.Stage {
+	case BossWindup:
+		if atk.Kind == BossCharge {
+			if aim := w.Player.Pos.Sub(e.Pos).Norm(); aim != (Vec2{}) {
+				b.Aim = aim
+			}
+		}
+		if b.Timer > 0 {
+			return
+		}
+		if atk.Kind == BossCharge {
+			b.Stage = BossCharging
+			b.Timer = atk.Duration
+			return
+		}
+		b.Stage = BossRecover
+		b.Timer = atk.Recover
+		// summons append to Enemies, so e is not used past this point
+		w.bossAttack(e.Pos, e.Kind, atk)
+	case BossCharging:
+		e.Pos = w.moveEntity(e.Pos, e.Pos.Add(b.Aim.Mul(atk.Speed*dt)), e.R)
+		if b.Timer <= 0 {
+			b.Stage = BossRecover
+			b.Timer = atk.Recover
+		}
+	case BossRecover:
+		if b.Timer > 0 {
+			return
+		}
+		b.Step = (b.Step + 1) % len(pattern)
+		b.Stage = BossWindup
+		b.Timer = pattern[b.Step].Windup
+	}
+}
+
+func (w *World) bossAttack(pos Vec2, source EnemyKind, atk BossAttack) {
+	switch atk.Kind {
+	case BossRing:
+		gap := 2 * math.Pi / float32(atk.Count)
+		for i := range atk.Count {
+			a := gap * (float32(i) + atk.Offset)
+			w.Shots = append(w.Shots, EnemyProjectile{
+				Pos:    pos,
+				Vel:    polar(a, atk.Speed),
+				R:      6,
+				Damage: atk.Damage,
+				Life:   atk.Duration,
+				Source: source,
+			})
+		}
+	case BossSummon:
+		// a ring just outside the boss, so summons do not stack on it
+		r := w.archetype(source).Radius + w.archetype(atk.Summon).Radius + 8
+		gap := 2 * math.Pi / float32(atk.Count)
+		for i := range atk.Count {
+			w.spawnEnemy(atk.Summon, pos.Add(polar(gap*float32(i), r)))
+		}
+	}
+}
+
+// defeatBoss pays out the guaranteed reward where the boss died.
+func (w *World) defeatBoss(pos Vec2) {
+	def := w.archetype(w.Boss.Kind).Boss
+	w.Boss = BossState{}
+	if def == nil {
+		return
+	}
+	if def.RewardXP > 0 {
+		w.spawnXPOrb(pos, def.RewardXP)
+	}
+	weapon := w.randomWeaponKind()
+	w.Drops = append(w.Drops, WeaponDrop{
+		Pos:  pos,
+		R:    weaponDef(weapon).DropRadius,
+		Kind: weapon,
+	})
+}
@@ -0,0 +1,437 @@
+package world
+
+import (
+	"math"
+	"math/rand"
+	"sync"
+)
+
+// ChunkCoord indexes a ChunkSize square of the endless field; chunk (0, 0)
+// starts at the world origin.
+type ChunkCoord struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+// Chunk is the generated content of one square: its tiles (row-major, nil
+// without terrain) and its obstacles in world space.
+type Chunk struct {
+	Coord     ChunkCoord
+	Tiles     []TileKind
+	Obstacles []Obstacle
+}
+
+// ChunkParams is everything chunk generation reads. A chunk is a pure
+// function of its params and coordinate, so streamed and synchronously
+// generated chunks are identical.
+type ChunkParams struct {
+	Size      float32
+	Seed      int64
+	Terrain   TerrainParams // window fields unused; zero for no terrain
+	Obstacles Config        // Obstacle* fields and StartSafeRadius
+	Safe      Vec2          // the player's start, kept clear
+}
+
+// chunkParams derives a run's chunk params. Terrain must already be the
+// run's terrain params (biome and seed picked).
+func chunkParams(cfg Config, seed int64, terrain TerrainParams, start Vec2) ChunkParams {
+	p := ChunkParams{
+		Size: cfg.ChunkSize,
+		Seed: seed,
+		Obstacles: Config{
+			ObstacleCount:      cfg.ChunkObstacles,
+			ObstacleRadiusMin:  cfg.ObstacleRadiusMin,
+			ObstacleRadiusMax:  cfg.ObstacleRadiusMax,
+			ObstaclePadding:    cfg.ObstaclePadding,
+			ObstacleRectChance: cfg.ObstacleRectChance,
+			ObstaclePolyChance: cfg.ObstaclePolyChance,
+			StartSafeRadius:    cfg.StartSafeRadius,
+			SpawnRadius:        cfg.SpawnRadius,
+		},
+		Safe: start,
+	}
+	if terrain.TileSize > 0 {
+		// chunks hold whole tiles
+		n := max(1, int(math.Round(float64(cfg.ChunkSize/terrain.TileSize))))
+		p.Size = float32(n) * terrain.TileSize
+		p.Terrain = TerrainParams{
+			Biome:      terrain.Biome,
+			Seed:       terrain.Seed,
+			TileSize:   terrain.TileSize,
+			Safe:       start,
+			SafeRadius: terrain.SafeRadius,
+			Chunk:      n,
+		}
+	}
+	return p
+}
+
+// GenerateChunk builds the chunk at c.
+func GenerateChunk(p ChunkParams, c ChunkCoord) *Chunk {
+	ch := &Chunk{Coord: c}
+	origin := Vec2{X: float32(c.X) * p.Size, Y: float32(c.Y) * p.Size}
+	var tiles *TileMap
+	if p.Terrain.TileSize > 0 {
+		ch.Tiles = generateChunkTiles(p.Terrain, c)
+		n := p.Terrain.Chunk
+		tiles = &TileMap{
+			Params: TerrainParams{TileSize: p.Terrain.TileSize, Cols: n, Rows: n, Chunk: n, Origin: c},
+			Tiles:  ch.Tiles,
+		}
+	}
+	// the inset keeps obstacles of neighbouring chunks as far apart as two in
+	// the same chunk
+	inset := p.Obstacles.ObstaclePadding + 9
+	ch.Obstacles = generateObstacles(
+		origin.Add(Vec2{X: inset, Y: inset}), p.Size-2*inset, p.Size-2*inset,
+		p.Obstacles, chunkSeed(p.Seed, c), p.Safe, tiles)
+	return ch
+}
+
+// generateChunkTiles grows one chunk of terrain. Walls come from the same
+// automaton as a bounded map, run inside the chunk with its border ring kept
+// open, so neighbouring chunks always connect. Water, rough floor and
+// bridges follow field-wide noise and spacing, so they line up across seams.
+func generateChunkTiles(p TerrainParams, c ChunkCoord) []TileKind {
+	preset := biomePresets[p.Biome]
+	n := p.Chunk
+	m := &TileMap{
+		Params: TerrainParams{TileSize: p.TileSize, Cols: n, Rows: n, Chunk: n, Origin: c},
+		Tiles:  make([]TileKind, n*n),
+	}
+	rng := rand.New(rand.NewSource(chunkSeed(p.Seed, c)))
+	walls := make([]bool, len(m.Tiles))
+	for i := range walls {
+		walls[i] = rng.Float32() < preset.WallFill
+	}
+	for range preset.WallSteps {
+		walls = m.smoothWalls(walls)
+	}
+
+	safeR := p.SafeRadius + p.TileSize
+	var starts []int
+	for r := range n {
+		for col := range n {
+			i := r*n + col
+			gc, gr := c.X*n+col, c.Y*n+r
+			border := col == 0 || r == 0 || col == n-1 || r == n-1
+			center := Vec2{X: (float32(gc) + 0.5) * p.TileSize, Y: (float32(gr) + 0.5) * p.TileSize}
+			switch {
+			case dist2(center, p.Safe) < safeR*safeR:
+				m.Tiles[i] = TileFloor
+				starts = append(starts, i)
+				continue
+			case walls[i] && !border:
+				m.Tiles[i] = TileWall
+			case latticeNoise(p.Seed, 0, gc, gr, preset.NoiseScale) > preset.WaterLevel:
+				m.Tiles[i] = TileWater
+				if preset.BridgeEvery > 0 && (positiveModInt(gc, preset.BridgeEvery) == 0 || positiveModInt(gr, preset.BridgeEvery) == 0) {
+					m.Tiles[i] = TileBridge
+				}
+			case latticeNoise(p.Seed, 1, gc, gr, preset.NoiseScale) > preset.RoughLevel:
+				m.Tiles[i] = TileRough
+			}
+			if border && !m.Tiles[i].Solid() {
+				starts = append(starts, i)
+			}
+		}
+	}
+	m.sealFrom(starts)
+	return m.Tiles
+}
+
+// stitchTerrain assembles the window p describes from per-chunk tiles.
+func stitchTerrain(p TerrainParams, tiles func(ChunkCoord) []TileKind) *TileMap {
+	m := &TileMap{Params: p, Tiles: make([]TileKind, p.Cols*p.Rows)}
+	n := p.Chunk
+	for cy := 0; cy*n < p.Rows; cy++ {
+		for cx := 0; cx*n < p.Cols; cx++ {
+			src := tiles(ChunkCoord{X: p.Origin.X + cx, Y: p.Origin.Y + cy})
+			for r := range n {
+				row := cy*n + r
+				if row >= p.Rows {
+					break
+				}
+				cols := min(n, p.Cols-cx*n)
+				copy(m.Tiles[row*p.Cols+cx*n:row*p.Cols+cx*n+cols], src[r*n:r*n+cols])
+			}
+		}
+	}
+	return m
+}
+
+// latticeNoise is valueNoise over an unbounded lattice whose points hash
+// from the seed, so any chunk can sample it without its neighbours.
+func latticeNoise(seed int64, layer, c, r, scale int) float32 {
+	scale = max(scale, 1)
+	fx := float32(c) / float32(scale)
+	fy := float32(r) / float32(scale)
+	x0, y0 := int(math.Floor(float64(fx))), int(math.Floor(float64(fy)))
+	tx, ty := smoothstep(fx-float32(x0)), smoothstep(fy-float32(y0))
+	v := func(x, y int) float32 {
+		h := mix64(uint64(seed) ^ mix64(uint64(layer)<<48^uint64(uint32(x))<<24^uint64(uint32(y))))
+		return float32(h>>40) / (1 << 24)
+	}
+	top := v(x0, y0) + (v(x0+1, y0)-v(x0, y0))*tx
+	bottom := v(x0, y0+1) + (v(x0+1, y0+1)-v(x0, y0+1))*tx
+	return top + (bottom-top)*ty
+}
+
+func chunkSeed(seed int64, c ChunkCoord) int64 {
+	return int64(mix64(uint64(seed) ^ mix64(uint64(uint32(c.X))<<32|uint64(uint32(c.Y)))))
+}
+
+// mix64 is the splitmix64 finalizer.
+func mix64(x uint64) uint64 {
+	x += 0x9e3779b97f4a7c15
+	x = (x ^ x>>30) * 0xbf58476d1ce4e5b9
+	x = (x ^ x>>27) * 0x94d049bb133111eb
+	return x ^ x>>31
+}
+
+// ChunkRequest asks the streamer for the chunk at Coord.
+type ChunkRequest struct {
+	Params ChunkParams
+	Coord  ChunkCoord
+}
+
+// ChunkStreamer generates chunks on its own goroutine. Like assets.Loader it
+// never blocks on a slow consumer; dropped results are regenerated on demand.
+type ChunkStreamer struct {
+	Req  chan ChunkRequest
+	Res  chan *Chunk
+	quit chan struct{}
+
+	closeOnce sync.Once
+}
+
+func NewChunkStreamer() *ChunkStreamer {
+	s := &ChunkStreamer{
+		Req:  make(chan ChunkRequest, 32),
+		Res:  make(chan *Chunk, 32),
+		quit: make(chan struct{}),
+	}
+
+	go s.loop()
+
+	return s
+}
+
+func (s *ChunkStreamer) Close() {
+	s.closeOnce.Do(func() {
+		close(s.quit)
+	})
+}
+
+func (s *ChunkStreamer) loop() {
+	for {
+		select {
+		case <-s.quit:
+			return
+		case req := <-s.Req:
+			ch := GenerateChunk(req.Params, req.Coord)
+			select {
+			case <-s.quit:
+				return
+			case s.Res <- ch:
+			default:
+			}
+		}
+	}
+}
+
+// chunkCache holds generated chunks around the player. Only the window, a
+// function of the player's chunk, feeds the simulation; what the streamer has
+// delivered so far only decides whether a window chunk is generated here or
+// taken ready-made.
+type chunkCache struct {
+	params   ChunkParams
+	streamer *ChunkStreamer
+	loaded   map[ChunkCoord]*Chunk
+	pending  map[ChunkCoord]bool
+	center   ChunkCoord
+	ready    bool // the window around center is applied
+
+	// diagnostics; they depend on goroutine timing, so stay out of snapshots
+	streamed, generated, unloaded int
+}
+
+// ChunkStats reports how many chunks are held, and how many arrived from the
+// streamer, were generated on the tick because they were late, or were
+// unloaded.
+type ChunkStats struct {
+	Loaded    int
+	Streamed  int
+	Generated int
+	Unloaded  int
+}
+
+func (w *World) ChunkStats() ChunkStats {
+	c := &w.chunks
+	return ChunkStats{Loaded: len(c.loaded), Streamed: c.streamed, Generated: c.generated, Unloaded: c.unloaded}
+}
+
+// chunked reports whether the world streams an endless field instead of the
+// W x H box.
+func (w *World) chunked() bool {
+	return w.Cfg.ChunkSize > 0
+}
+
+// initChunks points the cache at the run's chunk params and restarts the
+// streamer when the world is chunked. Requests still in flight were made
+// with the old params, so their results must not reach the new cache. The
+// window is applied on the next updateChunks.
+func (w *World) initChunks(terrain TerrainParams) {
+	c := &w.chunks
+	c.close()
+	if !w.chunked() {
+		*c = chunkCache{}
+		return
+	}
+	*c = chunkCache{
+		params:   chunkParams(w.Cfg, w.rngSeed, terrain, Vec2{X: w.W / 2, Y: w.H / 2}),
+		streamer: NewChunkStreamer(),
+		loaded:   make(map[ChunkCoord]*Chunk, 64),
+		pending:  make(map[ChunkCoord]bool, 32),
+	}
+}
+
+func (c *chunkCache) close() {
+	if c.streamer != nil {
+		c.streamer.Close()
+		c.streamer = nil
+	}
+}
+
+func (w *World) chunkOf(p Vec2) ChunkCoord {
+	size := w.chunks.params.Size
+	return ChunkCoord{X: int(math.Floor(float64(p.X / size))), Y: int(math.Floor(float64(p.Y / size)))}
+}
+
+// updateChunks takes streamed chunks, moves the window when the player
+// enters a new chunk and brings stragglers back.
+func (w *World) updateChunks() {
+	if !w.chunked() {
+		return
+	}
+	c := &w.chunks
+	c.drain()
+	if center := w.chunkOf(w.Player.Pos); !c.ready || center != c.center {
+		w.applyChunkWindow(center)
+	}
+	w.recycleStragglers()
+}
+
+func (c *chunkCache) drain() {
+	if c.streamer == nil {
+		return
+	}
+	for {
+		select {
+		case ch := <-c.streamer.Res:
+			delete(c.pending, ch.Coord)
+			if _, ok := c.loaded[ch.Coord]; !ok {
+				c.loaded[ch.Coord] = ch
+				c.streamed++
+			}
+		default:
+			return
+		}
+	}
+}
+
+// chunk returns the chunk at coord, generating it now if the streamer has
+// not delivered it.
+func (c *chunkCache) chunk(coord ChunkCoord) *Chunk {
+	if ch, ok := c.loaded[coord]; ok {
+		return ch
+	}
+	ch := GenerateChunk(c.params, coord)
+	c.loaded[coord] = ch
+	c.generated++
+	return ch
+}
+
+// applyChunkWindow rebuilds obstacles and terrain from the chunks within
+// ChunkRadius of center, prefetches the ring beyond and drops chunks further
+// out.
+func (w *World) applyChunkWindow(center ChunkCoord) {
+	c := &w.chunks
+	rad := max(w.Cfg.ChunkRadius, 0)
+	origin := ChunkCoord{X: center.X - rad, Y: center.Y - rad}
+
+	var obstacles []Obstacle
+	for y := origin.Y; y <= center.Y+rad; y++ {
+		for x := origin.X; x <= center.X+rad; x++ {
+			obstacles = append(obstacles, c.chunk(ChunkCoord{X: x, Y: y}).Obstacles...)
+		}
+	}
+	w.Obstacles = cloneObstacles(obstacles)
+
+	w.Terrain = nil
+	if t := c.params.Terrain; t.TileSize > 0 {
+		span := (2*rad + 1) * t.Chunk
+		t.Cols, t.Rows, t.Origin = span, span, origin
+		w.Terrain = stitchTerrain(t, func(coord ChunkCoord) []TileKind { return c.chunk(coord).Tiles })
+	}
+
+	for coord := range c.loaded {
+		if chebyshev(coord, center) > rad+2 {
+			delete(c.loaded, coord)
+			c.unloaded++
+		}
+	}
+	for coord := range c.pending {
+		if chebyshev(coord, center) > rad+2 {
+			delete(c.pending, coord)
+		}
+	}
+	if c.streamer != nil {
+		ring := rad + 1
+		for y := center.Y - ring; y <= center.Y+ring; y++ {
+			for x := center.X - ring; x <= center.X+ring; x++ {
+				coord := ChunkCoord{X: x, Y: y}
+				if chebyshev(coord, center) != ring || c.pending[coord] || c.loaded[coord] != nil {
+					continue
+				}
+				select {
+				case c.streamer.Req <- ChunkRequest{Params: c.params, Coord: coord}:
+					c.pending[coord] = true
+				default:
+				}
+			}
+		}
+	}
+
+	c.center = center
+	c.ready = true
+	w.flowStale = true
+}
+
+func chebyshev(a, b ChunkCoord) int {
+	return max(a.X-b.X, b.X-a.X, a.Y-b.Y, b.Y-a.Y)
+}
+
+// recycleStragglers moves enemies left more than DespawnDistance behind to
+// the opposite side of the spawn ring, back in front of the player.
+func (w *World) recycleStragglers() {
+	limit := w.Cfg.DespawnDistance
+	if limit <= 0 {
+		return
+	}
+	p := w.Player.Pos
+	moved := false
+	for i := range w.Enemies {
+		e := &w.Enemies[i]
+		d := e.Pos.Sub(p)
+		if d.X*d.X+d.Y*d.Y <= limit*limit {
+			continue
+		}
+		e.Pos = w.resolveEntityPosition(p.Sub(d.Norm().Mul(w.Cfg.SpawnRadius)), e.R)
+		w.Stats.EnemiesRecycled++
+		moved = true
+	}
+	if moved {
+		w.enemyGrid.stale = true
+	}
+}
@@ -18,6 +18,25 @@ type Config struct {
 	ObstacleRadiusMin float32
 	ObstacleRadiusMax float32
 	ObstaclePadding   float32
+	// Chances that an obstacle is an axis-aligned rect or a convex polygon
+	// instead of a circle.
+	ObstacleRectChance float32
+	ObstaclePolyChance float32
+	// Tile terrain: TerrainTileSize 0 keeps the flat arena. An empty Biome
+	// picks one per run from the seed.
+	TerrainTileSize float32
+	Biome           Biome
+	// Streaming: a ChunkSize above 0 replaces the W x H box with an endless
+	// field of ChunkSize squares. Chunks within ChunkRadius of the player's
+	// chunk are simulated, each scattering ChunkObstacles obstacles. Enemies
+	// more than DespawnDistance from the player are moved back in front of it.
+	ChunkSize       float32
+	ChunkRadius     int
+	ChunkObstacles  int
+	DespawnDistance float32
+	// Pathing: enemies follow a flow field of FlowCellSize cells around
+	// obstacles and solid terrain; 0 steers them straight at the player.
+	FlowCellSize float32
 
 	// Player
 	PlayerRadius         float32
@@ -29,23 +48,47 @@ type Config struct {
 	PlayerAttackCooldown float32
 	PlayerAttackRange    float32
 	PlayerDamage         float32
+	WeaponSlots          int
 
 	// Knockback feel
 	PlayerKnockbackSpeed   float32
 	PlayerKnockbackDamping float32
 
-	// Enemy
-	EnemyRadius      float32
-	EnemySpeed       float32
-	EnemyHP          float32
-	EnemyTouchDamage float32
+	// Enemy archetypes, from content/enemies.json by default. DefaultEnemy
+	// spawns when no archetype has weight in the current wave.
+	DefaultEnemy EnemyKind
+	Enemies      []EnemyArchetype
+	// Every BossEvery-th wave spawns the next boss archetype; 0 disables.
+	BossEvery int
+	// Elite rolls and affixes, from the same content file.
+	Elites EliteConfig
+	// Pickup drops, from content/pickups.json.
+	Pickups []PickupDef
 
 	// XP
 	XPOrbRadius     float32
 	XPPickupPadding float32
-	XPPerKill       float32
 	XPBaseToNext    float32
 	XPGrowthToNext  float64
+	// Orbs inside the magnet radius fly to the player, starting at
+	// XPOrbPullSpeed and accelerating to XPOrbMaxSpeed; XPOrbAccel 0 keeps
+	// the old instant pickup. Past XPOrbCap orbs (0 leaves the count
+	// unbounded), resting orbs within XPOrbMergeRadius merge into gems.
+	XPOrbPullSpeed   float32
+	XPOrbAccel       float32
+	XPOrbMaxSpeed    float32
+	XPOrbCap         int
+	XPOrbMergeRadius float32
+
+	// Level-up menu: options per offer (below 2 means the legacy two) and
+	// the reroll, skip and banish charges a run starts with.
+	UpgradeChoices  int
+	UpgradeRerolls  int
+	UpgradeSkips    int
+	UpgradeBanishes int
+
+	// Permanent bonuses bought between runs, applied to every fresh player.
+	Meta MetaBonuses
 
 	// Visual timers
 	LastAttackMax float32
@@ -55,24 +98,20 @@ type Config struct {
 	HitShakeMagnitude float32
 	HitShakeFreq1     float32
 	HitShakeFreq2     float32
+}
 
-	// Enemy archetypes
-	// 1. Runner
-	EnemyRunnerRadius      float32
-	EnemyRunnerSpeed       float32
-	EnemyRunnerHP          float32
-	EnemyRunnerTouchDamage float32
-	EnemyRunnerXP          float32
-
-	// 2. Tank
-	EnemyTankRadius      float32
-	EnemyTankSpeed       float32
-	EnemyTankHP          float32
-	EnemyTankTouchDamage float32
-	EnemyTankXP          float32
+// MetaBonuses are the profile's shop upgrades. A replay's initial config
+// carries them, so playback rebuilds the player it was recorded with.
+type MetaBonuses struct {
+	MaxHP    float32 `json:"max_hp,omitempty"`
+	Damage   float32 `json:"damage,omitempty"` // fraction added to base damage
+	Magnet   float32 `json:"magnet,omitempty"`
+	Revivals int     `json:"revivals,omitempty"`
+	Rerolls  int     `json:"rerolls,omitempty"`
 }
 
 func DefaultConfig() Config {
+	enemies := DefaultEnemyContent()
 	return Config{
 		BaseSpawnEvery:  0.75,
 		MinSpawnEvery:   0.20,
@@ -83,10 +122,18 @@ func DefaultConfig() Config {
 		WaveDuration:    20,
 		StartSafeRadius: 220,
 
-		ObstacleCount:     8,
-		ObstacleRadiusMin: 28,
-		ObstacleRadiusMax: 54,
-		ObstaclePadding:   6,
+		ObstacleCount:      8,
+		ObstacleRadiusMin:  28,
+		ObstacleRadiusMax:  54,
+		ObstaclePadding:    6,
+		ObstacleRectChance: 0.3,
+		ObstaclePolyChance: 0.3,
+		TerrainTileSize:    40,
+		ChunkSize:          640,
+		ChunkRadius:        2,
+		ChunkObstacles:     1,
+		DespawnDistance:    1100,
+		FlowCellSize:       40,
 
 		PlayerRadius:         10,
 		PlayerSpeed:          260,
@@ -97,40 +144,40 @@ func DefaultConfig() Config {
 		PlayerAttackCooldown: 0.45,
 		PlayerAttackRange:    180,
 		PlayerDamage:         25,
+		WeaponSlots:          4,
 
 		PlayerKnockbackSpeed:   520,
 		PlayerKnockbackDamping: 18,
 
-		EnemyRadius:      9,
-		EnemySpeed:       120,
-		EnemyHP:          50,
-		EnemyTouchDamage: 10,
+		DefaultEnemy: enemies.Default,
+		Enemies:      enemies.Enemies,
+		BossEvery:    5,
+		Elites:       enemies.Elites,
+		Pickups:      DefaultPickupContent().Pickups,
 
 		XPOrbRadius:     6,
 		XPPickupPadding: 10,
-		XPPerKill:       5,
 
 		XPBaseToNext:   25,
 		XPGrowthToNext: 1.28,
 
+		XPOrbPullSpeed:   150,
+		XPOrbAccel:       1600,
+		XPOrbMaxSpeed:    900,
+		XPOrbCap:         400,
+		XPOrbMergeRadius: 48,
+
+		UpgradeChoices:  3,
+		UpgradeRerolls:  2,
+		UpgradeSkips:    2,
+		UpgradeBanishes: 2,
+
 		LastAttackMax: 0.08,
 
 		HitShakeDuration:  0.12,
 		HitShakeMagnitude: 6.0,
 		HitShakeFreq1:     26.0,
 		HitShakeFreq2:     33.0,
-
-		EnemyRunnerRadius:      7,
-		EnemyRunnerSpeed:       190,
-		EnemyRunnerHP:          30,
-		EnemyRunnerTouchDamage: 8,
-		EnemyRunnerXP:          4,
-
-		EnemyTankRadius:      14,
-		EnemyTankSpeed:       75,
-		EnemyTankHP:          140,
-		EnemyTankTouchDamage: 18,
-		EnemyTankXP:          12,
 	}
 }
 
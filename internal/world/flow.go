@@ -0,0 +1,111 @@
+package world
+
+import (
+	"math"
+
+	"horde-lab/internal/jobs"
+)
+
+// flowKey names the last field handed to the pool.
+type flowKey struct {
+	version  uint64
+	col, row int
+}
+
+// currentFlowGrid returns the grid enemies path over, rebuilt after the
+// obstacles or terrain change and, when chunked, when the player enters
+// another chunk. The grid only depends on the current state, so a restored
+// snapshot rebuilds the same one. It is nil when FlowCellSize is 0 or
+// nothing blocks movement.
+func (w *World) currentFlowGrid() *jobs.FlowGrid {
+	if w.chunked() && w.chunkOf(w.Player.Pos) != w.flowChunk {
+		w.flowStale = true
+	}
+	if w.flowStale {
+		w.flowStale = false
+		w.flowVersion++
+		if w.chunked() {
+			w.flowChunk = w.chunkOf(w.Player.Pos)
+		}
+		w.flowGrid = w.buildFlowGrid()
+	}
+	return w.flowGrid
+}
+
+func (w *World) buildFlowGrid() *jobs.FlowGrid {
+	cell := w.Cfg.FlowCellSize
+	if cell <= 0 || (len(w.Obstacles) == 0 && w.Terrain == nil) {
+		return nil
+	}
+	lo, hi := Vec2{}, Vec2{X: w.W, Y: w.H}
+	if w.chunked() {
+		// the window the chunk cache simulates around the player
+		rad := max(w.Cfg.ChunkRadius, 0)
+		size := w.chunks.params.Size
+		lo = Vec2{X: float32(w.flowChunk.X-rad) * size, Y: float32(w.flowChunk.Y-rad) * size}
+		hi = lo.Add(Vec2{X: float32(2*rad+1) * size, Y: float32(2*rad+1) * size})
+	}
+	g := &jobs.FlowGrid{
+		OriginX:  lo.X,
+		OriginY:  lo.Y,
+		CellSize: cell,
+		Cols:     int(math.Ceil(float64((hi.X - lo.X) / cell))),
+		Rows:     int(math.Ceil(float64((hi.Y - lo.Y) / cell))),
+	}
+	if g.Cols <= 0 || g.Rows <= 0 {
+		return nil
+	}
+	g.Blocked = make([]bool, g.Cols*g.Rows)
+	center := func(c, r int) Vec2 {
+		return Vec2{X: lo.X + (float32(c)+0.5)*cell, Y: lo.Y + (float32(r)+0.5)*cell}
+	}
+
+	blocked := 0
+	if w.Terrain != nil {
+		for r := range g.Rows {
+			for c := range g.Cols {
+				if w.Terrain.TileAt(center(c, r)).Solid() {
+					g.Blocked[r*g.Cols+c] = true
+					blocked++
+				}
+			}
+		}
+	}
+	// a cell is blocked once its center is within half a cell of a surface
+	reach := cell / 2
+	for i := range w.Obstacles {
+		o := &w.Obstacles[i]
+		c0, r0, _ := g.Cell(o.Pos.X-o.R-reach, o.Pos.Y-o.R-reach)
+		c1, r1, _ := g.Cell(o.Pos.X+o.R+reach, o.Pos.Y+o.R+reach)
+		for r := max(r0, 0); r <= min(r1, g.Rows-1); r++ {
+			for c := max(c0, 0); c <= min(c1, g.Cols-1); c++ {
+				if g.Blocked[r*g.Cols+c] {
+					continue
+				}
+				if d, _ := o.Distance(center(c, r)); d < reach {
+					g.Blocked[r*g.Cols+c] = true
+					blocked++
+				}
+			}
+		}
+	}
+	if blocked == 0 {
+		return nil
+	}
+	return g
+}
+
+// submitFlowJob hands the field for the player's cell to the pool when the
+// grid or that cell changed. The intent request computes it itself if the
+// pool is late.
+func (w *World) submitFlowJob(tick uint64, grid *jobs.FlowGrid) {
+	col, row, ok := grid.Cell(w.Player.Pos.X, w.Player.Pos.Y)
+	key := flowKey{version: w.flowVersion, col: col, row: row}
+	if !ok || key == w.flowSent {
+		return
+	}
+	req := jobs.FlowFieldRequest{Version: key.version, Grid: grid, GoalCol: col, GoalRow: row, Cache: w.flows}
+	if w.pool.flows.Submit(jobs.Job[jobs.FlowFieldRequest]{Tick: tick, Deadline: tick, Priority: jobs.PriorityNormal, Req: req}) {
+		w.flowSent = key
+	}
+}
@@ -1,7 +1,9 @@
 package world
 
 import (
+	"cmp"
 	"math"
+	"slices"
 
 	"horde-lab/internal/jobs"
 )
@@ -44,45 +46,32 @@ func (w *World) spawnEnemyNearPlayer() {
 		Y: float32(math.Sin(float64(ang))) * spawnRadius,
 	}
 
-	pos := w.Player.Pos.Add(off)
+	w.spawnEnemy(w.chooseEnemyKind(), w.Player.Pos.Add(off))
+	w.rollElite(len(w.Enemies) - 1)
+}
 
-	kind := w.chooseEnemyKind()
+// spawnEnemy adds an enemy of kind at pos, nudged out of obstacles, and
+// returns its ID.
+func (w *World) spawnEnemy(kind EnemyKind, pos Vec2) int {
+	a := w.archetype(kind)
 
 	e := Enemy{
-		ID:   w.nextEnemyID,
-		Pos:  pos,
-		Kind: kind,
+		ID:          w.nextEnemyID,
+		Pos:         pos,
+		Kind:        a.ID,
+		R:           a.Radius,
+		Speed:       a.Speed,
+		MaxHP:       a.HP,
+		HP:          a.HP,
+		TouchDamage: a.TouchDamage,
+		XPValue:     a.XP,
 	}
 	w.nextEnemyID++
 
-	switch kind {
-	case EnemyTank:
-		e.R = cfg.EnemyTankRadius
-		e.Speed = cfg.EnemyTankSpeed
-		e.MaxHP = cfg.EnemyTankHP
-		e.HP = cfg.EnemyTankHP
-		e.TouchDamage = cfg.EnemyTankTouchDamage
-		e.XPValue = cfg.EnemyTankXP
-
-	case EnemyRunner:
-		e.R = cfg.EnemyRunnerRadius
-		e.Speed = cfg.EnemyRunnerSpeed
-		e.MaxHP = cfg.EnemyRunnerHP
-		e.HP = cfg.EnemyRunnerHP
-		e.TouchDamage = cfg.EnemyRunnerTouchDamage
-		e.XPValue = cfg.EnemyRunnerXP
-
-	default: // normal
-		e.R = cfg.EnemyRadius
-		e.Speed = cfg.EnemySpeed
-		e.MaxHP = cfg.EnemyHP
-		e.HP = cfg.EnemyHP
-		e.TouchDamage = cfg.EnemyTouchDamage
-		e.XPValue = cfg.XPPerKill
-	}
 	e.Pos = w.resolveEntityPosition(pos, e.R)
 	w.Enemies = append(w.Enemies, e)
 	w.Stats.EnemiesSpawned++
+	return e.ID
 }
 
 func (w *World) updateDifficulty() {
@@ -125,12 +114,21 @@ func (w *World) updateEnemies(dt float32, intents map[int]enemyMoveIntent) {
 				e.HitT = 0
 			}
 		}
+		held := statusSpeedScale(e.Statuses)
+		if held == 0 || w.bossCharging(e.ID) {
+			continue
+		}
 		speedScale := float32(1)
 		dir, ok := Vec2{}, false
 		if in, has := intents[e.ID]; has {
 			dir = in.Dir
 			speedScale = clamp(in.SpeedScale, 0.2, 1.5)
-			dir = resolveIntentDirection(e.ID, p.Sub(e.Pos), in, dir)
+			toP := p.Sub(e.Pos)
+			if in.Path.X != 0 || in.Path.Y != 0 {
+				// hold range along the path rather than through the blocker
+				toP = in.Path.Mul(toP.Len())
+			}
+			dir = resolveIntentDirection(e.ID, toP, in, dir)
 			ok = true
 		}
 		if !ok {
@@ -144,7 +142,7 @@ func (w *World) updateEnemies(dt float32, intents map[int]enemyMoveIntent) {
 		if dir.X == 0 && dir.Y == 0 {
 			continue
 		}
-		e.Pos = w.resolveEntityPosition(e.Pos.Add(dir.Mul(e.Speed*speedScale*dt)), e.R)
+		e.Pos = w.moveEntity(e.Pos, e.Pos.Add(dir.Mul(e.Speed*speedScale*held*dt)), e.R)
 	}
 }
 
@@ -214,23 +212,36 @@ func modeStrafeGain(mode jobs.IntentMode) float32 {
 // ============================================================================
 
 func (w *World) updateCombat(dt float32) {
+	if statusHeld(w.Player.Statuses) {
+		return
+	}
+	for i := range w.Player.Weapons {
+		w.updateWeaponSlot(i, dt)
+	}
+}
+
+func (w *World) updateWeaponSlot(i int, dt float32) {
+	slot := &w.Player.Weapons[i]
 	// cooldown timer
-	if w.Player.AttackTimer > 0 {
-		w.Player.AttackTimer -= dt
-		if w.Player.AttackTimer > 0 {
+	if slot.Timer > 0 {
+		slot.Timer -= dt
+		if slot.Timer > 0 {
 			return
 		}
 	}
 
-	wd := weaponDef(w.Player.Weapon)
-	attackRange := w.Player.AttackRange * wd.RangeMul
-	damage := w.Player.Damage * wd.DamageMul
-	nextCooldown := maxf(0.08, w.Player.AttackCooldown*wd.CooldownMul)
+	wd := weaponDef(slot.Kind)
+	st := slot.Stats()
+	st.AttackRadius *= w.Player.Area
+	st.Projectiles += w.Player.Projectiles
+	attackRange := w.Player.AttackRange * st.RangeMul
+	damage := w.Player.Damage * st.DamageMul
+	nextCooldown := w.Player.WeaponCooldown(*slot)
 	fired := false
 
 	switch wd.AttackStyle {
-	case AttackPierce:
-		idxs := w.nearestEnemiesInRange(w.Player.Pos, attackRange, 2)
+	case AttackPierce, AttackMulti:
+		idxs := w.nearestEnemiesInRange(w.Player.Pos, attackRange, max(st.Targets, 1))
 		if len(idxs) == 0 {
 			return
 		}
@@ -238,38 +249,145 @@ func (w *World) updateCombat(dt float32) {
 		w.LastAttackPos = w.Enemies[idxs[0]].Pos
 		sortIdxDesc(idxs)
 		for _, idx := range idxs {
-			w.damageEnemyAt(idx, damage)
+			w.strikeEnemyAt(idx, damage, wd.Inflicts)
 		}
-	case AttackRadial:
-		rad := wd.AttackRadius
+	case AttackRadial, AttackVortex:
+		rad := st.AttackRadius
 		if rad <= 0 {
-			rad = attackRange
+			rad = attackRange * w.Player.Area
 		}
-		idxs := w.nearestEnemiesInRange(w.Player.Pos, rad, 64)
+		idxs := w.nearestEnemiesInRange(w.Player.Pos, rad, st.Targets)
 		if len(idxs) == 0 {
 			return
 		}
 		fired = true
 		w.LastAttackPos = w.Player.Pos
 		w.LastAttackRadius = rad
+		if wd.AttackStyle == AttackVortex {
+			w.pullEnemies(idxs, wd.Pull)
+		}
 		sortIdxDesc(idxs)
 		for _, idx := range idxs {
-			w.damageEnemyAt(idx, damage)
+			w.strikeEnemyAt(idx, damage, wd.Inflicts)
+		}
+	case AttackChain:
+		idxs := w.chainTargets(attackRange, st.AttackRadius, st.Targets)
+		if len(idxs) == 0 {
+			return
+		}
+		fired = true
+		w.LastAttackPos = w.Enemies[idxs[0]].Pos
+		sortIdxDesc(idxs)
+		for _, idx := range idxs {
+			w.strikeEnemyAt(idx, damage, wd.Inflicts)
+		}
+	case AttackProjectile, AttackOrbit, AttackBoomerang:
+		if !w.firePlayerProjectiles(slot.Kind, wd, st, attackRange, damage) {
+			return
+		}
+		fired = true
+	case AttackLine:
+		idxs, end := w.lineTargets(attackRange, st.AttackRadius)
+		if len(idxs) == 0 {
+			return
+		}
+		fired = true
+		w.LastAttackPos = end
+		sortIdxDesc(idxs)
+		for _, idx := range idxs {
+			w.strikeEnemyAt(idx, damage, wd.Inflicts)
 		}
 	default:
-		idx := w.nearestEnemyInRange(w.Player.Pos, attackRange)
+		idx := w.nearestVisibleEnemy(w.Player.Pos, attackRange)
 		if idx < 0 {
 			return
 		}
 		fired = true
 		w.LastAttackPos = w.Enemies[idx].Pos
-		w.damageEnemyAt(idx, damage)
+		w.strikeEnemyAt(idx, damage, wd.Inflicts)
 	}
 	if fired {
-		w.Player.AttackTimer = nextCooldown
-		w.LastAttackT = 0.08
-		w.LastAttackWeapon = w.Player.Weapon
+		slot.Timer = nextCooldown
+		// projectiles are drawn themselves, not as an attack flash
+		if !wd.AttackStyle.Projectile() {
+			w.LastAttackT = 0.08
+			w.LastAttackWeapon = slot.Kind
+		}
+	}
+}
+
+// chainTargets starts at the nearest enemy in reach and jumps to the nearest
+// unhit enemy within jump of the previous one, up to n enemies.
+func (w *World) chainTargets(reach, jump float32, n int) []int {
+	first := w.nearestEnemyInRange(w.Player.Pos, reach)
+	if first < 0 {
+		return nil
 	}
+	idxs := []int{first}
+	for len(idxs) < n {
+		from := w.Enemies[idxs[len(idxs)-1]].Pos
+		next := -1
+		for _, idx := range w.nearestEnemiesInRange(from, jump, n+1) {
+			if !slices.Contains(idxs, idx) {
+				next = idx
+				break
+			}
+		}
+		if next < 0 {
+			break
+		}
+		idxs = append(idxs, next)
+	}
+	return idxs
+}
+
+// lineTargets aims at the nearest enemy in reach and returns every enemy
+// touching the segment of length reach and half-width halfW in that
+// direction, in index order, plus the segment's end point.
+func (w *World) lineTargets(reach, halfW float32) ([]int, Vec2) {
+	p := w.Player.Pos
+	aim := w.nearestEnemyInRange(p, reach)
+	if aim < 0 {
+		return nil, p
+	}
+	dir := w.Enemies[aim].Pos.Sub(p).Norm()
+	if dir == (Vec2{}) {
+		dir = Vec2{X: 1}
+	}
+
+	var idxs []int
+	w.ensureEnemyGrid()
+	for _, i := range w.enemyCandidates(p, reach+halfW+w.enemyGrid.maxR) {
+		e := &w.Enemies[i]
+		d := e.Pos.Sub(p)
+		along := d.X*dir.X + d.Y*dir.Y
+		across := absf(d.X*dir.Y - d.Y*dir.X)
+		if along >= -e.R && along <= reach+e.R && across <= halfW+e.R {
+			idxs = append(idxs, i)
+		}
+	}
+	slices.Sort(idxs)
+	return idxs, p.Add(dir.Mul(reach))
+}
+
+// pullEnemies drags enemies up to dist towards the player, stopping at
+// contact and respecting obstacles.
+func (w *World) pullEnemies(idxs []int, dist float32) {
+	if dist <= 0 {
+		return
+	}
+	p := w.Player.Pos
+	for _, i := range idxs {
+		e := &w.Enemies[i]
+		d := p.Sub(e.Pos)
+		step := minf(dist, d.Len()-e.R-w.Player.R)
+		if step <= 0 {
+			continue
+		}
+		e.Pos = w.moveEntity(e.Pos, e.Pos.Add(d.Norm().Mul(step)), e.R)
+	}
+	// positions moved under the grid; rebuild before the next query
+	w.enemyGrid.stale = true
 }
 
 func (w *World) updateContactDamage(dt float32) {
@@ -288,41 +406,57 @@ func (w *World) updateContactDamage(dt float32) {
 	p := w.Player.Pos
 
 	// if touching any enemy, take damage once per HurtCooldown.
-	for i := range w.Enemies {
+	// The lowest touching index wins, matching a plain in-order scan.
+	hit := -1
+	w.ensureEnemyGrid()
+	for _, i := range w.enemyCandidates(p, pr+w.enemyGrid.maxR) {
 		e := &w.Enemies[i]
 		rr := pr + e.R
-		if dist2(p, e.Pos) < rr*rr {
-			w.Player.HP -= e.TouchDamage
-			w.Stats.DamageTaken += e.TouchDamage
-			w.Player.HurtTimer = w.Cfg.PlayerHurtCooldown
-
-			// Knockback
-			dir := w.Player.Pos.Sub(e.Pos).Norm()
-			if dir.X == 0 && dir.Y == 0 {
-				// rare overlap: pick a deterministic-ish random direction
-				ang := w.randFloat32() * 2 * math.Pi
-				dir = Vec2{
-					X: float32(math.Cos(float64(ang))),
-					Y: float32(math.Sin(float64(ang))),
-				}
-			} else {
-				dir = dir.Norm()
-			}
-
-			w.Player.KnockVel = dir.Mul(w.Cfg.PlayerKnockbackSpeed)
-
-			// trigger/refresh shake
-			w.ShakeT = w.Cfg.HitShakeDuration
+		if dist2(p, e.Pos) < rr*rr && (hit < 0 || i < hit) && !hasStatus(e.Statuses, StatusFreeze) {
+			hit = i
+		}
+	}
+	if hit < 0 {
+		return
+	}
 
-			if w.Player.HP <= 0 {
-				w.Player.HP = 0
-				w.GameOver = true
-			}
+	e := &w.Enemies[hit]
+	w.applyPlayerStatuses(w.archetype(e.Kind).OnHit)
+	dealt := w.damagePlayer(w.Player.armored(e.TouchDamage))
+	affixContact(e, dealt)
+	w.Player.HurtTimer = w.Cfg.PlayerHurtCooldown
 
-			return
+	// Knockback
+	dir := w.Player.Pos.Sub(e.Pos).Norm()
+	if dir.X == 0 && dir.Y == 0 {
+		// rare overlap: pick a deterministic-ish random direction
+		ang := w.randFloat32() * 2 * math.Pi
+		dir = Vec2{
+			X: float32(math.Cos(float64(ang))),
+			Y: float32(math.Sin(float64(ang))),
 		}
+	} else {
+		dir = dir.Norm()
 	}
 
+	w.Player.KnockVel = dir.Mul(w.Cfg.PlayerKnockbackSpeed)
+
+	// trigger/refresh shake
+	w.ShakeT = w.Cfg.HitShakeDuration
+}
+
+// damagePlayer subtracts dmg, scaled by vulnerable, and returns what it
+// dealt. At 0 HP a revival is spent or the run ends. Callers own the hurt
+// timer.
+func (w *World) damagePlayer(dmg float32) float32 {
+	dmg *= statusDamageScale(w.Player.Statuses)
+	w.Player.HP -= dmg
+	w.Stats.DamageTaken += dmg
+	if w.Player.HP <= 0 && !w.revive() {
+		w.Player.HP = 0
+		w.GameOver = true
+	}
+	return dmg
 }
 
 func (w *World) updateRunnerRangedShots(dt float32) {
@@ -334,11 +468,11 @@ func (w *World) updateRunnerRangedShots(dt float32) {
 		shotDmg   = float32(5)
 	)
 
-	hasNova := w.Player.Weapon == WeaponNova
+	hasNova := w.Player.HasWeapon(WeaponNova)
 	p := w.Player.Pos
 	for i := range w.Enemies {
 		e := &w.Enemies[i]
-		if e.Kind != EnemyRunner {
+		if !w.archetype(e.Kind).Ranged {
 			continue
 		}
 		if e.ShotTimer > 0 {
@@ -347,7 +481,7 @@ func (w *World) updateRunnerRangedShots(dt float32) {
 				e.ShotTimer = 0
 			}
 		}
-		if !hasNova || e.ShotTimer > 0 {
+		if !hasNova || e.ShotTimer > 0 || statusHeld(e.Statuses) {
 			continue
 		}
 
@@ -358,7 +492,7 @@ func (w *World) updateRunnerRangedShots(dt float32) {
 		}
 
 		dir := toPlayer.Norm()
-		if dir.X == 0 && dir.Y == 0 {
+		if dir.X == 0 && dir.Y == 0 || !w.lineOfSight(e.Pos, p) {
 			continue
 		}
 
@@ -368,6 +502,7 @@ func (w *World) updateRunnerRangedShots(dt float32) {
 			R:      4,
 			Damage: shotDmg,
 			Life:   shotLife,
+			Source: e.Kind,
 		})
 		e.ShotTimer = 1.45
 	}
@@ -377,14 +512,16 @@ func (w *World) updateEnemyProjectiles(dt float32) {
 	p := w.Player.Pos
 	for i := 0; i < len(w.Shots); {
 		s := w.Shots[i]
-		s.Pos = s.Pos.Add(s.Vel.Mul(dt))
+		step := s.Vel.Mul(dt)
+		_, _, blocked := w.sweepObstacles(s.Pos, step, s.R+w.Cfg.ObstaclePadding)
+		s.Pos = s.Pos.Add(step)
 		s.Life -= dt
 
-		if s.Life <= 0 || s.Pos.X < 0 || s.Pos.X > w.W || s.Pos.Y < 0 || s.Pos.Y > w.H {
+		if s.Life <= 0 || w.outOfBounds(s.Pos) {
 			w.removeShotAt(i)
 			continue
 		}
-		if w.overlapsObstacle(s.Pos, s.R) {
+		if blocked || w.overlapsObstacle(s.Pos, s.R) {
 			w.removeShotAt(i)
 			continue
 		}
@@ -392,13 +529,9 @@ func (w *World) updateEnemyProjectiles(dt float32) {
 		rr := w.Player.R + s.R
 		if dist2(p, s.Pos) <= rr*rr {
 			if w.Player.HurtTimer <= 0 {
-				w.Player.HP -= s.Damage
-				w.Stats.DamageTaken += s.Damage
+				w.applyPlayerStatuses(w.archetype(s.Source).OnHit)
+				w.damagePlayer(w.Player.armored(s.Damage))
 				w.Player.HurtTimer = w.Cfg.PlayerHurtCooldown
-				if w.Player.HP <= 0 {
-					w.Player.HP = 0
-					w.GameOver = true
-				}
 			}
 			w.removeShotAt(i)
 			continue
@@ -419,7 +552,7 @@ func (w *World) updateKnockback(dt float32) {
 		return
 	}
 	// integrate
-	w.Player.Pos = w.resolveEntityPosition(w.Player.Pos.Add(kv.Mul(dt)), w.Player.R)
+	w.Player.Pos = w.moveEntity(w.Player.Pos, w.Player.Pos.Add(kv.Mul(dt)), w.Player.R)
 
 	// damping (euler integration)
 	d := w.Cfg.PlayerKnockbackDamping
@@ -478,27 +611,131 @@ func (w *World) spawnXPOrb(pos Vec2, value float32) {
 	})
 }
 
+// updateXPOrbs pulls orbs inside the magnet radius towards the player,
+// collects the ones that reach it and merges resting orbs past the cap.
 func (w *World) updateXPOrbs(dt float32) {
-	_ = dt // reserved for future motion/magnetism
+	if w.Cfg.XPOrbAccel <= 0 {
+		w.collectXPOrbsInstantly()
+		w.mergeXPOrbs()
+		return
+	}
 
 	p := w.Player.Pos
-	pickupR := w.Player.R + w.Cfg.XPPickupPadding + w.Player.XPMagnet
-	// pickup padding
+	magnetR := w.pickupRadius()
+	collectR := w.Player.R + w.Cfg.XPPickupPadding
+	var picked []int
+	for i := range w.Orbs {
+		o := &w.Orbs[i]
+		if o.Speed == 0 {
+			rr := magnetR + o.R
+			if dist2(p, o.Pos) > rr*rr {
+				continue
+			}
+			o.Speed = w.Cfg.XPOrbPullSpeed
+		}
+		o.Speed = minf(o.Speed+w.Cfg.XPOrbAccel*dt, max(w.Cfg.XPOrbMaxSpeed, w.Cfg.XPOrbPullSpeed))
+		d := p.Sub(o.Pos)
+		if dist := d.Len(); dist <= o.Speed*dt {
+			o.Pos = p
+		} else {
+			o.Pos = o.Pos.Add(d.Mul(o.Speed * dt / dist))
+		}
+		rr := collectR + o.R
+		if dist2(p, o.Pos) <= rr*rr {
+			picked = append(picked, i)
+		}
+	}
+
+	// indices are ascending, so removing from the back never moves a picked orb
+	for k := len(picked) - 1; k >= 0; k-- {
+		w.collectOrbAt(picked[k])
+	}
+	w.mergeXPOrbs()
+}
+
+// collectXPOrbsInstantly is the pickup for configs without orb pull: every
+// orb inside the magnet radius is collected on the spot.
+func (w *World) collectXPOrbsInstantly() {
+	p := w.Player.Pos
+	pickupR := w.pickupRadius()
 
-	for i := 0; i < len(w.Orbs); {
+	w.rebuildOrbGrid()
+	w.queryBuf = w.orbGrid.queryCircle(p, pickupR+w.orbGrid.maxR, w.queryBuf[:0])
+	picked := w.queryBuf[:0]
+	for _, i := range w.queryBuf {
 		o := w.Orbs[i]
 		rr := pickupR + o.R
-
 		if dist2(p, o.Pos) <= rr*rr {
-			w.Player.XP += o.Value
-			w.Stats.XPCollected += o.Value
-			w.removeOrbAt(i)
-			continue
+			picked = append(picked, i)
 		}
-		i++
+	}
+
+	// remove highest index first so swap-removes never move a picked orb
+	sortIdxDesc(picked)
+	for _, i := range picked {
+		w.collectOrbAt(i)
 	}
 }
 
+func (w *World) collectOrbAt(i int) {
+	o := w.Orbs[i]
+	w.Player.XP += o.Value
+	w.Stats.XPCollected += o.Value
+	w.removeOrbAt(i)
+}
+
+// mergeXPOrbs runs once the orb count passes XPOrbCap. Each resting orb, in
+// slice order, absorbs the resting orbs within XPOrbMergeRadius at its
+// value-weighted centre. Whatever still exceeds the cap folds into the
+// oldest orb, so the count stays bounded even when orbs are spread out.
+func (w *World) mergeXPOrbs() {
+	limit := w.Cfg.XPOrbCap
+	if limit <= 0 || len(w.Orbs) <= limit {
+		return
+	}
+	w.rebuildOrbGrid()
+
+	if mr := w.Cfg.XPOrbMergeRadius; mr > 0 {
+		merged := make([]bool, len(w.Orbs))
+		var cands []int
+		for i := range w.Orbs {
+			a := &w.Orbs[i]
+			if merged[i] || a.Speed > 0 {
+				continue
+			}
+			cands = w.orbGrid.queryCircle(a.Pos, mr+w.orbGrid.maxR, cands[:0])
+			slices.Sort(cands)
+			for _, j := range cands {
+				b := w.Orbs[j]
+				if j <= i || merged[j] || b.Speed > 0 || dist2(a.Pos, b.Pos) > mr*mr {
+					continue
+				}
+				total := a.Value + b.Value
+				if total > 0 {
+					a.Pos = a.Pos.Mul(a.Value / total).Add(b.Pos.Mul(b.Value / total))
+				}
+				a.Value = total
+				merged[j] = true
+			}
+		}
+		kept := w.Orbs[:0]
+		for i, o := range w.Orbs {
+			if !merged[i] {
+				kept = append(kept, o)
+			}
+		}
+		w.Orbs = kept
+	}
+
+	if len(w.Orbs) > limit {
+		for _, o := range w.Orbs[limit:] {
+			w.Orbs[0].Value += o.Value
+		}
+		w.Orbs = w.Orbs[:limit]
+	}
+	w.rebuildOrbGrid()
+}
+
 func (w *World) updateWeaponDrops() {
 	p := w.Player.Pos
 	pickupR := w.Player.R + 14
@@ -506,8 +743,7 @@ func (w *World) updateWeaponDrops() {
 	for i := 0; i < len(w.Drops); {
 		d := w.Drops[i]
 		rr := pickupR + d.R
-		if dist2(p, d.Pos) <= rr*rr {
-			w.Player.Weapon = d.Kind
+		if dist2(p, d.Pos) <= rr*rr && w.pickupWeapon(d.Kind) {
 			w.removeDropAt(i)
 			continue
 		}
@@ -533,7 +769,8 @@ func (w *World) updateLevelUp() {
 		leveled = true
 
 		// v0.1 simple reward: small heal on level up
-		w.Player.MaxHP = minf(w.Cfg.PlayerMaxHPCap, w.Player.MaxHP+w.Cfg.PlayerLevelUpHeal)
+		w.Player.Base.MaxHP = minf(w.Cfg.PlayerMaxHPCap, w.Player.Base.MaxHP+w.Cfg.PlayerLevelUpHeal)
+		w.Player.refreshStats()
 		w.Player.HP = minf(w.Player.MaxHP, w.Player.HP+w.Cfg.PlayerLevelUpHeal)
 	}
 
@@ -548,23 +785,28 @@ func (w *World) updateLevelUp() {
 
 func (w *World) chooseEnemyKind() EnemyKind {
 	n := w.Stats.EnemiesSpawned + 1
-	if w.Wave.GuaranteedTankAt > 0 && n%w.Wave.GuaranteedTankAt == 0 {
-		return EnemyTank
+	for _, g := range w.Wave.Guarantees {
+		if g.Every > 0 && n%g.Every == 0 {
+			return g.Kind
+		}
 	}
 
-	total := w.Wave.NormalWeight + w.Wave.RunnerWeight + w.Wave.TankWeight
+	total := 0
+	for _, s := range w.Wave.Spawns {
+		total += s.Weight
+	}
 	if total <= 0 {
-		return EnemyNormal
+		return w.Cfg.DefaultEnemy
 	}
 
 	roll := positiveModInt(n*5+w.Wave.Index*7+positiveModInt(int(w.rngSeed), total), total)
-	if roll < w.Wave.TankWeight {
-		return EnemyTank
-	}
-	if roll < w.Wave.TankWeight+w.Wave.RunnerWeight {
-		return EnemyRunner
+	for _, s := range w.Wave.Spawns {
+		if roll < s.Weight {
+			return s.Kind
+		}
+		roll -= s.Weight
 	}
-	return EnemyNormal
+	return w.Cfg.DefaultEnemy
 }
 
 func (w *World) nearestEnemyInRange(p Vec2, rng float32) int {
@@ -576,7 +818,7 @@ func (w *World) nearestEnemyInRange(p Vec2, rng float32) int {
 	best := -1
 	bestD2 := float32(0)
 
-	for i := range w.Enemies {
+	for _, i := range w.enemyCandidates(p, rng) {
 		d := w.Enemies[i].Pos.Sub(p)
 		d2 := d.X*d.X + d.Y*d.Y
 
@@ -584,7 +826,8 @@ func (w *World) nearestEnemyInRange(p Vec2, rng float32) int {
 			continue
 		}
 
-		if best == -1 || d2 < bestD2 {
+		// ties go to the lower index so the result does not depend on cell order
+		if best == -1 || d2 < bestD2 || (d2 == bestD2 && i < best) {
 			best = i
 			bestD2 = d2
 		}
@@ -593,8 +836,24 @@ func (w *World) nearestEnemyInRange(p Vec2, rng float32) int {
 	return best
 }
 
+// nearestVisibleEnemy is nearestEnemyInRange skipping enemies that a wall or
+// obstacle hides from p.
+func (w *World) nearestVisibleEnemy(p Vec2, rng float32) int {
+	idx := w.nearestEnemyInRange(p, rng)
+	if idx < 0 || w.lineOfSight(p, w.Enemies[idx].Pos) {
+		return idx
+	}
+	for _, i := range w.nearestEnemiesInRange(p, rng, len(w.Enemies)) {
+		if w.lineOfSight(p, w.Enemies[i].Pos) {
+			return i
+		}
+	}
+	return -1
+}
+
 func (w *World) removeEnemyAt(idx int) {
 	last := len(w.Enemies) - 1
+	w.enemyGrid.removeSwap(idx, last)
 
 	if idx != last {
 		w.Enemies[idx] = w.Enemies[last]
@@ -605,6 +864,7 @@ func (w *World) removeEnemyAt(idx int) {
 
 func (w *World) removeOrbAt(i int) {
 	last := len(w.Orbs) - 1
+	w.orbGrid.removeSwap(i, last)
 	if i != last {
 		w.Orbs[i] = w.Orbs[last]
 	}
@@ -627,8 +887,13 @@ func (w *World) removeShotAt(i int) {
 	w.Shots = w.Shots[:last]
 }
 
-func (w *World) maybeSpawnWeaponDrop(pos Vec2, kind EnemyKind) {
-	if w.randFloat32() > weaponDropChance(kind) {
+func (w *World) maybeSpawnWeaponDrop(pos Vec2, kind EnemyKind, elite bool) {
+	chance := w.archetype(kind).DropChance
+	if elite {
+		chance += w.Cfg.Elites.DropBonus
+	}
+	chance *= w.Player.Luck
+	if w.randFloat32() > chance {
 		return
 	}
 
@@ -651,8 +916,8 @@ func (w *World) nearestEnemiesInRange(p Vec2, rng float32, limit int) []int {
 		d2  float32
 	}
 	r2 := rng * rng
-	cands := make([]cand, 0, len(w.Enemies))
-	for i := range w.Enemies {
+	var cands []cand
+	for _, i := range w.enemyCandidates(p, rng) {
 		d := w.Enemies[i].Pos.Sub(p)
 		d2 := d.X*d.X + d.Y*d.Y
 		if d2 <= r2 {
@@ -663,15 +928,12 @@ func (w *World) nearestEnemiesInRange(p Vec2, rng float32, limit int) []int {
 		return nil
 	}
 
-	for i := 0; i < len(cands)-1; i++ {
-		best := i
-		for j := i + 1; j < len(cands); j++ {
-			if cands[j].d2 < cands[best].d2 {
-				best = j
-			}
+	slices.SortFunc(cands, func(a, b cand) int {
+		if a.d2 != b.d2 {
+			return cmp.Compare(a.d2, b.d2)
 		}
-		cands[i], cands[best] = cands[best], cands[i]
-	}
+		return a.idx - b.idx
+	})
 
 	if len(cands) > limit {
 		cands = cands[:limit]
@@ -689,7 +951,7 @@ func (w *World) damageEnemyAt(idx int, dmg float32) {
 		return
 	}
 	e := &w.Enemies[idx]
-	e.HP -= dmg
+	e.HP -= affixDamage(e, dmg*statusDamageScale(e.Statuses))
 	e.HitT = 1.10 // flash duration
 	if e.HP > 0 {
 		return
@@ -698,9 +960,18 @@ func (w *World) damageEnemyAt(idx int, dmg float32) {
 	deathPos := e.Pos
 	xp := e.XPValue
 	kind := e.Kind
+	affixes := e.Affixes
+	boss := w.Boss.Active && e.ID == w.Boss.EnemyID
 	w.removeEnemyAt(idx)
 	w.spawnXPOrb(deathPos, xp)
-	w.maybeSpawnWeaponDrop(deathPos, kind)
+	w.maybeSpawnWeaponDrop(deathPos, kind, len(affixes) > 0)
+	w.dropPickups(deathPos, len(affixes) > 0, boss)
+	if boss {
+		w.defeatBoss(deathPos)
+	}
+	if len(affixes) > 0 {
+		w.affixDeath(kind, deathPos, affixes)
+	}
 	w.Stats.EnemiesKilled++
 }
 
@@ -0,0 +1,132 @@
+package world
+
+import (
+	"runtime"
+
+	"horde-lab/internal/jobs"
+)
+
+// worldJobs are the world's job pools. Simulation jobs share one set of
+// workers; saves get a single worker of their own, which writes them in
+// order without holding up intents or flow fields.
+type worldJobs struct {
+	workers *jobs.Workers
+	writer  *jobs.Workers
+	intents *jobs.Pool[jobs.IntentRequest, jobs.IntentResult]
+	flows   *jobs.Pool[jobs.FlowFieldRequest, *jobs.FlowField]
+	waves   *jobs.Pool[waveRequest, WaveState]
+	saves   *jobs.Pool[saveRequest, error]
+}
+
+func newWorldJobs() *worldJobs {
+	count := runtime.NumCPU() / 2
+	if count < 1 {
+		count = 1
+	}
+	if count > 4 {
+		count = 4
+	}
+
+	workers := jobs.NewWorkers(count, 16)
+	writer := jobs.NewWorkers(1, 16)
+	return &worldJobs{
+		workers: workers,
+		writer:  writer,
+		intents: jobs.NewPool(workers, jobs.KindIntents, 16, jobs.ComputeIntents),
+		flows:   jobs.NewPool(workers, jobs.KindFlowField, 0, jobs.WarmFlowField),
+		waves:   jobs.NewPool(workers, jobs.KindWave, 4, waveRequest.build),
+		saves:   jobs.NewPool(writer, jobs.KindSave, 0, saveRequest.write),
+	}
+}
+
+// ensureJobs starts the pools and result trackers a zero World lacks.
+func (w *World) ensureJobs() {
+	if w.pool == nil {
+		w.pool = newWorldJobs()
+	}
+	if w.aiIntents == nil {
+		w.aiIntents = jobs.NewPending(jobs.ComputeIntents)
+	}
+	if w.waves == nil {
+		w.waves = jobs.NewPending(waveRequest.build)
+	}
+}
+
+// Close finishes queued saves and stops the workers.
+func (j *worldJobs) Close() {
+	j.workers.Close()
+	j.writer.Close()
+}
+
+// JobStats reports what each job pool did with its work, including results
+// the world computed itself because they were late.
+func (w *World) JobStats() []jobs.PoolStats {
+	if w.pool == nil {
+		return nil
+	}
+	intents := w.pool.intents.Stats()
+	intents.Late = w.aiIntents.Late()
+	waves := w.pool.waves.Stats()
+	waves.Late = w.waves.Late()
+	return []jobs.PoolStats{intents, w.pool.flows.Stats(), waves, w.pool.saves.Stats()}
+}
+
+// waveRequest builds a wave's state ahead of the wave; results are tagged
+// with the wave index.
+type waveRequest struct {
+	cfg   Config
+	index int
+	seed  int64
+}
+
+func (r waveRequest) build() WaveState {
+	return buildWaveState(r.cfg, r.index, r.seed)
+}
+
+func (w *World) prefetchWave(index int) {
+	req := waveRequest{cfg: w.Cfg, index: index, seed: w.rngSeed}
+	w.waves.Track(uint64(index), req)
+	if w.pool != nil {
+		w.pool.waves.Submit(jobs.Job[waveRequest]{Tick: uint64(index), Priority: jobs.PriorityLow, Req: req})
+	}
+}
+
+// saveRequest writes a snapshot taken on the world's goroutine.
+type saveRequest struct {
+	path  string
+	snap  Snapshot
+	reply chan<- error
+	done  chan struct{}
+}
+
+func (r saveRequest) write() error {
+	defer close(r.done)
+	err := writeSnapshotFile(r.path, r.snap)
+	if r.reply != nil {
+		select {
+		case r.reply <- err:
+		default:
+		}
+	}
+	return err
+}
+
+// saveSnapshotAsync snapshots the world now and writes it on the pool. When
+// the pool cannot take it, queued saves finish first and it is written here.
+func (w *World) saveSnapshotAsync(path string, reply chan<- error) {
+	req := saveRequest{path: path, snap: w.BuildSnapshot(), reply: reply, done: make(chan struct{})}
+	if w.pool != nil && w.pool.saves.Submit(jobs.Job[saveRequest]{Priority: jobs.PriorityLow, Req: req}) {
+		w.lastSave = req.done
+		return
+	}
+	w.awaitSaves()
+	req.write()
+}
+
+// awaitSaves blocks until every queued save is on disk. The save worker
+// writes in submission order, so the last one finishing means all have.
+func (w *World) awaitSaves() {
+	if w.lastSave != nil {
+		<-w.lastSave
+	}
+}
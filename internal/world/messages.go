@@ -5,11 +5,29 @@ type Msg interface{ isMsg() }
 func (MsgInput) isMsg() {}
 
 type MsgChooseUpgrade struct {
-	Choice int // 0 or 1
+	Choice int // index into UpgradeMenu.Options
 }
 
 func (MsgChooseUpgrade) isMsg() {}
 
+// MsgRerollUpgrades spends a reroll charge on a fresh level-up offer.
+type MsgRerollUpgrades struct{}
+
+func (MsgRerollUpgrades) isMsg() {}
+
+// MsgSkipUpgrade spends a skip charge to pass on the current level-up offer.
+type MsgSkipUpgrade struct{}
+
+func (MsgSkipUpgrade) isMsg() {}
+
+// MsgBanishUpgrade spends a banish charge to drop an option from every later
+// offer.
+type MsgBanishUpgrade struct {
+	Choice int // index into UpgradeMenu.Options
+}
+
+func (MsgBanishUpgrade) isMsg() {}
+
 type MsgRestart struct{}
 
 func (MsgRestart) isMsg() {}
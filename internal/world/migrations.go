@@ -0,0 +1,389 @@
+package world
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"math"
+
+	"horde-lab/internal/shared/migrate"
+)
+
+// SnapshotMigrations upgrades snapshot documents to SnapshotVersion. Other
+// packages nest it when they embed a snapshot (savegames, replays).
+//
+// Steps never read live config or content: a step that fills in defaults
+// uses literals or a file under content/migrations frozen at its version,
+// so a save migrates the same way whatever the game ships today.
+var SnapshotMigrations = migrate.New("snapshot", SnapshotVersion, "version").
+	Register(1, migrateSnapshotV1).
+	Register(2, migrateSnapshotV2).
+	Register(3, migrateSnapshotV3).
+	Register(4, migrateSnapshotV4).
+	Register(5, migrateSnapshotV5).
+	Register(6, migrateSnapshotV6).
+	Register(7, migrateSnapshotV7).
+	Register(8, migrateSnapshotV8).
+	Register(9, migrateSnapshotV9)
+
+// replayMigrations upgrades replay files; embedded snapshots (initial and
+// keyframes) are migrated independently of the replay header version.
+var replayMigrations = migrate.New("replay", ReplayVersion, "header", "version").
+	Nest("initial", SnapshotMigrations).
+	NestPath(SnapshotMigrations, "keyframes", "snapshot")
+
+// migrateSnapshotV1 adds stored RNG state. v1 restored math/rand by replaying
+// rng_calls draws; that stream cannot be reproduced by PCG32, so derive the
+// state a v2 run with the same seed and draw count would have.
+func migrateSnapshotV1(doc migrate.Doc) error {
+	seed, err := migrate.Int64(doc, "rng_seed")
+	if err != nil {
+		return err
+	}
+	calls, err := migrate.Uint64(doc, "rng_calls")
+	if err != nil {
+		return err
+	}
+	if seed == 0 {
+		seed = 1
+	}
+
+	rng := newRNGState(seed)
+	for range calls {
+		rng.next()
+	}
+	doc["rng"] = migrate.Doc{
+		"state": migrate.Uint64Number(rng.State),
+		"inc":   migrate.Uint64Number(rng.Inc),
+	}
+	return nil
+}
+
+// Content the steps fill in, frozen as it shipped with the version each
+// step upgrades to.
+var (
+	//go:embed content/migrations/snapshot_v2_enemies.json
+	snapshotV2Enemies []byte
+	//go:embed content/migrations/snapshot_v6_pickups.json
+	snapshotV6Pickups []byte
+)
+
+// v2EnemyStats lists the per-kind config keys v2 stored, in wave roll order.
+// v2 enemy kinds were ints indexing v2EnemyKinds.
+var (
+	v2EnemyStats = []struct {
+		kind EnemyKind
+		keys [5]string
+	}{
+		{EnemyTank, [5]string{"EnemyTankRadius", "EnemyTankSpeed", "EnemyTankHP", "EnemyTankTouchDamage", "EnemyTankXP"}},
+		{EnemyRunner, [5]string{"EnemyRunnerRadius", "EnemyRunnerSpeed", "EnemyRunnerHP", "EnemyRunnerTouchDamage", "EnemyRunnerXP"}},
+		{EnemyNormal, [5]string{"EnemyRadius", "EnemySpeed", "EnemyHP", "EnemyTouchDamage", "XPPerKill"}},
+	}
+	v2ArchetypeKeys = [5]string{"radius", "speed", "hp", "touch_damage", "xp"}
+	v2EnemyKinds    = []EnemyKind{EnemyNormal, EnemyRunner, EnemyTank}
+)
+
+// migrateSnapshotV2 moves enemy stats from fixed config fields into
+// archetypes and turns int enemy kinds into archetype IDs. Fields v2 had no
+// equivalent for (drawing, spawn curves) come from the v3 content.
+func migrateSnapshotV2(doc migrate.Doc) error {
+	if cfg, ok := doc["cfg"].(migrate.Doc); ok {
+		content, err := migrate.Decode(snapshotV2Enemies)
+		if err != nil {
+			return err
+		}
+		defaults, _ := content["enemies"].([]any)
+		byID := make(map[EnemyKind]migrate.Doc, len(defaults))
+		for _, raw := range defaults {
+			if a, ok := raw.(migrate.Doc); ok {
+				id, _ := a["id"].(string)
+				byID[EnemyKind(id)] = a
+			}
+		}
+
+		enemies := make([]any, 0, len(v2EnemyStats))
+		for _, st := range v2EnemyStats {
+			a, ok := byID[st.kind]
+			if !ok {
+				return fmt.Errorf("v3 content has no %q archetype", st.kind)
+			}
+			for i, key := range st.keys {
+				if v, ok := cfg[key]; ok {
+					a[v2ArchetypeKeys[i]] = v
+				}
+				delete(cfg, key)
+			}
+			enemies = append(enemies, a)
+		}
+		cfg["Enemies"] = enemies
+		cfg["DefaultEnemy"] = string(EnemyNormal)
+	}
+
+	if enemies, ok := doc["enemies"].([]any); ok {
+		for i, raw := range enemies {
+			e, ok := raw.(migrate.Doc)
+			if !ok {
+				return fmt.Errorf("enemies[%d] is not an object", i)
+			}
+			kind, err := migrate.Int64(e, "Kind")
+			if err != nil {
+				return fmt.Errorf("enemies[%d]: %w", i, err)
+			}
+			if kind < 0 || kind >= int64(len(v2EnemyKinds)) {
+				return fmt.Errorf("enemies[%d]: unknown v2 enemy kind %d", i, kind)
+			}
+			e["Kind"] = string(v2EnemyKinds[kind])
+		}
+	}
+
+	if wave, ok := doc["wave"].(migrate.Doc); ok {
+		var spawns []any
+		for _, f := range []struct {
+			kind EnemyKind
+			key  string
+		}{{EnemyTank, "tank_weight"}, {EnemyRunner, "runner_weight"}, {EnemyNormal, "normal_weight"}} {
+			weight, err := migrate.Int64(wave, f.key)
+			if err != nil {
+				return err
+			}
+			delete(wave, f.key)
+			if weight > 0 {
+				spawns = append(spawns, migrate.Doc{"kind": string(f.kind), "weight": weight})
+			}
+		}
+		wave["spawns"] = spawns
+
+		every, err := migrate.Int64(wave, "guaranteed_tank_at")
+		if err != nil {
+			return err
+		}
+		delete(wave, "guaranteed_tank_at")
+		if every > 0 {
+			wave["guarantees"] = []any{migrate.Doc{"kind": string(EnemyTank), "every": every}}
+		}
+	}
+	return nil
+}
+
+// migrateSnapshotV3 moves the single player weapon and its attack timer into
+// the first inventory slot.
+func migrateSnapshotV3(doc migrate.Doc) error {
+	if cfg, ok := doc["cfg"].(migrate.Doc); ok {
+		cfg["WeaponSlots"] = migrate.Uint64Number(4)
+	}
+	player, ok := doc["player"].(migrate.Doc)
+	if !ok {
+		return nil
+	}
+	kind, err := migrate.Int64(player, "Weapon")
+	if err != nil {
+		return err
+	}
+	slot := migrate.Doc{"Kind": kind, "Level": 1, "Timer": 0}
+	if timer, ok := player["AttackTimer"]; ok {
+		slot["Timer"] = timer
+	}
+	player["Weapons"] = []any{slot}
+	delete(player, "Weapon")
+	delete(player, "AttackTimer")
+	return nil
+}
+
+// migrateSnapshotV4 derives passive levels from the stats the matching
+// upgrades raised, so evolutions unlock for runs saved before passives.
+func migrateSnapshotV4(doc migrate.Doc) error {
+	player, ok := doc["player"].(migrate.Doc)
+	if !ok {
+		return nil
+	}
+	cfg, _ := doc["cfg"].(migrate.Doc)
+	read := func(d migrate.Doc, key string, fallback float64) (float64, error) {
+		if _, ok := d[key]; !ok {
+			return fallback, nil
+		}
+		return migrate.Float64(d, key)
+	}
+
+	baseDamage, err := read(cfg, "PlayerDamage", 25)
+	if err != nil {
+		return err
+	}
+	baseCooldown, err := read(cfg, "PlayerAttackCooldown", 0.45)
+	if err != nil {
+		return err
+	}
+	damage, err := read(player, "Damage", baseDamage)
+	if err != nil {
+		return err
+	}
+	cooldown, err := read(player, "AttackCooldown", baseCooldown)
+	if err != nil {
+		return err
+	}
+	magnet, err := read(player, "XPMagnet", 10)
+	if err != nil {
+		return err
+	}
+
+	levels := []struct {
+		kind  PassiveKind
+		level float64
+	}{
+		{PassiveMight, (damage - baseDamage) / 10},
+		{PassiveHaste, math.Log(cooldown/baseCooldown) / math.Log(0.85)},
+		{PassiveMagnet, (magnet - 10) / 15},
+	}
+	var passives []any
+	for _, l := range levels {
+		if math.IsNaN(l.level) || math.IsInf(l.level, 0) {
+			continue
+		}
+		if n := int(math.Round(l.level)); n > 0 {
+			passives = append(passives, migrate.Doc{
+				"Kind":  migrate.Uint64Number(uint64(l.kind)),
+				"Level": migrate.Uint64Number(uint64(n)),
+			})
+		}
+	}
+	player["Passives"] = passives
+	return nil
+}
+
+// migrateSnapshotV5 adds the player's base stats. v5 stored only the
+// upgraded values, so the base is what remains after taking the passives'
+// effects back out; recomputing from it reproduces the saved stats.
+func migrateSnapshotV5(doc migrate.Doc) error {
+	player, ok := doc["player"].(migrate.Doc)
+	if !ok {
+		return nil
+	}
+	stat := func(key string) (float64, error) {
+		if _, ok := player[key]; !ok {
+			return 0, nil
+		}
+		return migrate.Float64(player, key)
+	}
+	levels := map[PassiveKind]float64{}
+	if passives, ok := player["Passives"].([]any); ok {
+		for i, raw := range passives {
+			p, ok := raw.(migrate.Doc)
+			if !ok {
+				return fmt.Errorf("passives[%d] is not an object", i)
+			}
+			kind, err := migrate.Int64(p, "Kind")
+			if err != nil {
+				return err
+			}
+			level, err := migrate.Float64(p, "Level")
+			if err != nil {
+				return err
+			}
+			levels[PassiveKind(kind)] = level
+		}
+	}
+
+	base := migrate.Doc{"Area": 1, "Luck": 1}
+	for _, f := range []struct {
+		key  string
+		undo func(v float64) float64
+	}{
+		{"MaxHP", nil},
+		{"Speed", nil},
+		{"Damage", func(v float64) float64 { return v - 10*levels[PassiveMight] }},
+		{"AttackCooldown", func(v float64) float64 { return v / math.Pow(0.85, levels[PassiveHaste]) }},
+		{"AttackRange", nil},
+		{"XPMagnet", func(v float64) float64 { return v - 15*levels[PassiveMagnet] }},
+	} {
+		v, err := stat(f.key)
+		if err != nil {
+			return err
+		}
+		if f.undo != nil {
+			v = f.undo(v)
+		}
+		base[f.key] = v
+	}
+	player["Base"] = base
+	player["Area"] = 1
+	player["Luck"] = 1
+	return nil
+}
+
+// migrateSnapshotV6 adds the v7 pickup content. Runs saved before the
+// level-up menu had its config also get the default offer size and a full
+// set of reroll, skip and banish charges.
+func migrateSnapshotV6(doc migrate.Doc) error {
+	cfg, ok := doc["cfg"].(migrate.Doc)
+	if !ok {
+		return nil
+	}
+	pickups, err := migrate.Decode(snapshotV6Pickups)
+	if err != nil {
+		return err
+	}
+	cfg["Pickups"] = pickups["pickups"]
+
+	menu, _ := doc["upgrade"].(migrate.Doc)
+	for _, f := range []struct {
+		key, charges string
+		value        int
+	}{
+		{"UpgradeChoices", "", 3},
+		{"UpgradeRerolls", "Rerolls", 2},
+		{"UpgradeSkips", "Skips", 2},
+		{"UpgradeBanishes", "Banishes", 2},
+	} {
+		if _, ok := cfg[f.key]; ok {
+			continue
+		}
+		cfg[f.key] = migrate.Uint64Number(uint64(f.value))
+		if menu != nil && f.charges != "" {
+			menu[f.charges] = migrate.Uint64Number(uint64(f.value))
+		}
+	}
+	return nil
+}
+
+// migrateSnapshotV7 keeps runs saved before tile terrain on a flat arena;
+// generating terrain under them would move walls into live enemies.
+func migrateSnapshotV7(doc migrate.Doc) error {
+	if cfg, ok := doc["cfg"].(migrate.Doc); ok {
+		if _, ok := cfg["TerrainTileSize"]; !ok {
+			cfg["TerrainTileSize"] = migrate.Uint64Number(0)
+		}
+	}
+	return nil
+}
+
+// migrateSnapshotV8 keeps runs saved before chunk streaming in their bounded
+// arena.
+func migrateSnapshotV8(doc migrate.Doc) error {
+	if cfg, ok := doc["cfg"].(migrate.Doc); ok {
+		if _, ok := cfg["ChunkSize"]; !ok {
+			cfg["ChunkSize"] = migrate.Uint64Number(0)
+		}
+	}
+	return nil
+}
+
+// migrateSnapshotV9 keeps enemies in runs saved before flow-field pathing
+// steering straight at the player.
+func migrateSnapshotV9(doc migrate.Doc) error {
+	if cfg, ok := doc["cfg"].(migrate.Doc); ok {
+		if _, ok := cfg["FlowCellSize"]; !ok {
+			cfg["FlowCellSize"] = migrate.Uint64Number(0)
+		}
+	}
+	return nil
+}
+
+func decodeSnapshotJSON(blob []byte, s *Snapshot) error {
+	upgraded, err := SnapshotMigrations.MigrateJSON(blob)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(upgraded, s); err != nil {
+		return fmt.Errorf("decode snapshot: %w", err)
+	}
+	return nil
+}
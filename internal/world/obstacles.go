@@ -0,0 +1,328 @@
+package world
+
+import "math"
+
+// ObstacleShape selects how an Obstacle's extent is read.
+type ObstacleShape uint8
+
+const (
+	ShapeCircle  ObstacleShape = iota // radius R
+	ShapeRect                         // axis-aligned, Half extents
+	ShapePolygon                      // convex, Points in increasing angle order
+)
+
+// sweepSkin is how far a swept mover stops short of the surface it hits, so
+// the next sweep does not start touching it.
+const sweepSkin = 0.01
+
+// numVertices is the vertex count of a rect or polygon; circles have none.
+func (o *Obstacle) numVertices() int {
+	switch o.Shape {
+	case ShapeRect:
+		return 4
+	case ShapePolygon:
+		return len(o.Points)
+	}
+	return 0
+}
+
+// vertex returns the i-th corner in world space.
+func (o *Obstacle) vertex(i int) Vec2 {
+	if o.Shape == ShapeRect {
+		sx, sy := float32(1), float32(1)
+		if i == 0 || i == 3 {
+			sx = -1
+		}
+		if i < 2 {
+			sy = -1
+		}
+		return Vec2{X: o.Pos.X + sx*o.Half.X, Y: o.Pos.Y + sy*o.Half.Y}
+	}
+	return o.Pos.Add(o.Points[i])
+}
+
+// Vertices lists a rect's or polygon's corners in world space; circles have
+// none.
+func (o *Obstacle) Vertices() []Vec2 {
+	n := o.numVertices()
+	if n == 0 {
+		return nil
+	}
+	out := make([]Vec2, n)
+	for i := range out {
+		out[i] = o.vertex(i)
+	}
+	return out
+}
+
+// edgeNormal is the outward unit normal of the edge from vertex i to i+1.
+func (o *Obstacle) edgeNormal(i int) Vec2 {
+	d := o.vertex((i + 1) % o.numVertices()).Sub(o.vertex(i))
+	return Vec2{X: d.Y, Y: -d.X}.Norm()
+}
+
+// Distance is the signed distance from p to the obstacle's surface
+// (negative inside) and the outward direction that leaves it fastest.
+func (o *Obstacle) Distance(p Vec2) (float32, Vec2) {
+	n := o.numVertices()
+	if n < 3 {
+		delta := p.Sub(o.Pos)
+		l := delta.Len()
+		if l == 0 {
+			return -o.R, Vec2{X: 1}
+		}
+		return l - o.R, delta.Mul(1 / l)
+	}
+
+	// inside a convex shape the nearest edge is the one p is least behind
+	maxSep, maxN := float32(-math.MaxFloat32), Vec2{}
+	for i := range n {
+		nrm := o.edgeNormal(i)
+		if sep := p.Sub(o.vertex(i)).Dot(nrm); sep > maxSep {
+			maxSep, maxN = sep, nrm
+		}
+	}
+	if maxSep <= 0 {
+		return maxSep, maxN
+	}
+
+	best := float32(math.MaxFloat32)
+	var bestQ Vec2
+	for i := range n {
+		q := closestOnSegment(p, o.vertex(i), o.vertex((i+1)%n))
+		if d := dist2(p, q); d < best {
+			best, bestQ = d, q
+		}
+	}
+	d := float32(math.Sqrt(float64(best)))
+	if d == 0 {
+		return 0, maxN
+	}
+	return d, p.Sub(bestQ).Mul(1 / d)
+}
+
+// pushOut moves a circle of radius r clear of the obstacle.
+func (o *Obstacle) pushOut(p Vec2, r float32) (Vec2, bool) {
+	if o.Shape == ShapeCircle {
+		minDist := o.R + r
+		delta := p.Sub(o.Pos)
+		d2 := delta.X*delta.X + delta.Y*delta.Y
+		if d2 >= minDist*minDist {
+			return p, false
+		}
+		if d2 == 0 {
+			return Vec2{X: o.Pos.X + minDist, Y: o.Pos.Y}, true
+		}
+		d := float32(math.Sqrt(float64(d2)))
+		return o.Pos.Add(delta.Mul(minDist / d)), true
+	}
+	if !o.near(p, r) {
+		return p, false
+	}
+	dist, n := o.Distance(p)
+	if dist >= r {
+		return p, false
+	}
+	return p.Add(n.Mul(r - dist)), true
+}
+
+// sweep finds the first time t in [0, 1] at which a circle of radius r moving
+// from a by d touches the obstacle, treating the obstacle as grown by r.
+// Movers that start overlapping report no hit; pushOut handles them.
+func (o *Obstacle) sweep(a, d Vec2, r float32) (float32, bool) {
+	n := o.numVertices()
+	if n < 3 {
+		return rayCircle(a, d, o.Pos, o.R+r)
+	}
+	if dist, _ := o.Distance(a); dist < r {
+		return 0, false
+	}
+	best, hit := float32(2), false
+	for i := range n {
+		nrm := o.edgeNormal(i)
+		if d.Dot(nrm) >= 0 {
+			continue
+		}
+		off := nrm.Mul(r)
+		if t, ok := raySegment(a, d, o.vertex(i).Add(off), o.vertex((i+1)%n).Add(off)); ok && t < best {
+			best, hit = t, true
+		}
+	}
+	if r > 0 {
+		for i := range n {
+			if t, ok := rayCircle(a, d, o.vertex(i), r); ok && t < best {
+				best, hit = t, true
+			}
+		}
+	}
+	return best, hit
+}
+
+// near is a bounding-circle test: false means p is at least r from the
+// obstacle.
+func (o *Obstacle) near(p Vec2, r float32) bool {
+	return dist2(p, o.Pos) < squaref(o.R+r)
+}
+
+// mayTouch is a bounding-circle test against the segment a..a+d grown by r.
+func (o *Obstacle) mayTouch(a, d Vec2, r float32) bool {
+	rr := o.R + r
+	return dist2(o.Pos, closestOnSegment(o.Pos, a, a.Add(d))) < rr*rr
+}
+
+// rayCircle returns the entry time in [0, 1] of a + d*t into a circle. Rays
+// starting inside report no hit.
+func rayCircle(a, d, c Vec2, r float32) (float32, bool) {
+	f := a.Sub(c)
+	qa := d.Dot(d)
+	qb := 2 * f.Dot(d)
+	qc := f.Dot(f) - r*r
+	if qa == 0 || qc < 0 {
+		return 0, false
+	}
+	disc := qb*qb - 4*qa*qc
+	if disc < 0 {
+		return 0, false
+	}
+	t := (-qb - float32(math.Sqrt(float64(disc)))) / (2 * qa)
+	if t < 0 || t > 1 {
+		return 0, false
+	}
+	return t, true
+}
+
+// raySegment returns the time in [0, 1] at which a + d*t crosses p..q.
+func raySegment(a, d, p, q Vec2) (float32, bool) {
+	e := q.Sub(p)
+	den := d.X*e.Y - d.Y*e.X
+	if den == 0 {
+		return 0, false
+	}
+	ap := p.Sub(a)
+	t := (ap.X*e.Y - ap.Y*e.X) / den
+	u := (ap.X*d.Y - ap.Y*d.X) / den
+	if t < 0 || t > 1 || u < 0 || u > 1 {
+		return 0, false
+	}
+	return t, true
+}
+
+func closestOnSegment(p, a, b Vec2) Vec2 {
+	ab := b.Sub(a)
+	l2 := ab.Dot(ab)
+	if l2 == 0 {
+		return a
+	}
+	t := clamp(p.Sub(a).Dot(ab)/l2, 0, 1)
+	return a.Add(ab.Mul(t))
+}
+
+// sweepObstacles returns the earliest obstacle contact of a circle moving
+// from a by d, and the surface normal there.
+func (w *World) sweepObstacles(a, d Vec2, r float32) (t float32, n Vec2, hit bool) {
+	t = 2
+	for i := range w.Obstacles {
+		o := &w.Obstacles[i]
+		if !o.mayTouch(a, d, r) {
+			continue
+		}
+		if ti, ok := o.sweep(a, d, r); ok && ti < t {
+			t, hit = ti, true
+			_, n = o.Distance(a.Add(d.Mul(ti)))
+		}
+	}
+	return t, n, hit
+}
+
+// moveEntity sweeps a circle from from towards to. On contact it stops at the
+// surface and slides along it for the rest of the step, so fast movers cannot
+// tunnel through thin obstacles.
+func (w *World) moveEntity(from, to Vec2, r float32) Vec2 {
+	rr := r + w.Cfg.ObstaclePadding
+	pos, d := from, to.Sub(from)
+	for slide := 0; slide < 2 && (d.X != 0 || d.Y != 0); slide++ {
+		t, n, hit := w.sweepObstacles(pos, d, rr)
+		if !hit {
+			pos = pos.Add(d)
+			break
+		}
+		pos = pos.Add(d.Mul(t)).Add(n.Mul(sweepSkin))
+		rest := d.Mul(1 - t)
+		d = rest.Sub(n.Mul(rest.Dot(n)))
+	}
+	return w.resolveEntityPosition(pos, r)
+}
+
+// lineOfSight reports whether the segment a..b clears every obstacle and
+// terrain wall. Water does not block sight.
+func (w *World) lineOfSight(a, b Vec2) bool {
+	d := b.Sub(a)
+	for i := range w.Obstacles {
+		o := &w.Obstacles[i]
+		if !o.mayTouch(a, d, 0) {
+			continue
+		}
+		if dist, _ := o.Distance(a); dist < 0 {
+			return false
+		}
+		if _, hit := o.sweep(a, d, 0); hit {
+			return false
+		}
+	}
+	return !w.Terrain.wallBetween(a, b)
+}
+
+// wallBetween walks the tiles the segment a..b crosses and reports whether
+// any is a wall.
+func (m *TileMap) wallBetween(a, b Vec2) bool {
+	if m == nil {
+		return false
+	}
+	off := m.Offset()
+	a, b = a.Sub(off), b.Sub(off)
+	ts := m.Params.TileSize
+	c, r := int(math.Floor(float64(a.X/ts))), int(math.Floor(float64(a.Y/ts)))
+	ec, er := int(math.Floor(float64(b.X/ts))), int(math.Floor(float64(b.Y/ts)))
+	d := b.Sub(a)
+	stepC, stepR := 1, 1
+	if d.X < 0 {
+		stepC = -1
+	}
+	if d.Y < 0 {
+		stepR = -1
+	}
+	// parametric distance to the next column and row boundary, and per tile
+	next := func(pos, delta float32, cell, step int) (float32, float32) {
+		if delta == 0 {
+			return math.MaxFloat32, math.MaxFloat32
+		}
+		edge := float32(cell) * ts
+		if step > 0 {
+			edge += ts
+		}
+		return (edge - pos) / delta, ts / float32(math.Abs(float64(delta)))
+	}
+	tC, dC := next(a.X, d.X, c, stepC)
+	tR, dR := next(a.Y, d.Y, r, stepR)
+	for {
+		if m.At(c, r) == TileWall {
+			return true
+		}
+		if c == ec && r == er {
+			return false
+		}
+		if tC < tR {
+			if tC > 1 {
+				return false
+			}
+			c += stepC
+			tC += dC
+		} else {
+			if tR > 1 {
+				return false
+			}
+			r += stepR
+			tR += dR
+		}
+	}
+}
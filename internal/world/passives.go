@@ -0,0 +1,101 @@
+package world
+
+import "fmt"
+
+// PassiveKind is a stat item the player collects through level-up upgrades.
+// Passives also unlock weapon evolutions.
+type PassiveKind int
+
+const (
+	PassiveMight      PassiveKind = iota // taken with +Damage
+	PassiveHaste                         // taken with Faster Attack
+	PassiveMagnet                        // taken with Magnet
+	PassiveArmor                         // flat damage off every hit
+	PassiveVitality                      // max HP
+	PassiveWings                         // move speed
+	PassiveArea                          // attack area
+	PassiveDuplicator                    // extra projectiles
+	PassiveLuck                          // drop chances
+	PassiveRegen                         // HP regeneration
+	PassiveRevival                       // revive once per level
+)
+
+// PassiveSlot is one owned passive and how many times it was taken.
+type PassiveSlot struct {
+	Kind  PassiveKind
+	Level int
+}
+
+// PassiveDef is what one level of a passive adds to the player's stats.
+// MaxLevel 0 leaves the passive uncapped.
+type PassiveDef struct {
+	Name     string
+	Desc     string // one level's effect
+	MaxLevel int
+	Mod      StatMod // per level
+}
+
+// The first three keep their original, uncapped upgrade effects; their
+// offers still come through UpDamage, UpAttackSpeed and UpMagnet.
+var passiveDefs = map[PassiveKind]PassiveDef{
+	PassiveMight:      {Name: "Might", Desc: "Increase damage by +10", Mod: StatMod{Stat: StatDamage, Add: 10}},
+	PassiveHaste:      {Name: "Haste", Desc: "Reduce attack cooldown by 15%", Mod: StatMod{Stat: StatCooldown, Mul: 0.85}},
+	PassiveMagnet:     {Name: "Attractor", Desc: "Increase XP pickup radius by +15", Mod: StatMod{Stat: StatMagnet, Add: 15}},
+	PassiveArmor:      {Name: "Plate", Desc: "Take 1 less damage per hit", MaxLevel: 5, Mod: StatMod{Stat: StatArmor, Add: 1}},
+	PassiveVitality:   {Name: "Hollow Heart", Desc: "Increase max HP by +20", MaxLevel: 5, Mod: StatMod{Stat: StatMaxHP, Add: 20}},
+	PassiveWings:      {Name: "Wings", Desc: "Move 10% faster", MaxLevel: 5, Mod: StatMod{Stat: StatSpeed, Mul: 1.10}},
+	PassiveArea:       {Name: "Candelabra", Desc: "Increase attack area by 10%", MaxLevel: 5, Mod: StatMod{Stat: StatArea, Mul: 1.10}},
+	PassiveDuplicator: {Name: "Duplicator", Desc: "Fire +1 projectile", MaxLevel: 2, Mod: StatMod{Stat: StatProjectiles, Add: 1}},
+	PassiveLuck:       {Name: "Clover", Desc: "Increase drop chances by 10%", MaxLevel: 5, Mod: StatMod{Stat: StatLuck, Add: 0.1}},
+	PassiveRegen:      {Name: "Pummarola", Desc: "Regenerate 0.2 HP per second", MaxLevel: 5, Mod: StatMod{Stat: StatRegen, Add: 0.2}},
+	PassiveRevival:    {Name: "Tiragisu", Desc: "Revive once at half HP", MaxLevel: 2, Mod: StatMod{Stat: StatRevivals, Add: 1}},
+}
+
+// offeredPassives lists, in offer order, the passives the upgrade menu
+// offers as UpPassive. The others have their own upgrade kinds.
+var offeredPassives = []PassiveKind{
+	PassiveArmor, PassiveVitality, PassiveWings, PassiveArea,
+	PassiveDuplicator, PassiveLuck, PassiveRegen, PassiveRevival,
+}
+
+// PassiveName returns the display name for a passive.
+func PassiveName(kind PassiveKind) string {
+	return passiveDefs[kind].Name
+}
+
+// PassiveLevel is how many times the player took kind; 0 means not owned.
+func (p *Player) PassiveLevel(kind PassiveKind) int {
+	for _, s := range p.Passives {
+		if s.Kind == kind {
+			return s.Level
+		}
+	}
+	return 0
+}
+
+// passiveMaxed reports whether kind cannot be taken again.
+func (p *Player) passiveMaxed(kind PassiveKind) bool {
+	limit := passiveDefs[kind].MaxLevel
+	return limit > 0 && p.PassiveLevel(kind) >= limit
+}
+
+func (p *Player) addPassive(kind PassiveKind) {
+	for i := range p.Passives {
+		if p.Passives[i].Kind == kind {
+			p.Passives[i].Level++
+			return
+		}
+	}
+	p.Passives = append(p.Passives, PassiveSlot{Kind: kind, Level: 1})
+}
+
+// passiveOffer builds the upgrade option for the next level of kind.
+func passiveOffer(p *Player, kind PassiveKind) UpgradeOption {
+	def := passiveDefs[kind]
+	return UpgradeOption{
+		Kind:    UpPassive,
+		Passive: kind,
+		Title:   fmt.Sprintf("%s Lv %d", def.Name, p.PassiveLevel(kind)+1),
+		Desc:    def.Desc,
+	}
+}
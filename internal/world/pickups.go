@@ -0,0 +1,283 @@
+package world
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+)
+
+// PickupKind names what collecting a pickup does. Content decides which
+// kinds drop, how often and how strong they are.
+type PickupKind string
+
+const (
+	PickupChest  PickupKind = "chest"  // grants a rolled number of random upgrades
+	PickupFood   PickupKind = "food"   // heals Value HP
+	PickupVacuum PickupKind = "vacuum" // collects every XP orb on the map
+	PickupBomb   PickupKind = "bomb"   // deals Value to enemies within Range
+	PickupGold   PickupKind = "gold"   // adds Value gold
+	PickupFrenzy PickupKind = "frenzy" // applies Buff for Duration seconds
+)
+
+var pickupKinds = []PickupKind{PickupChest, PickupFood, PickupVacuum, PickupBomb, PickupGold, PickupFrenzy}
+
+// Pickup is an item lying on the ground.
+type Pickup struct {
+	Pos  Vec2
+	R    float32
+	Kind PickupKind
+	Life float32 // seconds left; 0 stays until collected
+}
+
+// PickupDef is one pickup kind as content defines it. Kills roll Chance,
+// plus EliteChance for elites; boss kills roll BossChance instead. Luck
+// scales all three.
+type PickupDef struct {
+	Kind        PickupKind   `json:"kind"`
+	Name        string       `json:"name"`
+	Radius      float32      `json:"radius"`
+	Chance      float32      `json:"chance,omitempty"`
+	EliteChance float32      `json:"elite_chance,omitempty"`
+	BossChance  float32      `json:"boss_chance,omitempty"`
+	Value       float32      `json:"value,omitempty"`
+	Range       float32      `json:"range,omitempty"`  // bomb
+	Grants      []ChestGrant `json:"grants,omitempty"` // chest
+	Buff        StatMod      `json:"buff,omitzero"`    // frenzy
+	Duration    float32      `json:"duration,omitempty"`
+	Life        float32      `json:"life,omitempty"`
+	Color       string       `json:"color"` // "#rrggbb"
+}
+
+// ChestGrant is one weighted outcome of opening a chest.
+type ChestGrant struct {
+	Count  int `json:"count"`
+	Weight int `json:"weight"`
+}
+
+// PickupContent is the layout of the pickup content file.
+type PickupContent struct {
+	Pickups []PickupDef `json:"pickups"`
+}
+
+//go:embed content/pickups.json
+var embeddedPickups []byte
+
+var defaultPickupContent = mustParsePickupContent(embeddedPickups)
+
+// DefaultPickupContent returns a copy of the embedded pickup definitions.
+func DefaultPickupContent() PickupContent {
+	c := PickupContent{Pickups: slices.Clone(defaultPickupContent.Pickups)}
+	for i := range c.Pickups {
+		c.Pickups[i].Grants = slices.Clone(c.Pickups[i].Grants)
+	}
+	return c
+}
+
+// LoadPickupContent reads and validates a pickup content file.
+func LoadPickupContent(path string) (PickupContent, error) {
+	blob, err := os.ReadFile(path)
+	if err != nil {
+		return PickupContent{}, fmt.Errorf("read pickup content: %w", err)
+	}
+	return ParsePickupContent(blob)
+}
+
+func ParsePickupContent(blob []byte) (PickupContent, error) {
+	var c PickupContent
+	if err := json.Unmarshal(blob, &c); err != nil {
+		return PickupContent{}, fmt.Errorf("decode pickup content: %w", err)
+	}
+	if err := ValidatePickupDefs(c.Pickups); err != nil {
+		return PickupContent{}, err
+	}
+	return c, nil
+}
+
+func mustParsePickupContent(blob []byte) PickupContent {
+	c, err := ParsePickupContent(blob)
+	if err != nil {
+		panic("world: embedded pickup content: " + err.Error())
+	}
+	return c
+}
+
+// ValidatePickupDefs checks that each kind is known, listed once and has
+// usable parameters.
+func ValidatePickupDefs(defs []PickupDef) error {
+	seen := make(map[PickupKind]bool, len(defs))
+	for _, d := range defs {
+		if !slices.Contains(pickupKinds, d.Kind) {
+			return fmt.Errorf("unknown pickup %q", d.Kind)
+		}
+		if seen[d.Kind] {
+			return fmt.Errorf("duplicate pickup %q", d.Kind)
+		}
+		seen[d.Kind] = true
+		switch {
+		case d.Radius <= 0:
+			return fmt.Errorf("pickup %q needs a positive radius", d.Kind)
+		case d.Chance < 0 || d.EliteChance < 0 || d.BossChance < 0 || d.Life < 0:
+			return fmt.Errorf("pickup %q has a negative chance or life", d.Kind)
+		case d.Kind == PickupBomb && (d.Value <= 0 || d.Range <= 0):
+			return fmt.Errorf("pickup %q needs a positive value and range", d.Kind)
+		case (d.Kind == PickupFood || d.Kind == PickupGold) && d.Value <= 0:
+			return fmt.Errorf("pickup %q needs a positive value", d.Kind)
+		case d.Kind == PickupChest && len(d.Grants) == 0:
+			return fmt.Errorf("pickup %q needs grants", d.Kind)
+		case d.Kind == PickupFrenzy && (d.Duration <= 0 || (d.Buff.Add == 0 && d.Buff.Mul == 0)):
+			return fmt.Errorf("pickup %q needs a buff and a positive duration", d.Kind)
+		case d.Kind == PickupFrenzy && new(PlayerStats).field(d.Buff.Stat) == nil:
+			return fmt.Errorf("pickup %q buffs unknown stat %q", d.Kind, d.Buff.Stat)
+		}
+		for _, g := range d.Grants {
+			if g.Count <= 0 || g.Weight <= 0 {
+				return fmt.Errorf("pickup %q grants need a positive count and weight", d.Kind)
+			}
+		}
+	}
+	return nil
+}
+
+// Pickup looks up the content entry for a pickup kind.
+func (c *Config) Pickup(kind PickupKind) (*PickupDef, bool) {
+	for i := range c.Pickups {
+		if c.Pickups[i].Kind == kind {
+			return &c.Pickups[i], true
+		}
+	}
+	return nil, false
+}
+
+// dropPickups rolls every pickup's drop chance for a kill at pos. Kinds
+// without a chance for this kill draw no RNG.
+func (w *World) dropPickups(pos Vec2, elite, boss bool) {
+	n := 0
+	for i := range w.Cfg.Pickups {
+		d := &w.Cfg.Pickups[i]
+		chance := d.Chance
+		if elite {
+			chance += d.EliteChance
+		}
+		if boss {
+			chance = d.BossChance
+		}
+		chance *= w.Player.Luck
+		if chance <= 0 || (chance < 1 && w.randFloat32() >= chance) {
+			continue
+		}
+		// fan out so drops from one kill do not stack
+		w.spawnPickup(d.Kind, pos.Add(polar(float32(n)*2.4, 14*float32(n))))
+		n++
+	}
+}
+
+func (w *World) spawnPickup(kind PickupKind, pos Vec2) {
+	d, ok := w.Cfg.Pickup(kind)
+	if !ok {
+		return
+	}
+	w.Pickups = append(w.Pickups, Pickup{Pos: pos, R: d.Radius, Kind: kind, Life: d.Life})
+}
+
+// pickupRadius is how far from the player pickups and XP orbs are collected.
+func (w *World) pickupRadius() float32 {
+	return w.Player.R + w.Cfg.XPPickupPadding + w.Player.XPMagnet
+}
+
+// inPickupRange reports whether an item of radius r at pos is collected.
+func (w *World) inPickupRange(pos Vec2, r float32) bool {
+	rr := w.pickupRadius() + r
+	return dist2(w.Player.Pos, pos) <= rr*rr
+}
+
+// updatePickups expires old pickups and collects the ones in range.
+func (w *World) updatePickups(dt float32) {
+	for i := len(w.Pickups) - 1; i >= 0; i-- {
+		p := &w.Pickups[i]
+		if p.Life > 0 {
+			p.Life -= dt
+			if p.Life <= 0 {
+				w.removePickupAt(i)
+				continue
+			}
+		}
+		if w.inPickupRange(p.Pos, p.R) {
+			kind := p.Kind
+			w.removePickupAt(i)
+			w.collectPickup(kind)
+		}
+	}
+}
+
+func (w *World) removePickupAt(i int) {
+	last := len(w.Pickups) - 1
+	if i != last {
+		w.Pickups[i] = w.Pickups[last]
+	}
+	w.Pickups = w.Pickups[:last]
+}
+
+func (w *World) collectPickup(kind PickupKind) {
+	d, ok := w.Cfg.Pickup(kind)
+	if !ok {
+		return
+	}
+	p := &w.Player
+	switch kind {
+	case PickupChest:
+		w.openChest(d.Grants)
+	case PickupFood:
+		p.HP = min(p.MaxHP, p.HP+d.Value)
+	case PickupVacuum:
+		for _, o := range w.Orbs {
+			p.XP += o.Value
+			w.Stats.XPCollected += o.Value
+		}
+		w.Orbs = w.Orbs[:0]
+		w.rebuildOrbGrid()
+	case PickupBomb:
+		rr := d.Range * d.Range
+		for i := len(w.Enemies) - 1; i >= 0; i-- {
+			if i < len(w.Enemies) && dist2(p.Pos, w.Enemies[i].Pos) <= rr {
+				w.damageEnemyAt(i, d.Value)
+			}
+		}
+	case PickupGold:
+		p.Gold += int(d.Value)
+	case PickupFrenzy:
+		p.addBuff(d.Buff, d.Duration)
+		p.refreshStats()
+	}
+}
+
+// openChest applies a weighted number of upgrades drawn like level-up
+// offers, each at common rarity. Gold stands in once nothing is left.
+func (w *World) openChest(grants []ChestGrant) {
+	total := 0
+	for _, g := range grants {
+		total += g.Weight
+	}
+	if total <= 0 {
+		return
+	}
+	count := grants[len(grants)-1].Count
+	roll := w.randIntn(total)
+	for _, g := range grants {
+		if roll < g.Weight {
+			count = g.Count
+			break
+		}
+		roll -= g.Weight
+	}
+
+	for range count {
+		pool := w.upgradeCandidates()
+		if len(pool) == 0 {
+			w.applyUpgrade(UpgradeOption{Kind: UpGold})
+			continue
+		}
+		w.applyUpgrade(pool[w.pickUpgrade(pool)])
+	}
+}
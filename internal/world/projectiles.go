@@ -0,0 +1,316 @@
+package world
+
+import (
+	"math"
+	"slices"
+)
+
+const (
+	// homingRange is how far a homing projectile looks for a target.
+	homingRange float32 = 300
+	// bounceRange is how far a projectile that ran out of pierce looks for
+	// the next enemy to ricochet to.
+	bounceRange float32 = 240
+	// orbitRehit is how often an orbiter may hit the same enemy again.
+	orbitRehit float32 = 0.5
+)
+
+// firePlayerProjectiles spawns one attack of a projectile-style weapon. It
+// returns false, leaving the weapon ready, when no enemy is within reach.
+func (w *World) firePlayerProjectiles(kind WeaponKind, wd WeaponDef, st WeaponStats, reach, damage float32) bool {
+	p := w.Player.Pos
+	target := w.nearestEnemyInRange(p, reach)
+	if target < 0 {
+		return false
+	}
+	aim := w.Enemies[target].Pos.Sub(p).Norm()
+	if aim == (Vec2{}) {
+		aim = Vec2{X: 1}
+	}
+	base := math.Atan2(float64(aim.Y), float64(aim.X))
+
+	pd := wd.Projectile
+	n := max(st.Projectiles, 1)
+	for k := range n {
+		s := w.allocPlayerShot()
+		s.Weapon = kind
+		s.Style = wd.AttackStyle
+		s.R = pd.Radius
+		s.Damage = damage
+		s.Life = pd.Life
+
+		switch wd.AttackStyle {
+		case AttackOrbit:
+			s.Orbit = st.AttackRadius
+			s.Spin = pd.Speed
+			s.Angle = float32(base) + 2*math.Pi*float32(k)/float32(n)
+			s.Pos = p.Add(polar(s.Angle, s.Orbit))
+			s.HitTimer = orbitRehit
+		default:
+			// fan the volley out symmetrically around the aim direction
+			a := float32(base) + pd.Spread*(float32(k)-float32(n-1)/2)
+			s.Pos = p
+			s.Vel = polar(a, pd.Speed)
+			s.Speed = pd.Speed
+			s.Pierce = st.Pierce
+			s.Bounces = st.Bounces
+			s.Homing = pd.Homing
+			if wd.AttackStyle == AttackBoomerang && reach > 0 {
+				// decelerate to a stop exactly at reach
+				s.Decel = pd.Speed * pd.Speed / (2 * reach)
+			}
+		}
+	}
+	return true
+}
+
+// allocPlayerShot appends a zeroed projectile, reusing the Hit buffer of a
+// previously removed one.
+func (w *World) allocPlayerShot() *PlayerProjectile {
+	n := len(w.PlayerShots)
+	if n < cap(w.PlayerShots) {
+		w.PlayerShots = w.PlayerShots[:n+1]
+		hit := w.PlayerShots[n].Hit[:0]
+		w.PlayerShots[n] = PlayerProjectile{Hit: hit}
+	} else {
+		w.PlayerShots = append(w.PlayerShots, PlayerProjectile{})
+	}
+	return &w.PlayerShots[n]
+}
+
+// removePlayerShotAt swaps the removed projectile to the tail, so its Hit
+// buffer is reused by the next allocPlayerShot.
+func (w *World) removePlayerShotAt(i int) {
+	last := len(w.PlayerShots) - 1
+	if i != last {
+		w.PlayerShots[i], w.PlayerShots[last] = w.PlayerShots[last], w.PlayerShots[i]
+	}
+	w.PlayerShots = w.PlayerShots[:last]
+}
+
+func (w *World) updatePlayerProjectiles(dt float32) {
+	for i := 0; i < len(w.PlayerShots); {
+		s := &w.PlayerShots[i]
+		s.Life -= dt
+
+		alive := s.Life > 0
+		if alive {
+			switch s.Style {
+			case AttackOrbit:
+				alive = w.stepOrbiter(s, dt)
+			case AttackBoomerang:
+				alive = w.stepBoomerang(s, dt)
+			default:
+				alive = w.stepBolt(s, dt)
+			}
+		}
+		if !alive {
+			w.removePlayerShotAt(i)
+			continue
+		}
+		i++
+	}
+}
+
+func (w *World) stepOrbiter(s *PlayerProjectile, dt float32) bool {
+	s.Angle = float32(math.Remainder(float64(s.Angle+s.Spin*dt), 2*math.Pi))
+	s.Pos = w.Player.Pos.Add(polar(s.Angle, s.Orbit))
+
+	s.HitTimer -= dt
+	if s.HitTimer <= 0 {
+		s.Hit = s.Hit[:0]
+		s.HitTimer += orbitRehit
+	}
+	w.projectileHits(s, -1)
+	return true
+}
+
+func (w *World) stepBoomerang(s *PlayerProjectile, dt float32) bool {
+	if !s.Returning {
+		speed := s.Vel.Len() - s.Decel*dt
+		if speed <= 0 || w.boomerangBlocked(s, dt) {
+			// turn around; enemies hit on the way out can be hit again
+			s.Returning = true
+			s.Hit = s.Hit[:0]
+		} else {
+			s.Vel = s.Vel.Norm().Mul(speed)
+		}
+	}
+	if s.Returning {
+		to := w.Player.Pos.Sub(s.Pos)
+		rr := w.Player.R + s.R
+		if to.X*to.X+to.Y*to.Y <= rr*rr {
+			return false
+		}
+		s.Vel = to.Norm().Mul(s.Speed)
+	}
+	s.Pos = s.Pos.Add(s.Vel.Mul(dt))
+	w.projectileHits(s, -1)
+	return true
+}
+
+func (w *World) stepBolt(s *PlayerProjectile, dt float32) bool {
+	if s.Homing > 0 {
+		w.steerProjectile(s, dt)
+	}
+	d := s.Vel.Mul(dt)
+	if t, n, hit := w.sweepObstacles(s.Pos, d, s.R); hit {
+		if s.Bounces <= 0 {
+			return false
+		}
+		s.Bounces--
+		s.Pos = s.Pos.Add(d.Mul(t)).Add(n.Mul(sweepSkin))
+		s.Vel = reflectVel(s.Vel, n)
+	} else {
+		s.Pos = s.Pos.Add(d)
+	}
+	if w.outOfBounds(s.Pos) {
+		return false
+	}
+	if o := w.obstacleAt(s.Pos, s.R); o >= 0 {
+		// spawned inside; the sweep only catches entries
+		if s.Bounces <= 0 {
+			return false
+		}
+		s.Bounces--
+		w.reflectOffObstacle(s, &w.Obstacles[o])
+	}
+
+	n := w.projectileHits(s, s.Pierce+1)
+	if n == 0 {
+		return true
+	}
+	s.Pierce -= n
+	if s.Pierce >= 0 {
+		return true
+	}
+	if s.Bounces <= 0 {
+		return false
+	}
+	next := w.nearestUnhitEnemy(s, bounceRange)
+	if next < 0 {
+		return false
+	}
+	s.Bounces--
+	s.Pierce = 0
+	s.Vel = w.Enemies[next].Pos.Sub(s.Pos).Norm().Mul(s.Vel.Len())
+	return true
+}
+
+// projectileHits damages enemies the projectile overlaps and has not hit yet,
+// nearest first, up to limit (negative: no limit). It returns the number hit.
+func (w *World) projectileHits(s *PlayerProjectile, limit int) int {
+	if limit == 0 || len(w.Enemies) == 0 {
+		return 0
+	}
+	type cand struct {
+		idx int
+		d2  float32
+	}
+	var cands []cand
+	w.ensureEnemyGrid()
+	for _, i := range w.enemyCandidates(s.Pos, s.R+w.enemyGrid.maxR) {
+		e := &w.Enemies[i]
+		rr := s.R + e.R
+		d2 := dist2(s.Pos, e.Pos)
+		if d2 <= rr*rr && !slices.Contains(s.Hit, e.ID) {
+			cands = append(cands, cand{idx: i, d2: d2})
+		}
+	}
+	if len(cands) == 0 {
+		return 0
+	}
+	slices.SortFunc(cands, func(a, b cand) int {
+		if a.d2 != b.d2 {
+			if a.d2 < b.d2 {
+				return -1
+			}
+			return 1
+		}
+		return a.idx - b.idx
+	})
+	if limit > 0 && len(cands) > limit {
+		cands = cands[:limit]
+	}
+
+	idxs := make([]int, len(cands))
+	for k, c := range cands {
+		idxs[k] = c.idx
+		s.Hit = append(s.Hit, w.Enemies[c.idx].ID)
+	}
+	sortIdxDesc(idxs)
+	inflicts := weaponDef(s.Weapon).Inflicts
+	for _, idx := range idxs {
+		w.strikeEnemyAt(idx, s.Damage, inflicts)
+	}
+	return len(idxs)
+}
+
+func (w *World) nearestUnhitEnemy(s *PlayerProjectile, r float32) int {
+	for _, idx := range w.nearestEnemiesInRange(s.Pos, r, len(s.Hit)+1) {
+		if !slices.Contains(s.Hit, w.Enemies[idx].ID) {
+			return idx
+		}
+	}
+	return -1
+}
+
+// steerProjectile turns the velocity towards the nearest enemy by at most
+// Homing*dt radians, keeping its speed.
+func (w *World) steerProjectile(s *PlayerProjectile, dt float32) {
+	target := w.nearestUnhitEnemy(s, homingRange)
+	if target < 0 {
+		return
+	}
+	to := w.Enemies[target].Pos.Sub(s.Pos)
+	cur := math.Atan2(float64(s.Vel.Y), float64(s.Vel.X))
+	want := math.Atan2(float64(to.Y), float64(to.X))
+	turn := math.Remainder(want-cur, 2*math.Pi)
+	maxTurn := float64(s.Homing * dt)
+	turn = math.Max(-maxTurn, math.Min(maxTurn, turn))
+	s.Vel = polar(float32(cur+turn), s.Vel.Len())
+}
+
+// boomerangBlocked reports whether the outbound step runs into an obstacle.
+func (w *World) boomerangBlocked(s *PlayerProjectile, dt float32) bool {
+	step := s.Vel.Mul(dt)
+	if w.overlapsObstacle(s.Pos.Add(step), s.R) {
+		return true
+	}
+	_, _, hit := w.sweepObstacles(s.Pos, step, s.R+w.Cfg.ObstaclePadding)
+	return hit
+}
+
+func (w *World) obstacleAt(pos Vec2, r float32) int {
+	for i := range w.Obstacles {
+		o := &w.Obstacles[i]
+		if !o.near(pos, r) {
+			continue
+		}
+		if d, _ := o.Distance(pos); d < r {
+			return i
+		}
+	}
+	return -1
+}
+
+// reflectOffObstacle mirrors the velocity about the obstacle's surface normal
+// and pushes the projectile back outside it.
+func (w *World) reflectOffObstacle(s *PlayerProjectile, o *Obstacle) {
+	_, n := o.Distance(s.Pos)
+	s.Vel = reflectVel(s.Vel, n)
+	s.Pos, _ = o.pushOut(s.Pos, s.R)
+}
+
+// reflectVel mirrors v about the surface normal n if v points into the surface.
+func reflectVel(v, n Vec2) Vec2 {
+	if dot := v.Dot(n); dot < 0 {
+		return v.Sub(n.Mul(2 * dot))
+	}
+	return v
+}
+
+func polar(angle, length float32) Vec2 {
+	sin, cos := math.Sincos(float64(angle))
+	return Vec2{X: float32(cos) * length, Y: float32(sin) * length}
+}
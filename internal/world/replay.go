@@ -20,18 +20,35 @@ type ReplayHeader struct {
 	ConfigHash       string  `json:"config_hash"`
 }
 
+// ReplayFrame is one tick of recorded input. Choose is the upgrade option
+// picked, or banished when Banish is set; -1 means none.
 type ReplayFrame struct {
 	Tick        uint64      `json:"tick"`
 	Input       input.State `json:"input"`
 	Choose      int         `json:"choose"`
 	TogglePause bool        `json:"toggle_pause"`
 	Restart     bool        `json:"restart"`
+	Reroll      bool        `json:"reroll,omitempty"`
+	Skip        bool        `json:"skip,omitempty"`
+	Banish      bool        `json:"banish,omitempty"`
+}
+
+// ReplayKeyframeInterval is how many ticks apart recorders embed keyframe
+// snapshots, bounding how far a seek has to re-simulate.
+const ReplayKeyframeInterval = 600
+
+// ReplayKeyframe is the world state after the first Frame frames ran.
+type ReplayKeyframe struct {
+	Frame    int      `json:"frame"`
+	Snapshot Snapshot `json:"snapshot"`
 }
 
 type ReplayFile struct {
-	Header  ReplayHeader  `json:"header"`
-	Initial Snapshot      `json:"initial"`
-	Frames  []ReplayFrame `json:"frames"`
+	Header      ReplayHeader       `json:"header"`
+	Initial     Snapshot           `json:"initial"`
+	Frames      []ReplayFrame      `json:"frames"`
+	Checkpoints []ReplayCheckpoint `json:"checkpoints,omitempty"`
+	Keyframes   []ReplayKeyframe   `json:"keyframes,omitempty"`
 }
 
 func BuildReplayHeader(initial Snapshot, fixedStepSeconds float32) (ReplayHeader, error) {
@@ -48,6 +65,43 @@ func BuildReplayHeader(initial Snapshot, fixedStepSeconds float32) (ReplayHeader
 	}, nil
 }
 
+// EnqueueFrame translates one recorded frame into inbox messages. The order
+// matches live play so recorded and replayed ticks see identical inboxes.
+func (w *World) EnqueueFrame(frame ReplayFrame) {
+	w.Enqueue(MsgInput{Input: frame.Input})
+	if frame.Restart {
+		w.Enqueue(MsgRestart{})
+	}
+	if frame.TogglePause {
+		w.Enqueue(MsgTogglePause{})
+	}
+	if frame.Reroll {
+		w.Enqueue(MsgRerollUpgrades{})
+	}
+	if frame.Skip {
+		w.Enqueue(MsgSkipUpgrade{})
+	}
+	if frame.Choose >= 0 {
+		if frame.Banish {
+			w.Enqueue(MsgBanishUpgrade{Choice: frame.Choose})
+		} else {
+			w.Enqueue(MsgChooseUpgrade{Choice: frame.Choose})
+		}
+	}
+}
+
+// RecordKeyframe embeds a snapshot when the recorded frame count reaches a
+// multiple of ReplayKeyframeInterval. Call it after the latest frame ran.
+func (rep *ReplayFile) RecordKeyframe(w *World) {
+	n := len(rep.Frames)
+	if n == 0 || n%ReplayKeyframeInterval != 0 {
+		return
+	}
+	rep.Keyframes = append(rep.Keyframes, ReplayKeyframe{Frame: n, Snapshot: w.BuildSnapshot()})
+}
+
+// SaveReplayFile writes JSON, or the compact binary codec when path ends in
+// ReplayBinaryExt.
 func SaveReplayFile(path string, rep ReplayFile) error {
 	if path == "" {
 		return fmt.Errorf("replay path is empty")
@@ -55,7 +109,15 @@ func SaveReplayFile(path string, rep ReplayFile) error {
 	if rep.Header.Version != ReplayVersion {
 		return fmt.Errorf("unsupported replay version: got %d want %d", rep.Header.Version, ReplayVersion)
 	}
-	blob, err := json.MarshalIndent(rep, "", "  ")
+	var (
+		blob []byte
+		err  error
+	)
+	if isBinaryReplayPath(path) {
+		blob, err = encodeReplayBinary(rep)
+	} else {
+		blob, err = json.MarshalIndent(rep, "", "  ")
+	}
 	if err != nil {
 		return fmt.Errorf("marshal replay: %w", err)
 	}
@@ -76,6 +138,8 @@ func SaveReplayFile(path string, rep ReplayFile) error {
 	return nil
 }
 
+// LoadReplayFile reads either codec; binary files are recognised by their
+// magic bytes.
 func LoadReplayFile(path string) (ReplayFile, error) {
 	if path == "" {
 		return ReplayFile{}, fmt.Errorf("replay path is empty")
@@ -87,8 +151,18 @@ func LoadReplayFile(path string) (ReplayFile, error) {
 	}
 
 	var rep ReplayFile
-	if err := json.Unmarshal(blob, &rep); err != nil {
-		return ReplayFile{}, fmt.Errorf("decode replay file: %w", err)
+	if isBinaryReplay(blob) {
+		if rep, err = decodeReplayBinary(blob); err != nil {
+			return ReplayFile{}, err
+		}
+	} else {
+		blob, err = replayMigrations.MigrateJSON(blob)
+		if err != nil {
+			return ReplayFile{}, fmt.Errorf("migrate replay file: %w", err)
+		}
+		if err := json.Unmarshal(blob, &rep); err != nil {
+			return ReplayFile{}, fmt.Errorf("decode replay file: %w", err)
+		}
 	}
 	if rep.Header.Version != ReplayVersion {
 		return ReplayFile{}, fmt.Errorf("unsupported replay version: got %d want %d", rep.Header.Version, ReplayVersion)
@@ -96,5 +170,10 @@ func LoadReplayFile(path string) (ReplayFile, error) {
 	if rep.Initial.Version != SnapshotVersion {
 		return ReplayFile{}, fmt.Errorf("unsupported snapshot version in replay: got %d want %d", rep.Initial.Version, SnapshotVersion)
 	}
+	for _, kf := range rep.Keyframes {
+		if kf.Snapshot.Version != SnapshotVersion {
+			return ReplayFile{}, fmt.Errorf("unsupported snapshot version in replay keyframe %d: got %d want %d", kf.Frame, kf.Snapshot.Version, SnapshotVersion)
+		}
+	}
 	return rep, nil
 }
@@ -0,0 +1,369 @@
+package world
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"path/filepath"
+	"strings"
+
+	"horde-lab/internal/shared/input"
+)
+
+// ReplayBinaryExt selects the binary codec in SaveReplayFile. Loading sniffs
+// replayMagic instead, so a renamed file still decodes.
+const ReplayBinaryExt = ".hlr"
+
+// replayBinaryVersion versions the container layout below, independently of
+// ReplayVersion (the header semantics) and SnapshotVersion. v1 files end
+// after the events section, v2 files after the checkpoints. v4 added the
+// reroll, skip and banish event bits.
+const replayBinaryVersion = 4
+
+var replayMagic = [4]byte{'H', 'L', 'R', 'P'}
+
+// maxReplayFrames bounds the frame count of a binary replay, a bit over 19
+// hours at 60 ticks per second. Runs make a few bytes enough to declare any
+// count, so the decoder cannot size it against the remaining input.
+const maxReplayFrames = 1 << 22
+
+// Binary layout, integers as varints unless noted:
+//
+//	magic "HLRP" | codec version
+//	header: version, fixed step (float32 bits, uint32 LE), seed, config hash
+//	initial snapshot: length-prefixed JSON, migrated like the JSON codec
+//	frames: count, then runs of (tick gap, length, input bits) until count
+//	events: count, then (frame index gap, event bits[, choose])
+//	checkpoints: length-prefixed JSON (v2+)
+//	keyframes: count, then (frame, length-prefixed snapshot JSON) (v3+)
+//
+// A run covers consecutive ticks with the same input. Frames carry events only
+// when Choose is not -1 or one of the flags is set.
+
+const (
+	inputUp uint8 = 1 << iota
+	inputDown
+	inputLeft
+	inputRight
+)
+
+const (
+	eventTogglePause uint8 = 1 << iota
+	eventRestart
+	eventChoose
+	eventReroll
+	eventSkip
+	eventBanish // only with eventChoose
+)
+
+func isBinaryReplayPath(path string) bool {
+	return strings.EqualFold(filepath.Ext(path), ReplayBinaryExt)
+}
+
+func isBinaryReplay(blob []byte) bool {
+	return len(blob) >= len(replayMagic) && bytes.Equal(blob[:len(replayMagic)], replayMagic[:])
+}
+
+func packInput(in input.State) uint8 {
+	var b uint8
+	if in.Up {
+		b |= inputUp
+	}
+	if in.Down {
+		b |= inputDown
+	}
+	if in.Left {
+		b |= inputLeft
+	}
+	if in.Right {
+		b |= inputRight
+	}
+	return b
+}
+
+func unpackInput(b uint8) input.State {
+	return input.State{
+		Up:    b&inputUp != 0,
+		Down:  b&inputDown != 0,
+		Left:  b&inputLeft != 0,
+		Right: b&inputRight != 0,
+	}
+}
+
+func frameEvents(f ReplayFrame) uint8 {
+	var b uint8
+	if f.TogglePause {
+		b |= eventTogglePause
+	}
+	if f.Restart {
+		b |= eventRestart
+	}
+	if f.Choose != -1 {
+		b |= eventChoose
+		if f.Banish {
+			b |= eventBanish
+		}
+	}
+	if f.Reroll {
+		b |= eventReroll
+	}
+	if f.Skip {
+		b |= eventSkip
+	}
+	return b
+}
+
+func encodeReplayBinary(rep ReplayFile) ([]byte, error) {
+	initial, err := json.Marshal(rep.Initial)
+	if err != nil {
+		return nil, fmt.Errorf("marshal replay snapshot: %w", err)
+	}
+
+	buf := make([]byte, 0, 64+len(initial)+len(rep.Frames)/4)
+	buf = append(buf, replayMagic[:]...)
+	buf = binary.AppendUvarint(buf, replayBinaryVersion)
+
+	buf = binary.AppendVarint(buf, int64(rep.Header.Version))
+	buf = binary.LittleEndian.AppendUint32(buf, math.Float32bits(rep.Header.FixedStepSeconds))
+	buf = binary.AppendVarint(buf, rep.Header.Seed)
+	buf = appendBytes(buf, []byte(rep.Header.ConfigHash))
+	buf = appendBytes(buf, initial)
+
+	frames := rep.Frames
+	if len(frames) > maxReplayFrames {
+		return nil, fmt.Errorf("encode binary replay: %d frames, limit %d", len(frames), maxReplayFrames)
+	}
+	buf = binary.AppendUvarint(buf, uint64(len(frames)))
+	var prevTick uint64
+	for i := 0; i < len(frames); {
+		bits := packInput(frames[i].Input)
+		j := i + 1
+		for j < len(frames) && frames[j].Tick == frames[j-1].Tick+1 && packInput(frames[j].Input) == bits {
+			j++
+		}
+		// Ticks normally count up by one; a gap (or the first tick) is stored
+		// as the distance from the previous run's last tick.
+		gap := frames[i].Tick - prevTick
+		buf = binary.AppendUvarint(buf, gap)
+		buf = binary.AppendUvarint(buf, uint64(j-i))
+		buf = append(buf, bits)
+		prevTick = frames[j-1].Tick
+		i = j
+	}
+
+	events := 0
+	for _, f := range frames {
+		if frameEvents(f) != 0 {
+			events++
+		}
+	}
+	buf = binary.AppendUvarint(buf, uint64(events))
+	prev := 0
+	for i, f := range frames {
+		ev := frameEvents(f)
+		if ev == 0 {
+			continue
+		}
+		buf = binary.AppendUvarint(buf, uint64(i-prev))
+		buf = append(buf, ev)
+		if ev&eventChoose != 0 {
+			buf = binary.AppendVarint(buf, int64(f.Choose))
+		}
+		prev = i
+	}
+
+	checkpoints, err := json.Marshal(rep.Checkpoints)
+	if err != nil {
+		return nil, fmt.Errorf("marshal replay checkpoints: %w", err)
+	}
+	buf = appendBytes(buf, checkpoints)
+
+	buf = binary.AppendUvarint(buf, uint64(len(rep.Keyframes)))
+	for _, kf := range rep.Keyframes {
+		snap, err := json.Marshal(kf.Snapshot)
+		if err != nil {
+			return nil, fmt.Errorf("marshal replay keyframe %d: %w", kf.Frame, err)
+		}
+		buf = binary.AppendUvarint(buf, uint64(kf.Frame))
+		buf = appendBytes(buf, snap)
+	}
+	return buf, nil
+}
+
+func decodeReplayBinary(blob []byte) (ReplayFile, error) {
+	r := bytes.NewReader(blob[len(replayMagic):])
+	var rep ReplayFile
+
+	codec, err := binary.ReadUvarint(r)
+	if err != nil {
+		return rep, binaryReplayErr("codec version", err)
+	}
+	if codec == 0 || codec > replayBinaryVersion {
+		return rep, fmt.Errorf("unsupported binary replay codec: got %d want %d", codec, replayBinaryVersion)
+	}
+
+	version, err := binary.ReadVarint(r)
+	if err != nil {
+		return rep, binaryReplayErr("header version", err)
+	}
+	var stepBits [4]byte
+	if _, err := io.ReadFull(r, stepBits[:]); err != nil {
+		return rep, binaryReplayErr("fixed step", err)
+	}
+	seed, err := binary.ReadVarint(r)
+	if err != nil {
+		return rep, binaryReplayErr("seed", err)
+	}
+	hash, err := readBytes(r)
+	if err != nil {
+		return rep, binaryReplayErr("config hash", err)
+	}
+	rep.Header = ReplayHeader{
+		Version:          int(version),
+		FixedStepSeconds: math.Float32frombits(binary.LittleEndian.Uint32(stepBits[:])),
+		Seed:             seed,
+		ConfigHash:       string(hash),
+	}
+
+	initial, err := readBytes(r)
+	if err != nil {
+		return rep, binaryReplayErr("initial snapshot", err)
+	}
+	if err := decodeSnapshotJSON(initial, &rep.Initial); err != nil {
+		return rep, fmt.Errorf("replay initial snapshot: %w", err)
+	}
+
+	count, err := binary.ReadUvarint(r)
+	if err != nil {
+		return rep, binaryReplayErr("frame count", err)
+	}
+	if count > maxReplayFrames {
+		return rep, fmt.Errorf("decode binary replay: %d frames, limit %d", count, maxReplayFrames)
+	}
+	// The count is untrusted input; let append grow past the first chunk.
+	rep.Frames = make([]ReplayFrame, 0, min(count, 1<<16))
+	var tick uint64
+	for uint64(len(rep.Frames)) < count {
+		gap, err := binary.ReadUvarint(r)
+		if err != nil {
+			return rep, binaryReplayErr("frame run", err)
+		}
+		n, err := binary.ReadUvarint(r)
+		if err != nil {
+			return rep, binaryReplayErr("frame run", err)
+		}
+		bits, err := r.ReadByte()
+		if err != nil {
+			return rep, binaryReplayErr("frame run", err)
+		}
+		if n == 0 || n > count-uint64(len(rep.Frames)) {
+			return rep, fmt.Errorf("decode binary replay: bad run length %d", n)
+		}
+		in := unpackInput(bits)
+		tick += gap
+		for k := range n {
+			rep.Frames = append(rep.Frames, ReplayFrame{Tick: tick + k, Input: in, Choose: -1})
+		}
+		tick += n - 1
+	}
+
+	events, err := binary.ReadUvarint(r)
+	if err != nil {
+		return rep, binaryReplayErr("event count", err)
+	}
+	idx := uint64(0)
+	for range events {
+		gap, err := binary.ReadUvarint(r)
+		if err != nil {
+			return rep, binaryReplayErr("event", err)
+		}
+		ev, err := r.ReadByte()
+		if err != nil {
+			return rep, binaryReplayErr("event", err)
+		}
+		idx += gap
+		if idx >= uint64(len(rep.Frames)) {
+			return rep, fmt.Errorf("decode binary replay: event frame %d out of range", idx)
+		}
+		f := &rep.Frames[idx]
+		f.TogglePause = ev&eventTogglePause != 0
+		f.Restart = ev&eventRestart != 0
+		f.Reroll = ev&eventReroll != 0
+		f.Skip = ev&eventSkip != 0
+		f.Banish = ev&eventBanish != 0
+		if ev&eventChoose != 0 {
+			choose, err := binary.ReadVarint(r)
+			if err != nil {
+				return rep, binaryReplayErr("event choice", err)
+			}
+			f.Choose = int(choose)
+		}
+	}
+
+	if codec >= 2 {
+		checkpoints, err := readBytes(r)
+		if err != nil {
+			return rep, binaryReplayErr("checkpoints", err)
+		}
+		if err := json.Unmarshal(checkpoints, &rep.Checkpoints); err != nil {
+			return rep, fmt.Errorf("decode binary replay checkpoints: %w", err)
+		}
+	}
+	if codec >= 3 {
+		n, err := binary.ReadUvarint(r)
+		if err != nil {
+			return rep, binaryReplayErr("keyframe count", err)
+		}
+		for range n {
+			frame, err := binary.ReadUvarint(r)
+			if err != nil {
+				return rep, binaryReplayErr("keyframe", err)
+			}
+			if frame > uint64(len(rep.Frames)) {
+				return rep, fmt.Errorf("decode binary replay: keyframe %d past last frame", frame)
+			}
+			blob, err := readBytes(r)
+			if err != nil {
+				return rep, binaryReplayErr("keyframe", err)
+			}
+			kf := ReplayKeyframe{Frame: int(frame)}
+			if err := decodeSnapshotJSON(blob, &kf.Snapshot); err != nil {
+				return rep, fmt.Errorf("replay keyframe %d: %w", frame, err)
+			}
+			rep.Keyframes = append(rep.Keyframes, kf)
+		}
+	}
+	if r.Len() != 0 {
+		return rep, fmt.Errorf("decode binary replay: %d trailing bytes", r.Len())
+	}
+	return rep, nil
+}
+
+func appendBytes(buf, b []byte) []byte {
+	buf = binary.AppendUvarint(buf, uint64(len(b)))
+	return append(buf, b...)
+}
+
+func readBytes(r *bytes.Reader) ([]byte, error) {
+	n, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, err
+	}
+	if n > uint64(r.Len()) {
+		return nil, io.ErrUnexpectedEOF
+	}
+	b := make([]byte, n)
+	_, err = io.ReadFull(r, b)
+	return b, err
+}
+
+func binaryReplayErr(field string, err error) error {
+	if errors.Is(err, io.EOF) {
+		err = io.ErrUnexpectedEOF
+	}
+	return fmt.Errorf("decode binary replay %s: %w", field, err)
+}
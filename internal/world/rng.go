@@ -1,25 +1,80 @@
 package world
 
-import "math/rand"
+import "math/bits"
+
+// RNGState is the complete state of the world's PCG32 (XSH-RR 64/32)
+// generator. Snapshots store it verbatim, so restoring randomness costs the
+// same no matter how long the run has been going.
+type RNGState struct {
+	State uint64 `json:"state"`
+	Inc   uint64 `json:"inc"`
+}
+
+const (
+	pcgMultiplier uint64 = 6364136223846793005
+	pcgStream     uint64 = 1442695040888963407
+)
+
+// newRNGState seeds a generator the way the PCG reference implementation does.
+func newRNGState(seed int64) RNGState {
+	r := RNGState{Inc: pcgStream | 1}
+	r.next()
+	r.State += uint64(seed)
+	r.next()
+	return r
+}
+
+func (r *RNGState) next() uint32 {
+	old := r.State
+	r.State = old*pcgMultiplier + r.Inc
+	xorshifted := uint32(((old >> 18) ^ old) >> 27)
+	rot := uint32(old >> 59)
+	return bits.RotateLeft32(xorshifted, -int(rot))
+}
+
+// float32 returns a uniform value in [0, 1) built from the top 24 bits.
+func (r *RNGState) float32() float32 {
+	return float32(r.next()>>8) * (1.0 / (1 << 24))
+}
+
+// intn returns a uniform value in [0, n) using Lemire's multiply-shift with
+// rejection. It panics if n <= 0, like math/rand.
+func (r *RNGState) intn(n int) int {
+	if n <= 0 {
+		panic("world: intn called with n <= 0")
+	}
+	if n > 1<<31-1 {
+		panic("world: intn bound exceeds 31 bits")
+	}
+	bound := uint32(n)
+	hi, lo := bits.Mul32(r.next(), bound)
+	if lo < bound {
+		threshold := -bound % bound
+		for lo < threshold {
+			hi, lo = bits.Mul32(r.next(), bound)
+		}
+	}
+	return int(hi)
+}
 
 func (w *World) ensureRNG() {
-	if w.rng != nil {
+	if w.rng.Inc != 0 {
 		return
 	}
 	if w.rngSeed == 0 {
 		w.rngSeed = 1
 	}
-	w.rng = rand.New(rand.NewSource(w.rngSeed))
+	w.rng = newRNGState(w.rngSeed)
 }
 
 func (w *World) randFloat32() float32 {
 	w.ensureRNG()
 	w.rngCalls++
-	return w.rng.Float32()
+	return w.rng.float32()
 }
 
 func (w *World) randIntn(n int) int {
 	w.ensureRNG()
 	w.rngCalls++
-	return w.rng.Intn(n)
+	return w.rng.intn(n)
 }
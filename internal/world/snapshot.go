@@ -5,11 +5,10 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
-
-	"horde-lab/internal/jobs"
+	"slices"
 )
 
-const SnapshotVersion = 1
+const SnapshotVersion = 10
 
 type Snapshot struct {
 	Version int `json:"version"`
@@ -19,12 +18,15 @@ type Snapshot struct {
 
 	Cfg Config `json:"cfg"`
 
-	Player    Player            `json:"player"`
-	Enemies   []Enemy           `json:"enemies"`
-	Orbs      []XPOrb           `json:"orbs"`
-	Drops     []WeaponDrop      `json:"drops"`
-	Shots     []EnemyProjectile `json:"shots"`
-	Obstacles []Obstacle        `json:"obstacles"`
+	Player      Player             `json:"player"`
+	Enemies     []Enemy            `json:"enemies"`
+	Orbs        []XPOrb            `json:"orbs"`
+	Drops       []WeaponDrop       `json:"drops"`
+	Pickups     []Pickup           `json:"pickups"`
+	Shots       []EnemyProjectile  `json:"shots"`
+	PlayerShots []PlayerProjectile `json:"player_shots"`
+	Obstacles   []Obstacle         `json:"obstacles"`
+	Terrain     TerrainParams      `json:"terrain"`
 
 	SpawnTimer float32 `json:"spawn_timer"`
 	SpawnEvery float32 `json:"spawn_every"`
@@ -39,6 +41,7 @@ type Snapshot struct {
 	Paused       bool        `json:"paused"`
 	Upgrade      UpgradeMenu `json:"upgrade"`
 	Wave         WaveState   `json:"wave"`
+	Boss         BossState   `json:"boss"`
 	Stats        Stats       `json:"stats"`
 
 	ShakeT     float32 `json:"shake_t"`
@@ -48,22 +51,29 @@ type Snapshot struct {
 	NextEnemyID int    `json:"next_enemy_id"`
 	AITick      uint64 `json:"ai_tick"`
 
-	RNGSeed  int64  `json:"rng_seed"`
-	RNGCalls uint64 `json:"rng_calls"`
+	RNGSeed  int64    `json:"rng_seed"`
+	RNGCalls uint64   `json:"rng_calls"`
+	RNG      RNGState `json:"rng"`
 }
 
 func (w *World) BuildSnapshot() Snapshot {
-	enemies := make([]Enemy, len(w.Enemies))
-	copy(enemies, w.Enemies)
+	enemies := cloneEnemies(w.Enemies)
 
 	orbs := make([]XPOrb, len(w.Orbs))
 	copy(orbs, w.Orbs)
 	drops := make([]WeaponDrop, len(w.Drops))
 	copy(drops, w.Drops)
+	pickups := make([]Pickup, len(w.Pickups))
+	copy(pickups, w.Pickups)
 	shots := make([]EnemyProjectile, len(w.Shots))
 	copy(shots, w.Shots)
-	obstacles := make([]Obstacle, len(w.Obstacles))
-	copy(obstacles, w.Obstacles)
+	obstacles := cloneObstacles(w.Obstacles)
+	playerShots := clonePlayerShots(w.PlayerShots)
+	player := clonePlayer(w.Player)
+	var terrain TerrainParams
+	if w.Terrain != nil {
+		terrain = w.Terrain.Params
+	}
 
 	return Snapshot{
 		Version: SnapshotVersion,
@@ -71,12 +81,15 @@ func (w *World) BuildSnapshot() Snapshot {
 		H:       w.H,
 		Cfg:     w.Cfg,
 
-		Player:    w.Player,
-		Enemies:   enemies,
-		Orbs:      orbs,
-		Drops:     drops,
-		Shots:     shots,
-		Obstacles: obstacles,
+		Player:      player,
+		Enemies:     enemies,
+		Orbs:        orbs,
+		Drops:       drops,
+		Pickups:     pickups,
+		Shots:       shots,
+		PlayerShots: playerShots,
+		Obstacles:   obstacles,
+		Terrain:     terrain,
 
 		SpawnTimer: w.spawnTimer,
 		SpawnEvery: w.spawnEvery,
@@ -89,8 +102,9 @@ func (w *World) BuildSnapshot() Snapshot {
 		TimeSurvived: w.TimeSurvived,
 		GameOver:     w.GameOver,
 		Paused:       w.Paused,
-		Upgrade:      w.Upgrade,
+		Upgrade:      w.Upgrade.clone(),
 		Wave:         w.Wave,
+		Boss:         w.Boss,
 		Stats:        w.Stats,
 
 		ShakeT:     w.ShakeT,
@@ -102,6 +116,7 @@ func (w *World) BuildSnapshot() Snapshot {
 
 		RNGSeed:  w.rngSeed,
 		RNGCalls: w.rngCalls,
+		RNG:      w.rng,
 	}
 }
 
@@ -117,25 +132,32 @@ func (w *World) ApplySnapshot(s Snapshot) error {
 	w.H = s.H
 	w.Cfg = s.Cfg
 
-	w.Player = s.Player
-	if w.Cfg.PlayerMaxHPCap > 0 && w.Player.MaxHP > w.Cfg.PlayerMaxHPCap {
-		w.Player.MaxHP = w.Cfg.PlayerMaxHPCap
+	w.Player = clonePlayer(s.Player)
+	if w.Cfg.PlayerMaxHPCap > 0 && w.Player.Base.MaxHP > w.Cfg.PlayerMaxHPCap {
+		w.Player.Base.MaxHP = w.Cfg.PlayerMaxHPCap
+		w.Player.refreshStats()
 	}
 	if w.Player.HP > w.Player.MaxHP {
 		w.Player.HP = w.Player.MaxHP
 	}
-	w.Enemies = make([]Enemy, len(s.Enemies))
-	copy(w.Enemies, s.Enemies)
+	w.Enemies = cloneEnemies(s.Enemies)
 	w.Orbs = make([]XPOrb, len(s.Orbs))
 	copy(w.Orbs, s.Orbs)
 	w.Drops = make([]WeaponDrop, len(s.Drops))
 	copy(w.Drops, s.Drops)
+	w.Pickups = make([]Pickup, len(s.Pickups))
+	copy(w.Pickups, s.Pickups)
 	w.Shots = make([]EnemyProjectile, len(s.Shots))
 	copy(w.Shots, s.Shots)
-	w.Obstacles = make([]Obstacle, len(s.Obstacles))
-	copy(w.Obstacles, s.Obstacles)
-	if len(w.Obstacles) == 0 && w.Cfg.ObstacleCount > 0 {
-		w.Obstacles = generateObstacles(w.W, w.H, w.Cfg, s.RNGSeed, w.Player.Pos)
+	w.PlayerShots = clonePlayerShots(s.PlayerShots)
+	w.Obstacles = cloneObstacles(s.Obstacles)
+	if w.Terrain == nil || w.Terrain.Params != s.Terrain {
+		w.Terrain = GenerateTerrain(s.Terrain)
+	}
+	w.rebuildEnemyGrid()
+	w.rebuildOrbGrid()
+	if len(w.Obstacles) == 0 && w.Cfg.ObstacleCount > 0 && !w.chunked() {
+		w.Obstacles = generateObstacles(Vec2{}, w.W, w.H, w.Cfg, s.RNGSeed, w.Player.Pos, w.Terrain)
 	}
 
 	w.spawnTimer = s.SpawnTimer
@@ -149,11 +171,12 @@ func (w *World) ApplySnapshot(s Snapshot) error {
 	w.TimeSurvived = s.TimeSurvived
 	w.GameOver = s.GameOver
 	w.Paused = s.Paused
-	w.Upgrade = s.Upgrade
+	w.Upgrade = s.Upgrade.clone()
 	w.Wave = s.Wave
 	if w.Wave.Index == 0 {
 		w.Wave = buildWaveStateForTime(w.Cfg, w.TimeSurvived, s.RNGSeed)
 	}
+	w.Boss = s.Boss
 	w.Stats = s.Stats
 
 	w.ShakeT = s.ShakeT
@@ -167,36 +190,39 @@ func (w *World) ApplySnapshot(s Snapshot) error {
 	if w.rngSeed == 0 {
 		w.rngSeed = 1
 	}
-	w.rng = nil
-	w.rngCalls = 0
+	w.rngCalls = s.RNGCalls
+	w.rng = s.RNG
 	w.ensureRNG()
-	for range s.RNGCalls {
-		_ = w.randFloat32()
-	}
-
-	if w.aiPendingRequests == nil {
-		w.aiPendingRequests = make(map[uint64]jobs.IntentRequest, 8)
-	} else {
-		clear(w.aiPendingRequests)
-	}
-	if w.aiReadyResults == nil {
-		w.aiReadyResults = make(map[uint64]jobs.IntentResult, 8)
-	} else {
-		clear(w.aiReadyResults)
-	}
-	if w.aiPool == nil {
-		w.aiPool = newAIPool()
+	// the window is rebuilt from the player's chunk on the next tick; chunks
+	// are pure, so it matches the stored obstacles and terrain
+	w.initChunks(s.Terrain)
+	w.flowStale = true
+
+	// In-flight results belong to the replaced state, so discard them and
+	// re-submit the request the snapshotted tick left for the next one.
+	w.ensureJobs()
+	w.pool.intents.Flush()
+	w.pool.waves.Flush()
+	w.aiIntents.Reset()
+	w.waves.Reset()
+	if w.aiTick > 0 {
+		w.submitAIJob(w.aiTick)
 	}
+	w.prefetchWave(w.Wave.Index + 1)
 
 	return nil
 }
 
 func (w *World) SaveSnapshot(path string) error {
+	w.awaitSaves()
+	return writeSnapshotFile(path, w.BuildSnapshot())
+}
+
+func writeSnapshotFile(path string, s Snapshot) error {
 	if path == "" {
 		return fmt.Errorf("snapshot path is empty")
 	}
 
-	s := w.BuildSnapshot()
 	blob, err := json.MarshalIndent(s, "", "  ")
 	if err != nil {
 		return fmt.Errorf("marshal snapshot: %w", err)
@@ -224,6 +250,7 @@ func (w *World) LoadSnapshot(path string) error {
 	if path == "" {
 		return fmt.Errorf("snapshot path is empty")
 	}
+	w.awaitSaves()
 
 	blob, err := os.ReadFile(path)
 	if err != nil {
@@ -231,7 +258,7 @@ func (w *World) LoadSnapshot(path string) error {
 	}
 
 	var s Snapshot
-	if err := json.Unmarshal(blob, &s); err != nil {
+	if err := decodeSnapshotJSON(blob, &s); err != nil {
 		return fmt.Errorf("decode snapshot file: %w", err)
 	}
 
@@ -240,3 +267,42 @@ func (w *World) LoadSnapshot(path string) error {
 	}
 	return nil
 }
+
+// clonePlayer copies p with its own weapon, passive, status and buff slices.
+func clonePlayer(p Player) Player {
+	p.Weapons = slices.Clone(p.Weapons)
+	p.Passives = slices.Clone(p.Passives)
+	p.Statuses = slices.Clone(p.Statuses)
+	p.Buffs = slices.Clone(p.Buffs)
+	return p
+}
+
+// cloneEnemies deep-copies enemies, status and affix lists included.
+func cloneEnemies(src []Enemy) []Enemy {
+	out := make([]Enemy, len(src))
+	copy(out, src)
+	for i := range out {
+		out[i].Statuses = slices.Clone(out[i].Statuses)
+		out[i].Affixes = slices.Clone(out[i].Affixes)
+	}
+	return out
+}
+
+// clonePlayerShots deep-copies projectiles, Hit lists included.
+func clonePlayerShots(src []PlayerProjectile) []PlayerProjectile {
+	out := make([]PlayerProjectile, len(src))
+	for i, s := range src {
+		s.Hit = slices.Clone(s.Hit)
+		out[i] = s
+	}
+	return out
+}
+
+func cloneObstacles(src []Obstacle) []Obstacle {
+	out := make([]Obstacle, len(src))
+	for i, o := range src {
+		o.Points = slices.Clone(o.Points)
+		out[i] = o
+	}
+	return out
+}
@@ -0,0 +1,222 @@
+package world
+
+import "math"
+
+const (
+	// enemyGridCellSize is tuned for the current archetypes: enemy radii stay
+	// well under a cell, and a whip-range query touches about a 7x7 block.
+	enemyGridCellSize float32 = 64
+
+	// maxGridCells caps the dense grid; sparse layouts coarsen the cell size
+	// instead of allocating a huge mostly-empty grid.
+	maxGridCells = 1 << 16
+)
+
+// spatialGrid is a uniform grid over slice indices, rebuilt in CSR layout:
+// cell c owns order[start[c]:start[c+1]]. Bounds come from the indexed
+// positions, so the grid works for any world size. Indices are ascending
+// within a cell; callers that need a stable result order still break
+// distance ties by index.
+//
+// Swap-removes are mirrored in O(1) through slot, and entries appended after
+// a rebuild go to extra until the next rebuild.
+type spatialGrid struct {
+	baseCell float32
+	invCell  float32
+	minX     int32
+	minY     int32
+	cols     int32
+	rows     int32
+
+	start []int32
+	order []int32 // -1 marks a removed entry
+	slot  []int32 // slot[idx] >= 0: order position; < 0: extra position -(s+1)
+	extra []int32
+	cells []int32 // per-index cell scratch for the counting sort
+
+	count int
+	maxR  float32
+
+	// stale is set when an incremental update cannot be mirrored, so the
+	// next query rebuilds from scratch instead of returning wrong candidates.
+	stale bool
+}
+
+func newSpatialGrid(cellSize float32) spatialGrid {
+	if cellSize <= 0 {
+		cellSize = enemyGridCellSize
+	}
+	return spatialGrid{baseCell: cellSize, invCell: 1 / cellSize}
+}
+
+func (g *spatialGrid) coord(v float32) int32 {
+	return int32(math.Floor(float64(v * g.invCell)))
+}
+
+// build indexes n entries whose positions and radii come from at.
+func (g *spatialGrid) build(n int, at func(i int) (Vec2, float32)) {
+	if g.baseCell <= 0 {
+		*g = newSpatialGrid(enemyGridCellSize)
+	}
+	g.invCell = 1 / g.baseCell
+	g.count = n
+	g.maxR = 0
+	g.stale = false
+	g.extra = g.extra[:0]
+	g.order = resizeInt32(g.order, n)
+	g.slot = resizeInt32(g.slot, n)
+	g.cells = resizeInt32(g.cells, n)
+	if n == 0 {
+		g.cols, g.rows = 0, 0
+		return
+	}
+
+	lo, _ := at(0)
+	hi := lo
+	for i := range n {
+		p, r := at(i)
+		lo.X, lo.Y = minf(lo.X, p.X), minf(lo.Y, p.Y)
+		hi.X, hi.Y = maxf(hi.X, p.X), maxf(hi.Y, p.Y)
+		g.maxR = maxf(g.maxR, r)
+	}
+
+	cellSize := g.baseCell
+	for {
+		g.invCell = 1 / cellSize
+		g.minX, g.minY = g.coord(lo.X), g.coord(lo.Y)
+		cols := int64(g.coord(hi.X)) - int64(g.minX) + 1
+		rows := int64(g.coord(hi.Y)) - int64(g.minY) + 1
+		if cols*rows <= maxGridCells {
+			g.cols, g.rows = int32(cols), int32(rows)
+			break
+		}
+		cellSize *= 2
+	}
+
+	// counting sort: after the prefix sum start[c+1] is the end of cell c
+	cellCount := int(g.cols * g.rows)
+	g.start = resizeInt32(g.start, cellCount+1)
+	clear(g.start)
+	for i := range n {
+		p, _ := at(i)
+		c := (g.coord(p.Y)-g.minY)*g.cols + (g.coord(p.X) - g.minX)
+		g.cells[i] = c
+		g.start[c+1]++
+	}
+	for c := 1; c <= cellCount; c++ {
+		g.start[c] += g.start[c-1]
+	}
+	// Filling back to front keeps indices ascending within each cell and
+	// leaves start[c+1] pointing at the first slot of cell c.
+	for i := n - 1; i >= 0; i-- {
+		c := g.cells[i] + 1
+		g.start[c]--
+		g.order[g.start[c]] = int32(i)
+		g.slot[i] = g.start[c]
+	}
+	copy(g.start[:cellCount], g.start[1:])
+	g.start[cellCount] = int32(n)
+}
+
+// insert indexes an entry appended after the last rebuild.
+func (g *spatialGrid) insert(idx int, r float32) {
+	if idx != len(g.slot) {
+		g.stale = true
+		return
+	}
+	g.extra = append(g.extra, int32(idx))
+	g.slot = append(g.slot, -int32(len(g.extra)))
+	g.count++
+	g.maxR = maxf(g.maxR, r)
+}
+
+// removeSwap mirrors a swap-remove on the indexed slice: idx disappears and
+// the entry that lived at last is renamed to idx.
+func (g *spatialGrid) removeSwap(idx, last int) {
+	if g.count == 0 {
+		return
+	}
+	if last != len(g.slot)-1 || idx > last {
+		g.stale = true
+		return
+	}
+	g.setSlot(g.slot[idx], -1)
+	if idx != last {
+		g.slot[idx] = g.slot[last]
+		g.setSlot(g.slot[idx], int32(idx))
+	}
+	g.slot = g.slot[:last]
+	g.count--
+}
+
+func (g *spatialGrid) setSlot(s, v int32) {
+	if s >= 0 {
+		g.order[s] = v
+		return
+	}
+	g.extra[-s-1] = v
+}
+
+// queryCircle appends every index whose cell overlaps the circle's bounding
+// box, plus late inserts. Callers still run the exact distance check.
+func (g *spatialGrid) queryCircle(p Vec2, r float32, out []int) []int {
+	if g.cols > 0 {
+		x0 := max(g.coord(p.X-r)-g.minX, 0)
+		x1 := min(g.coord(p.X+r)-g.minX, g.cols-1)
+		y0 := max(g.coord(p.Y-r)-g.minY, 0)
+		y1 := min(g.coord(p.Y+r)-g.minY, g.rows-1)
+		for y := y0; y <= y1 && x0 <= x1; y++ {
+			row := y * g.cols
+			for _, idx := range g.order[g.start[row+x0]:g.start[row+x1+1]] {
+				if idx >= 0 {
+					out = append(out, int(idx))
+				}
+			}
+		}
+	}
+	for _, idx := range g.extra {
+		if idx >= 0 {
+			out = append(out, int(idx))
+		}
+	}
+	return out
+}
+
+func resizeInt32(s []int32, n int) []int32 {
+	if cap(s) < n {
+		return make([]int32, n)
+	}
+	return s[:n]
+}
+
+// rebuildEnemyGrid indexes enemies at their post-movement positions. It runs
+// once per tick; removeEnemyAt keeps it in sync for the rest of the tick.
+func (w *World) rebuildEnemyGrid() {
+	w.enemyGrid.build(len(w.Enemies), func(i int) (Vec2, float32) {
+		return w.Enemies[i].Pos, w.Enemies[i].R
+	})
+}
+
+// ensureEnemyGrid rebuilds the enemy grid if it no longer mirrors w.Enemies,
+// e.g. after tests or snapshot loads replace the slice wholesale.
+func (w *World) ensureEnemyGrid() {
+	if w.enemyGrid.stale || w.enemyGrid.count != len(w.Enemies) {
+		w.rebuildEnemyGrid()
+	}
+}
+
+// enemyCandidates returns indices of enemies whose cells overlap the circle.
+// The returned slice aliases scratch space and is only valid until the next call.
+func (w *World) enemyCandidates(p Vec2, r float32) []int {
+	w.ensureEnemyGrid()
+	w.queryBuf = w.enemyGrid.queryCircle(p, r, w.queryBuf[:0])
+	return w.queryBuf
+}
+
+// rebuildOrbGrid indexes XP orbs so the pickup query only touches cells
+// around the player.
+func (w *World) rebuildOrbGrid() {
+	w.orbGrid.build(len(w.Orbs), func(i int) (Vec2, float32) {
+		return w.Orbs[i].Pos, w.Orbs[i].R
+	})
+}
@@ -0,0 +1,323 @@
+package world
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// ReplayCheckpointInterval is how many ticks apart recorders store state
+// checkpoints in a replay.
+const ReplayCheckpointInterval = 60
+
+// StateDigest fingerprints the simulation at one tick. Hash covers every
+// section; the section hashes and Summary narrow down what diverged.
+type StateDigest struct {
+	Hash    uint64       `json:"hash"`
+	Player  uint64       `json:"player"`
+	Enemies uint64       `json:"enemies"`
+	Orbs    uint64       `json:"orbs"`
+	Drops   uint64       `json:"drops"`
+	Shots   uint64       `json:"shots"`
+	RNG     uint64       `json:"rng"`
+	Timers  uint64       `json:"timers"`
+	Summary StateSummary `json:"summary"`
+}
+
+// StateSummary keeps a few readable values next to the hashes so a desync
+// report can show more than "hash differs".
+type StateSummary struct {
+	PlayerPos    Vec2     `json:"player_pos"`
+	PlayerHP     float32  `json:"player_hp"`
+	PlayerXP     float32  `json:"player_xp"`
+	PlayerLevel  int      `json:"player_level"`
+	EnemyCount   int      `json:"enemy_count"`
+	OrbCount     int      `json:"orb_count"`
+	DropCount    int      `json:"drop_count"`
+	ShotCount    int      `json:"shot_count"`
+	PlayerShots  int      `json:"player_shots"`
+	RNG          RNGState `json:"rng"`
+	RNGCalls     uint64   `json:"rng_calls"`
+	TimeSurvived float32  `json:"time_survived"`
+	SpawnTimer   float32  `json:"spawn_timer"`
+	Wave         int      `json:"wave"`
+	Kills        int      `json:"kills"`
+}
+
+// ReplayCheckpoint is the digest recorded after the frame with Tick ran.
+type ReplayCheckpoint struct {
+	Tick   uint64      `json:"tick"`
+	Digest StateDigest `json:"digest"`
+}
+
+// StateHash is a deterministic hash of the simulation state: player,
+// enemies, orbs, drops, shots, RNG state and the remaining timers.
+func (w *World) StateHash() uint64 {
+	return w.StateDigest().Hash
+}
+
+// StateDigest hashes the current snapshot section by section. Config, world
+// size and obstacles are fixed for a run (the replay header carries the
+// config hash), so they are left out.
+func (w *World) StateDigest() StateDigest {
+	s := w.BuildSnapshot()
+
+	d := StateDigest{
+		Player:  hashValue(s.Player),
+		Enemies: hashValue(s.Enemies),
+		Orbs:    hashValue(s.Orbs),
+		Drops:   hashValue(s.Drops),
+		Shots:   hashValue([]any{s.Shots, s.PlayerShots}),
+		RNG:     hashValue([]any{s.RNGSeed, s.RNGCalls, s.RNG}),
+		Summary: StateSummary{
+			PlayerPos:    s.Player.Pos,
+			PlayerHP:     s.Player.HP,
+			PlayerXP:     s.Player.XP,
+			PlayerLevel:  s.Player.Level,
+			EnemyCount:   len(s.Enemies),
+			OrbCount:     len(s.Orbs),
+			DropCount:    len(s.Drops),
+			ShotCount:    len(s.Shots),
+			PlayerShots:  len(s.PlayerShots),
+			RNG:          s.RNG,
+			RNGCalls:     s.RNGCalls,
+			TimeSurvived: s.TimeSurvived,
+			SpawnTimer:   s.SpawnTimer,
+			Wave:         s.Wave.Index,
+			Kills:        s.Stats.EnemiesKilled,
+		},
+	}
+
+	// Everything not hashed above falls into Timers, so new snapshot fields
+	// are covered without touching this function.
+	rest := s
+	rest.Version, rest.W, rest.H = 0, 0, 0
+	rest.Cfg = Config{}
+	rest.Obstacles = nil
+	rest.Player = Player{}
+	rest.Enemies, rest.Orbs, rest.Drops, rest.Shots, rest.PlayerShots = nil, nil, nil, nil, nil
+	rest.RNGSeed, rest.RNGCalls, rest.RNG = 0, 0, RNGState{}
+	d.Timers = hashValue(rest)
+
+	h := newFNV64()
+	for _, v := range []uint64{d.Player, d.Enemies, d.Orbs, d.Drops, d.Shots, d.RNG, d.Timers} {
+		h.u64(v)
+	}
+	d.Hash = h.sum
+	return d
+}
+
+// DesyncError reports the first checkpoint a replay failed to reproduce.
+// Diff compares the digests. Fields compares the full snapshots at the
+// first recorded keyframe from Tick on, which ends FieldsFrame frames in;
+// FieldsFrame stays 0 when playback never reached one.
+type DesyncError struct {
+	Tick uint64
+	Want StateDigest
+	Got  StateDigest
+	Diff []string
+
+	FieldsFrame int
+	Fields      []string
+}
+
+func (e *DesyncError) Error() string {
+	msg := fmt.Sprintf("replay desync at tick %d: %s", e.Tick, strings.Join(e.Diff, "; "))
+	if len(e.Fields) > 0 {
+		msg += fmt.Sprintf(" (frame %d: %s)", e.FieldsFrame, strings.Join(e.Fields, "; "))
+	}
+	return msg
+}
+
+// DiffKeyframe fills in Fields from a recorded keyframe and the world
+// replayed to the same frame, unless an earlier keyframe already did.
+func (e *DesyncError) DiffKeyframe(kf ReplayKeyframe, w *World) {
+	if e.FieldsFrame != 0 {
+		return
+	}
+	e.FieldsFrame = kf.Frame
+	e.Fields = DiffSnapshots(kf.Snapshot, w.BuildSnapshot())
+}
+
+// ReplayVerifier checks replayed ticks against recorded checkpoints. After
+// the first mismatch it stops checking; later divergence is just fallout.
+type ReplayVerifier struct {
+	checkpoints []ReplayCheckpoint
+	next        int
+}
+
+func NewReplayVerifier(checkpoints []ReplayCheckpoint) *ReplayVerifier {
+	return &ReplayVerifier{checkpoints: checkpoints}
+}
+
+// Check is called after the frame with tick ran. It returns a *DesyncError
+// when the world no longer matches the checkpoint recorded for that tick.
+func (v *ReplayVerifier) Check(w *World, tick uint64) error {
+	for v.next < len(v.checkpoints) && v.checkpoints[v.next].Tick < tick {
+		v.next++
+	}
+	if v.next >= len(v.checkpoints) || v.checkpoints[v.next].Tick != tick {
+		return nil
+	}
+	want := v.checkpoints[v.next].Digest
+	v.next++
+
+	got := w.StateDigest()
+	if got.Hash == want.Hash {
+		return nil
+	}
+	v.next = len(v.checkpoints)
+	return &DesyncError{Tick: tick, Want: want, Got: got, Diff: DiffStateDigests(want, got)}
+}
+
+// RecordCheckpoint appends a checkpoint when tick closes a checkpoint
+// interval. Call it after the frame with tick ran.
+func (rep *ReplayFile) RecordCheckpoint(w *World, tick uint64) {
+	if (tick+1)%ReplayCheckpointInterval != 0 {
+		return
+	}
+	rep.Checkpoints = append(rep.Checkpoints, ReplayCheckpoint{Tick: tick, Digest: w.StateDigest()})
+}
+
+// DiffStateDigests lists differing fields as "path: want != got".
+func DiffStateDigests(want, got StateDigest) []string {
+	return diffValues("", reflect.ValueOf(want), reflect.ValueOf(got), nil)
+}
+
+// DiffSnapshots lists differing snapshot fields as "path: want != got".
+func DiffSnapshots(want, got Snapshot) []string {
+	return diffValues("", reflect.ValueOf(want), reflect.ValueOf(got), nil)
+}
+
+func diffValues(path string, a, b reflect.Value, out []string) []string {
+	switch a.Kind() {
+	case reflect.Struct:
+		t := a.Type()
+		for i := range t.NumField() {
+			out = diffValues(joinPath(path, fieldName(t.Field(i))), a.Field(i), b.Field(i), out)
+		}
+	case reflect.Slice, reflect.Array:
+		if a.Len() != b.Len() {
+			out = append(out, fmt.Sprintf("%s: len %d != %d", path, a.Len(), b.Len()))
+		}
+		for i := range min(a.Len(), b.Len()) {
+			out = diffValues(fmt.Sprintf("%s[%d]", path, i), a.Index(i), b.Index(i), out)
+		}
+	default:
+		if hashValue(a) != hashValue(b) {
+			out = append(out, fmt.Sprintf("%s: %s != %s", path, formatValue(a), formatValue(b)))
+		}
+	}
+	return out
+}
+
+func fieldName(f reflect.StructField) string {
+	if tag, _, _ := strings.Cut(f.Tag.Get("json"), ","); tag != "" && tag != "-" {
+		return tag
+	}
+	return f.Name
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+func formatValue(v reflect.Value) string {
+	switch v.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return fmt.Sprint(v.Float())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return fmt.Sprint(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return fmt.Sprintf("%#x", v.Uint())
+	case reflect.Bool:
+		return fmt.Sprint(v.Bool())
+	case reflect.String:
+		return fmt.Sprintf("%q", v.String())
+	}
+	return v.Type().String()
+}
+
+// fnv64 is FNV-1a over the little-endian encoding of each value.
+type fnv64 struct{ sum uint64 }
+
+func newFNV64() fnv64 { return fnv64{sum: 14695981039346656037} }
+
+func (h *fnv64) u64(v uint64) {
+	for range 8 {
+		h.sum ^= v & 0xff
+		h.sum *= 1099511628211
+		v >>= 8
+	}
+}
+
+// hashValue walks v field by field, so floats hash by bit pattern and the
+// result does not depend on formatting or map iteration order.
+func hashValue(v any) uint64 {
+	rv, ok := v.(reflect.Value)
+	if !ok {
+		rv = reflect.ValueOf(v)
+	}
+	h := newFNV64()
+	h.value(rv)
+	return h.sum
+}
+
+func (h *fnv64) value(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Invalid:
+		h.u64(0)
+	case reflect.Bool:
+		if v.Bool() {
+			h.u64(1)
+		} else {
+			h.u64(0)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		h.u64(uint64(v.Int()))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		h.u64(v.Uint())
+	case reflect.Float32:
+		h.u64(uint64(math.Float32bits(float32(v.Float()))))
+	case reflect.Float64:
+		h.u64(math.Float64bits(v.Float()))
+	case reflect.String:
+		s := v.String()
+		h.u64(uint64(len(s)))
+		for i := 0; i < len(s); i++ {
+			h.sum ^= uint64(s[i])
+			h.sum *= 1099511628211
+		}
+	case reflect.Struct:
+		for i := range v.NumField() {
+			h.value(v.Field(i))
+		}
+	case reflect.Slice, reflect.Array:
+		h.u64(uint64(v.Len()))
+		for i := range v.Len() {
+			h.value(v.Index(i))
+		}
+	case reflect.Map:
+		keys := v.MapKeys()
+		slices.SortFunc(keys, func(a, b reflect.Value) int {
+			return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+		})
+		h.u64(uint64(len(keys)))
+		for _, k := range keys {
+			h.value(k)
+			h.value(v.MapIndex(k))
+		}
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			h.u64(0)
+			return
+		}
+		h.u64(1)
+		h.value(v.Elem())
+	}
+}
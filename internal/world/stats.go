@@ -0,0 +1,223 @@
+package world
+
+import (
+	"cmp"
+	"math"
+	"slices"
+)
+
+// StatKind names a player stat passives and buffs modify.
+type StatKind string
+
+const (
+	StatMaxHP       StatKind = "max_hp"
+	StatSpeed       StatKind = "speed"
+	StatDamage      StatKind = "damage"
+	StatCooldown    StatKind = "cooldown"
+	StatRange       StatKind = "range"
+	StatMagnet      StatKind = "magnet"
+	StatArmor       StatKind = "armor"       // flat damage off every hit
+	StatArea        StatKind = "area"        // attack radius multiplier
+	StatProjectiles StatKind = "projectiles" // extra projectiles per volley
+	StatLuck        StatKind = "luck"        // drop chance multiplier
+	StatRegen       StatKind = "regen"       // HP per second
+	StatRevivals    StatKind = "revivals"
+)
+
+// minCooldown floors the attack cooldown passives and buffs can reach.
+const minCooldown = 0.12
+
+// minArmoredShare is the part of a hit armor can never remove.
+const minArmoredShare = 0.25
+
+// PlayerStats is one value per StatKind. Player.Base holds the values before
+// passives and buffs; counts are stored as floats so every stat runs through
+// the same pipeline.
+type PlayerStats struct {
+	MaxHP          float32
+	Speed          float32
+	Damage         float32
+	AttackCooldown float32
+	AttackRange    float32
+	XPMagnet       float32
+	Armor          float32
+	Area           float32
+	Projectiles    float32
+	Luck           float32
+	Regen          float32
+	Revivals       float32
+}
+
+// StatMod changes one stat: Add is summed into the base value, then the sum
+// is scaled by Mul (0 means no scaling).
+type StatMod struct {
+	Stat StatKind `json:"stat"`
+	Add  float32  `json:"add,omitempty"`
+	Mul  float32  `json:"mul,omitempty"`
+}
+
+// StatBuff is a temporary StatMod. The same mod does not stack: applying it
+// again while it runs only restarts the timer.
+type StatBuff struct {
+	Mod       StatMod
+	Remaining float32 // seconds
+}
+
+// basePlayerStats is a fresh player's stats for cfg, meta bonuses included.
+func basePlayerStats(cfg Config) PlayerStats {
+	maxHP := cfg.PlayerMaxHP + cfg.Meta.MaxHP
+	if cfg.PlayerMaxHPCap > 0 {
+		maxHP = min(maxHP, cfg.PlayerMaxHPCap)
+	}
+	return PlayerStats{
+		MaxHP:          maxHP,
+		Speed:          cfg.PlayerSpeed,
+		Damage:         cfg.PlayerDamage * (1 + cfg.Meta.Damage),
+		AttackCooldown: cfg.PlayerAttackCooldown,
+		AttackRange:    cfg.PlayerAttackRange,
+		XPMagnet:       10 + cfg.Meta.Magnet,
+		Area:           1,
+		Luck:           1,
+		Revivals:       float32(cfg.Meta.Revivals),
+	}
+}
+
+func (s *PlayerStats) field(kind StatKind) *float32 {
+	switch kind {
+	case StatMaxHP:
+		return &s.MaxHP
+	case StatSpeed:
+		return &s.Speed
+	case StatDamage:
+		return &s.Damage
+	case StatCooldown:
+		return &s.AttackCooldown
+	case StatRange:
+		return &s.AttackRange
+	case StatMagnet:
+		return &s.XPMagnet
+	case StatArmor:
+		return &s.Armor
+	case StatArea:
+		return &s.Area
+	case StatProjectiles:
+		return &s.Projectiles
+	case StatLuck:
+		return &s.Luck
+	case StatRegen:
+		return &s.Regen
+	case StatRevivals:
+		return &s.Revivals
+	}
+	return nil
+}
+
+// times is the mod applied level times.
+func (m StatMod) times(level int) StatMod {
+	out := StatMod{Stat: m.Stat, Add: m.Add * float32(level)}
+	if m.Mul != 0 {
+		out.Mul = float32(math.Pow(float64(m.Mul), float64(level)))
+	}
+	return out
+}
+
+func compareStatMods(a, b StatMod) int {
+	return cmp.Or(cmp.Compare(a.Stat, b.Stat), cmp.Compare(a.Add, b.Add), cmp.Compare(a.Mul, b.Mul))
+}
+
+// computePlayerStats folds passives and buffs into base. The mods are sorted
+// first, so float rounding never depends on the order passives were taken or
+// buffs applied.
+func computePlayerStats(base PlayerStats, passives []PassiveSlot, buffs []StatBuff) PlayerStats {
+	mods := make([]StatMod, 0, len(passives)+len(buffs))
+	for _, p := range passives {
+		if def, ok := passiveDefs[p.Kind]; ok && p.Level > 0 {
+			mods = append(mods, def.Mod.times(p.Level))
+		}
+	}
+	for _, b := range buffs {
+		mods = append(mods, b.Mod)
+	}
+	slices.SortFunc(mods, compareStatMods)
+
+	s := base
+	for _, m := range mods {
+		if f := s.field(m.Stat); f != nil {
+			*f += m.Add
+		}
+	}
+	for _, m := range mods {
+		if f := s.field(m.Stat); f != nil && m.Mul != 0 {
+			*f *= m.Mul
+		}
+	}
+
+	s.MaxHP = max(s.MaxHP, 1)
+	s.Speed = max(s.Speed, 0)
+	s.AttackCooldown = max(s.AttackCooldown, min(base.AttackCooldown, minCooldown))
+	s.AttackRange = max(s.AttackRange, 0)
+	s.Armor = max(s.Armor, 0)
+	s.Area = max(s.Area, 0.1)
+	s.Luck = max(s.Luck, 0)
+	return s
+}
+
+// refreshStats recomputes the effective stats from Base, Passives and Buffs.
+func (p *Player) refreshStats() {
+	s := computePlayerStats(p.Base, p.Passives, p.Buffs)
+	p.MaxHP = s.MaxHP
+	p.Speed = s.Speed
+	p.Damage = s.Damage
+	p.AttackCooldown = s.AttackCooldown
+	p.AttackRange = s.AttackRange
+	p.XPMagnet = s.XPMagnet
+	p.Armor = s.Armor
+	p.Area = s.Area
+	p.Projectiles = int(math.Round(float64(s.Projectiles)))
+	p.Luck = s.Luck
+	p.Regen = s.Regen
+	p.Revivals = int(math.Round(float64(s.Revivals)))
+	p.HP = min(p.HP, p.MaxHP)
+}
+
+// armored is the damage a hit deals after armor.
+func (p *Player) armored(dmg float32) float32 {
+	return max(dmg-p.Armor, dmg*minArmoredShare)
+}
+
+// addBuff applies mod for seconds, restarting the timer of a running copy.
+func (p *Player) addBuff(mod StatMod, seconds float32) {
+	for i := range p.Buffs {
+		if p.Buffs[i].Mod == mod {
+			p.Buffs[i].Remaining = seconds
+			return
+		}
+	}
+	p.Buffs = append(p.Buffs, StatBuff{Mod: mod, Remaining: seconds})
+}
+
+// updatePlayerStats expires buffs, recomputes the effective stats and
+// applies regeneration.
+func (w *World) updatePlayerStats(dt float32) {
+	p := &w.Player
+	for i := range p.Buffs {
+		p.Buffs[i].Remaining -= dt
+	}
+	p.Buffs = slices.DeleteFunc(p.Buffs, func(b StatBuff) bool { return b.Remaining <= 0 })
+	p.refreshStats()
+	if p.Regen > 0 && p.HP > 0 {
+		p.HP = min(p.MaxHP, p.HP+p.Regen*dt)
+	}
+}
+
+// revive spends a revival instead of ending the run.
+func (w *World) revive() bool {
+	p := &w.Player
+	if p.RevivesUsed >= p.Revivals {
+		return false
+	}
+	p.RevivesUsed++
+	p.HP = p.MaxHP * 0.5
+	p.Statuses = nil
+	return true
+}
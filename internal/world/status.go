@@ -0,0 +1,241 @@
+package world
+
+import (
+	"fmt"
+	"slices"
+)
+
+// StatusKind identifies a status effect. Kinds are strings so enemy content
+// can name them.
+type StatusKind string
+
+const (
+	StatusBurn   StatusKind = "burn"   // damage per stack every interval
+	StatusPoison StatusKind = "poison" // damage every interval; reapplying extends it
+	StatusSlow   StatusKind = "slow"   // movement speed scaled by 1-Magnitude
+	StatusFreeze StatusKind = "freeze" // cannot move, attack or deal contact damage
+	StatusStun   StatusKind = "stun"   // cannot move or attack
+	StatusVuln   StatusKind = "vulnerable"
+)
+
+// StatusStacking is how reapplying an active status combines with it.
+type StatusStacking uint8
+
+const (
+	// StackRefresh keeps one instance: the longer duration and the stronger
+	// magnitude win.
+	StackRefresh StatusStacking = iota
+	// StackIntensity adds a stack up to MaxStacks and refreshes the duration.
+	StackIntensity
+	// StackDuration adds the new duration up to MaxDuration.
+	StackDuration
+)
+
+// StatusDef holds the fixed rules for one status kind.
+type StatusDef struct {
+	Name        string
+	Stacking    StatusStacking
+	MaxStacks   int
+	MaxDuration float32 // StackDuration cap
+	Interval    float32 // damage-over-time tick, 0 for none
+}
+
+var statusDefs = map[StatusKind]StatusDef{
+	StatusBurn:   {Name: "Burn", Stacking: StackIntensity, MaxStacks: 5, Interval: 0.5},
+	StatusPoison: {Name: "Poison", Stacking: StackDuration, MaxStacks: 1, MaxDuration: 8, Interval: 1},
+	StatusSlow:   {Name: "Slow", Stacking: StackRefresh, MaxStacks: 1},
+	StatusFreeze: {Name: "Freeze", Stacking: StackRefresh, MaxStacks: 1},
+	StatusStun:   {Name: "Stun", Stacking: StackRefresh, MaxStacks: 1},
+	StatusVuln:   {Name: "Vulnerable", Stacking: StackIntensity, MaxStacks: 3},
+}
+
+// StatusName returns the display name for a status kind.
+func StatusName(kind StatusKind) string {
+	return statusDefs[kind].Name
+}
+
+// StatusApply is a status a hit inflicts. Magnitude is damage per tick for
+// burn and poison, the slowed fraction for slow and the extra damage taken
+// per stack for vulnerable. Chance 0 means always.
+type StatusApply struct {
+	Kind      StatusKind `json:"kind"`
+	Magnitude float32    `json:"magnitude,omitempty"`
+	Duration  float32    `json:"duration"`
+	Chance    float32    `json:"chance,omitempty"`
+}
+
+// StatusEffect is one active status on an enemy or the player.
+type StatusEffect struct {
+	Kind      StatusKind
+	Stacks    int
+	Magnitude float32
+	Remaining float32 // seconds
+	TickT     float32 // seconds until the next damage tick
+}
+
+// ValidateStatusApplies checks that every entry names a known status and has
+// a positive duration.
+func ValidateStatusApplies(applies []StatusApply) error {
+	for _, a := range applies {
+		if _, ok := statusDefs[a.Kind]; !ok {
+			return fmt.Errorf("unknown status %q", a.Kind)
+		}
+		if a.Duration <= 0 {
+			return fmt.Errorf("status %q needs a positive duration", a.Kind)
+		}
+	}
+	return nil
+}
+
+// addStatus applies a to the list following its kind's stacking rule.
+func addStatus(list []StatusEffect, a StatusApply) []StatusEffect {
+	def := statusDefs[a.Kind]
+	i := slices.IndexFunc(list, func(s StatusEffect) bool { return s.Kind == a.Kind })
+	if i < 0 {
+		return append(list, StatusEffect{
+			Kind:      a.Kind,
+			Stacks:    1,
+			Magnitude: a.Magnitude,
+			Remaining: a.Duration,
+			TickT:     def.Interval,
+		})
+	}
+
+	s := &list[i]
+	switch def.Stacking {
+	case StackIntensity:
+		s.Stacks = min(s.Stacks+1, max(def.MaxStacks, 1))
+		s.Magnitude = max(s.Magnitude, a.Magnitude)
+		s.Remaining = max(s.Remaining, a.Duration)
+	case StackDuration:
+		s.Magnitude = max(s.Magnitude, a.Magnitude)
+		s.Remaining = min(s.Remaining+a.Duration, max(def.MaxDuration, a.Duration))
+	default:
+		s.Magnitude = max(s.Magnitude, a.Magnitude)
+		s.Remaining = max(s.Remaining, a.Duration)
+	}
+	return list
+}
+
+// tickStatuses advances durations and returns the damage-over-time dealt this
+// step. Expired effects are dropped in place.
+func tickStatuses(list []StatusEffect, dt float32) ([]StatusEffect, float32) {
+	var dmg float32
+	for i := range list {
+		s := &list[i]
+		interval := statusDefs[s.Kind].Interval
+		if interval > 0 {
+			// an effect that expires mid-interval still gets its last tick
+			elapsed := min(dt, s.Remaining)
+			s.TickT -= elapsed
+			for s.TickT <= 0 {
+				dmg += s.Magnitude * float32(s.Stacks)
+				s.TickT += interval
+			}
+		}
+		s.Remaining -= dt
+	}
+	list = slices.DeleteFunc(list, func(s StatusEffect) bool { return s.Remaining <= 0 })
+	return list, dmg
+}
+
+func statusOf(list []StatusEffect, kind StatusKind) (StatusEffect, bool) {
+	for _, s := range list {
+		if s.Kind == kind {
+			return s, true
+		}
+	}
+	return StatusEffect{}, false
+}
+
+func hasStatus(list []StatusEffect, kind StatusKind) bool {
+	_, ok := statusOf(list, kind)
+	return ok
+}
+
+// statusHeld reports whether freeze or stun keeps the holder in place.
+func statusHeld(list []StatusEffect) bool {
+	return hasStatus(list, StatusFreeze) || hasStatus(list, StatusStun)
+}
+
+// statusSpeedScale is the movement multiplier from slow, freeze and stun.
+func statusSpeedScale(list []StatusEffect) float32 {
+	if statusHeld(list) {
+		return 0
+	}
+	if s, ok := statusOf(list, StatusSlow); ok {
+		return clamp(1-s.Magnitude, 0, 1)
+	}
+	return 1
+}
+
+// statusDamageScale is the incoming damage multiplier from vulnerable.
+func statusDamageScale(list []StatusEffect) float32 {
+	if s, ok := statusOf(list, StatusVuln); ok {
+		return 1 + s.Magnitude*float32(s.Stacks)
+	}
+	return 1
+}
+
+// rollStatus reports whether a lands. Certain applications skip the RNG so
+// they never shift the draw sequence.
+func (w *World) rollStatus(a StatusApply) bool {
+	if a.Chance <= 0 || a.Chance >= 1 {
+		return true
+	}
+	return w.randFloat32() < a.Chance
+}
+
+// applyEnemyStatuses inflicts applies on the enemy at idx.
+func (w *World) applyEnemyStatuses(idx int, applies []StatusApply) {
+	e := &w.Enemies[idx]
+	for _, a := range applies {
+		if w.rollStatus(a) {
+			e.Statuses = addStatus(e.Statuses, a)
+		}
+	}
+}
+
+// applyPlayerStatuses inflicts applies on the player.
+func (w *World) applyPlayerStatuses(applies []StatusApply) {
+	for _, a := range applies {
+		if w.rollStatus(a) {
+			w.Player.Statuses = addStatus(w.Player.Statuses, a)
+		}
+	}
+}
+
+// strikeEnemyAt applies a weapon's statuses and then its damage, so a
+// vulnerable applied by the hit already counts.
+func (w *World) strikeEnemyAt(idx int, dmg float32, applies []StatusApply) {
+	if idx < 0 || idx >= len(w.Enemies) {
+		return
+	}
+	w.applyEnemyStatuses(idx, applies)
+	w.damageEnemyAt(idx, dmg)
+}
+
+// updateStatusEffects ticks every active status and deals damage over time.
+func (w *World) updateStatusEffects(dt float32) {
+	// descending, so a kill swapping the last enemy in never skips one
+	for i := len(w.Enemies) - 1; i >= 0; i-- {
+		e := &w.Enemies[i]
+		if len(e.Statuses) == 0 {
+			continue
+		}
+		var dmg float32
+		e.Statuses, dmg = tickStatuses(e.Statuses, dt)
+		if dmg > 0 {
+			w.damageEnemyAt(i, dmg)
+		}
+	}
+
+	if len(w.Player.Statuses) == 0 {
+		return
+	}
+	var dmg float32
+	w.Player.Statuses, dmg = tickStatuses(w.Player.Statuses, dt)
+	if dmg > 0 {
+		w.damagePlayer(dmg)
+	}
+}
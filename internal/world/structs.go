@@ -1,8 +1,6 @@
 package world
 
 import (
-	"math/rand"
-
 	"horde-lab/internal/jobs"
 	"horde-lab/internal/shared/input"
 )
@@ -11,6 +9,7 @@ type XPOrb struct {
 	Pos   Vec2
 	R     float32
 	Value float32
+	Speed float32 // pull towards the player; 0 while the orb rests
 }
 
 type WeaponDrop struct {
@@ -25,25 +24,72 @@ type EnemyProjectile struct {
 	R      float32
 	Damage float32
 	Life   float32
+	Source EnemyKind // shooter archetype, for its on-hit statuses
 }
 
+// PlayerProjectile is a travelling hit fired by a projectile-style weapon.
+type PlayerProjectile struct {
+	Weapon WeaponKind
+	Style  WeaponAttackStyle
+	Pos    Vec2
+	Vel    Vec2
+	R      float32
+	Damage float32
+	Life   float32
+
+	Pierce  int     // further enemies it can pass through
+	Bounces int     // ricochets left
+	Homing  float32 // rad/s
+
+	// orbit
+	Angle float32
+	Orbit float32 // radius around the player
+	Spin  float32 // rad/s
+
+	// boomerang
+	Speed     float32
+	Decel     float32
+	Returning bool
+
+	// Hit lists enemy IDs already damaged, so one projectile hits each enemy
+	// once per pass. Orbiters clear it every HitTimer cycle.
+	Hit      []int
+	HitTimer float32
+}
+
+// Obstacle is a static blocker. R is the circle's radius, or the bounding
+// radius of the other shapes.
 type Obstacle struct {
-	Pos Vec2    `json:"pos"`
-	R   float32 `json:"r"`
+	Pos    Vec2          `json:"pos"`
+	R      float32       `json:"r"`
+	Shape  ObstacleShape `json:"shape,omitempty"`
+	Half   Vec2          `json:"half,omitzero"`    // rect half extents
+	Points []Vec2        `json:"points,omitempty"` // polygon, relative to Pos
 }
 
 type MsgInput struct{ Input input.State }
 
 type WaveState struct {
-	Index            int     `json:"index"`
-	Label            string  `json:"label"`
-	StartTime        float32 `json:"start_time"`
-	Duration         float32 `json:"duration"`
-	SpawnRateScale   float32 `json:"spawn_rate_scale"`
-	NormalWeight     int     `json:"normal_weight"`
-	RunnerWeight     int     `json:"runner_weight"`
-	TankWeight       int     `json:"tank_weight"`
-	GuaranteedTankAt int     `json:"guaranteed_tank_at"`
+	Index          int             `json:"index"`
+	Label          string          `json:"label"`
+	StartTime      float32         `json:"start_time"`
+	Duration       float32         `json:"duration"`
+	SpawnRateScale float32         `json:"spawn_rate_scale"`
+	Spawns         []WaveSpawn     `json:"spawns"`
+	Guarantees     []WaveGuarantee `json:"guarantees,omitempty"`
+	Boss           EnemyKind       `json:"boss,omitempty"`
+}
+
+// WaveSpawn is an archetype's spawn weight in the current wave.
+type WaveSpawn struct {
+	Kind   EnemyKind `json:"kind"`
+	Weight int       `json:"weight"`
+}
+
+// WaveGuarantee forces a Kind spawn on every Every-th spawn of the run.
+type WaveGuarantee struct {
+	Kind  EnemyKind `json:"kind"`
+	Every int       `json:"every"`
 }
 
 type World struct {
@@ -52,20 +98,25 @@ type World struct {
 	inboxCh  chan Msg
 	inboxBuf []Msg
 
-	Cfg       Config
-	Orbs      []XPOrb
-	Drops     []WeaponDrop
-	Shots     []EnemyProjectile
-	Obstacles []Obstacle
-	Player    Player
-	Enemies   []Enemy
+	Cfg         Config
+	Orbs        []XPOrb
+	Drops       []WeaponDrop
+	Pickups     []Pickup
+	Shots       []EnemyProjectile
+	PlayerShots []PlayerProjectile
+	Obstacles   []Obstacle
+	Terrain     *TileMap // nil without terrain; the window around the player when chunked
+	Player      Player
+	Enemies     []Enemy
+
+	chunks chunkCache
 
 	// spawning
 	spawnTimer float32
 	spawnEvery float32
-	rng        *rand.Rand
+	rng        RNGState
 	rngSeed    int64
-	rngCalls   uint64
+	rngCalls   uint64 // draw counter, kept for diagnostics
 
 	// attack visualization
 	LastAttackPos    Vec2
@@ -79,6 +130,7 @@ type World struct {
 	Paused       bool
 	Upgrade      UpgradeMenu
 	Wave         WaveState
+	Boss         BossState
 
 	// stats
 	Stats Stats
@@ -94,11 +146,27 @@ type World struct {
 	ShakePhase float32
 	ShakeOff   Vec2
 
-	// v0.3 AI intents worker-pool pipeline
-	aiPool            *jobs.IntentPool
-	aiTick            uint64
-	aiPendingRequests map[uint64]jobs.IntentRequest
-	aiReadyResults    map[uint64]jobs.IntentResult
+	// worker-pool jobs; results are taken through jobs.Pending, so late
+	// ones are computed on the tick and runs stay deterministic
+	pool      *worldJobs
+	aiTick    uint64
+	aiIntents *jobs.Pending[jobs.IntentRequest, jobs.IntentResult]
+	waves     *jobs.Pending[waveRequest, WaveState] // tagged by wave index
+	lastSave  <-chan struct{}                       // closed once the latest save is written
+
+	// flow-field pathing; the grid is rebuilt on the next AI submit once
+	// flowStale is set
+	flows       *jobs.FlowCache
+	flowGrid    *jobs.FlowGrid
+	flowVersion uint64
+	flowStale   bool
+	flowChunk   ChunkCoord
+	flowSent    flowKey
+
+	// spatial indices, rebuilt every tick (not persisted)
+	enemyGrid spatialGrid
+	orbGrid   spatialGrid
+	queryBuf  []int
 
 	nextEnemyID int
 }
@@ -108,12 +176,24 @@ type Player struct {
 	Speed float32
 	R     float32
 
-	// combat (auto attack)
+	// combat (auto attack); per-weapon cooldowns scale AttackCooldown
 	AttackCooldown float32 // seconds
-	AttackTimer    float32 // counts down to 0
 	AttackRange    float32
 	Damage         float32
-	Weapon         WeaponKind
+	Weapons        []WeaponSlot
+	Passives       []PassiveSlot
+
+	// Speed, MaxHP, the combat stats above, XPMagnet and these are effective
+	// values, recomputed every tick from Base, Passives and Buffs.
+	Armor       float32
+	Area        float32
+	Projectiles int
+	Luck        float32
+	Regen       float32
+	Revivals    int
+	Base        PlayerStats
+	Buffs       []StatBuff
+	RevivesUsed int
 
 	// health / damage taken
 	HP           float32
@@ -126,10 +206,13 @@ type Player struct {
 	XP       float32
 	XPToNext float32
 	XPMagnet float32
+	Gold     int
 
 	// knockback
 	KnockVel Vec2
 	Moving   bool
+
+	Statuses []StatusEffect
 }
 
 type Enemy struct {
@@ -152,13 +235,18 @@ type Enemy struct {
 
 	// conditional ranged attack (used by runners when player has Nova)
 	ShotTimer float32
+
+	Statuses []StatusEffect
+
+	// elites carry affixes; Shield soaks damage before HP (shielded affix)
+	Affixes []EnemyAffix
+	Shield  float32
 }
 
 type Stats struct {
-	EnemiesSpawned int
-	EnemiesKilled  int
-	DamageTaken    float32
-	XPCollected    float32
+	EnemiesSpawned  int
+	EnemiesKilled   int
+	EnemiesRecycled int
+	DamageTaken     float32
+	XPCollected     float32
 }
-
-type EnemyKind int
@@ -0,0 +1,369 @@
+package world
+
+import (
+	"math"
+	"math/rand"
+)
+
+// TileKind is one cell of the terrain grid.
+type TileKind uint8
+
+const (
+	TileFloor  TileKind = iota
+	TileRough           // second floor type; walkable, drawn differently
+	TileWall            // blocks movement
+	TileWater           // blocks movement
+	TileBridge          // walkable crossing over water
+)
+
+// Biome names a terrain preset. Runs pick one from the seed unless the
+// config forces it.
+type Biome string
+
+const (
+	BiomeGraveyard Biome = "graveyard"
+	BiomeForest    Biome = "forest"
+	BiomeRuins     Biome = "ruins"
+)
+
+var biomeOrder = []Biome{BiomeGraveyard, BiomeForest, BiomeRuins}
+
+// BiomePreset tunes the generator. Walls come from a cellular automaton
+// seeded at WallFill and smoothed WallSteps times; water and rough floor are
+// value noise with lattice spacing NoiseScale tiles, thresholded at
+// WaterLevel and RoughLevel. Every BridgeEvery-th row and column crosses
+// water as a bridge.
+type BiomePreset struct {
+	WallFill    float32
+	WallSteps   int
+	NoiseScale  int
+	WaterLevel  float32
+	RoughLevel  float32
+	BridgeEvery int
+}
+
+var biomePresets = map[Biome]BiomePreset{
+	BiomeGraveyard: {WallFill: 0.38, WallSteps: 5, NoiseScale: 7, WaterLevel: 0.74, RoughLevel: 0.52, BridgeEvery: 9},
+	BiomeForest:    {WallFill: 0.41, WallSteps: 4, NoiseScale: 9, WaterLevel: 0.66, RoughLevel: 0.45, BridgeEvery: 11},
+	BiomeRuins:     {WallFill: 0.43, WallSteps: 3, NoiseScale: 6, WaterLevel: 0.80, RoughLevel: 0.58, BridgeEvery: 8},
+}
+
+// TerrainParams is everything the generator needs. Snapshots store these
+// instead of the tiles; a zero TileSize means no terrain.
+//
+// A Chunk above 0 makes the map a window onto the endless field: Cols x Rows
+// tiles stitched from Chunk-tile squares, starting at chunk Origin.
+type TerrainParams struct {
+	Biome      Biome      `json:"biome,omitempty"`
+	Seed       int64      `json:"seed,omitempty"`
+	TileSize   float32    `json:"tile_size,omitempty"`
+	Cols       int        `json:"cols,omitempty"`
+	Rows       int        `json:"rows,omitempty"`
+	Safe       Vec2       `json:"safe"` // kept clear for the player's start
+	SafeRadius float32    `json:"safe_radius,omitempty"`
+	Chunk      int        `json:"chunk,omitempty"`
+	Origin     ChunkCoord `json:"origin,omitzero"`
+}
+
+// TileMap is a generated terrain grid in row-major order.
+type TileMap struct {
+	Params TerrainParams
+	Tiles  []TileKind
+}
+
+// terrainParams picks the terrain for a new run.
+func terrainParams(worldW, worldH float32, cfg Config, seed int64, anchor Vec2) TerrainParams {
+	if cfg.TerrainTileSize <= 0 {
+		return TerrainParams{}
+	}
+	rng := rand.New(rand.NewSource(seed ^ 0x7e44a1c3))
+	biome := cfg.Biome
+	if _, ok := biomePresets[biome]; !ok {
+		biome = biomeOrder[rng.Intn(len(biomeOrder))]
+	}
+	return TerrainParams{
+		Biome:      biome,
+		Seed:       rng.Int63(),
+		TileSize:   cfg.TerrainTileSize,
+		Cols:       int(math.Ceil(float64(worldW / cfg.TerrainTileSize))),
+		Rows:       int(math.Ceil(float64(worldH / cfg.TerrainTileSize))),
+		Safe:       anchor,
+		SafeRadius: cfg.StartSafeRadius,
+	}
+}
+
+// GenerateTerrain builds the tile grid p describes; the same params always
+// give the same tiles. Zero params give nil.
+func GenerateTerrain(p TerrainParams) *TileMap {
+	preset, ok := biomePresets[p.Biome]
+	if !ok || p.TileSize <= 0 || p.Cols <= 0 || p.Rows <= 0 {
+		return nil
+	}
+	if p.Chunk > 0 {
+		return stitchTerrain(p, func(c ChunkCoord) []TileKind { return generateChunkTiles(p, c) })
+	}
+	m := &TileMap{Params: p, Tiles: make([]TileKind, p.Cols*p.Rows)}
+	rng := rand.New(rand.NewSource(p.Seed))
+
+	walls := make([]bool, len(m.Tiles))
+	for i := range walls {
+		walls[i] = rng.Float32() < preset.WallFill
+	}
+	for range preset.WallSteps {
+		walls = m.smoothWalls(walls)
+	}
+	water := newValueNoise(rng, p.Cols, p.Rows, preset.NoiseScale)
+	rough := newValueNoise(rng, p.Cols, p.Rows, preset.NoiseScale)
+
+	for r := range p.Rows {
+		for c := range p.Cols {
+			i := r*p.Cols + c
+			switch {
+			case m.inSafeZone(c, r):
+				m.Tiles[i] = TileFloor
+			case walls[i]:
+				m.Tiles[i] = TileWall
+			case water.at(c, r) > preset.WaterLevel:
+				m.Tiles[i] = TileWater
+				if preset.BridgeEvery > 0 && (c%preset.BridgeEvery == 0 || r%preset.BridgeEvery == 0) {
+					m.Tiles[i] = TileBridge
+				}
+			case rough.at(c, r) > preset.RoughLevel:
+				m.Tiles[i] = TileRough
+			}
+		}
+	}
+	m.sealUnreachable()
+	return m
+}
+
+// smoothWalls runs one automaton step: a cell becomes wall with five or more
+// wall neighbours, floor with three or fewer. Off-map counts as floor so the
+// arena edge stays open.
+func (m *TileMap) smoothWalls(walls []bool) []bool {
+	cols, rows := m.Params.Cols, m.Params.Rows
+	out := make([]bool, len(walls))
+	for r := range rows {
+		for c := range cols {
+			n := 0
+			for dr := -1; dr <= 1; dr++ {
+				for dc := -1; dc <= 1; dc++ {
+					nc, nr := c+dc, r+dr
+					if (dc != 0 || dr != 0) && nc >= 0 && nc < cols && nr >= 0 && nr < rows && walls[nr*cols+nc] {
+						n++
+					}
+				}
+			}
+			i := r*cols + c
+			out[i] = n >= 5 || (walls[i] && n == 4)
+		}
+	}
+	return out
+}
+
+// sealUnreachable walls off walkable pockets the safe zone cannot reach, so
+// nothing spawns or drops where the player can never go.
+func (m *TileMap) sealUnreachable() {
+	if start, ok := m.cellOf(m.Params.Safe); ok {
+		m.sealFrom([]int{start})
+	}
+}
+
+// sealFrom walls off walkable tiles not connected to any start cell.
+func (m *TileMap) sealFrom(starts []int) {
+	cols, rows := m.Params.Cols, m.Params.Rows
+	seen := make([]bool, len(m.Tiles))
+	queue := make([]int, 0, len(starts))
+	for _, i := range starts {
+		if !seen[i] {
+			seen[i] = true
+			queue = append(queue, i)
+		}
+	}
+	for len(queue) > 0 {
+		i := queue[0]
+		queue = queue[1:]
+		c, r := i%cols, i/cols
+		for _, d := range [4][2]int{{1, 0}, {-1, 0}, {0, 1}, {0, -1}} {
+			nc, nr := c+d[0], r+d[1]
+			if nc < 0 || nc >= cols || nr < 0 || nr >= rows {
+				continue
+			}
+			j := nr*cols + nc
+			if !seen[j] && !m.Tiles[j].Solid() {
+				seen[j] = true
+				queue = append(queue, j)
+			}
+		}
+	}
+	for i, t := range m.Tiles {
+		if !seen[i] && !t.Solid() {
+			m.Tiles[i] = TileWall
+		}
+	}
+}
+
+func (m *TileMap) inSafeZone(c, r int) bool {
+	ts := m.Params.TileSize
+	center := Vec2{X: (float32(c) + 0.5) * ts, Y: (float32(r) + 0.5) * ts}
+	rr := m.Params.SafeRadius + ts
+	return dist2(center, m.Params.Safe) < rr*rr
+}
+
+// Solid reports whether entities are kept out of the tile.
+func (t TileKind) Solid() bool {
+	return t == TileWall || t == TileWater
+}
+
+// At returns the tile at a grid cell; cells off the map are floor.
+func (m *TileMap) At(c, r int) TileKind {
+	if m == nil || c < 0 || r < 0 || c >= m.Params.Cols || r >= m.Params.Rows {
+		return TileFloor
+	}
+	return m.Tiles[r*m.Params.Cols+c]
+}
+
+// TileAt returns the tile under a world position.
+func (m *TileMap) TileAt(pos Vec2) TileKind {
+	if m == nil {
+		return TileFloor
+	}
+	pos = pos.Sub(m.Offset())
+	ts := m.Params.TileSize
+	return m.At(int(math.Floor(float64(pos.X/ts))), int(math.Floor(float64(pos.Y/ts))))
+}
+
+// Offset is the world position of tile (0, 0): zero for a bounded map, the
+// window's corner for a streamed one.
+func (m *TileMap) Offset() Vec2 {
+	span := float32(m.Params.Chunk) * m.Params.TileSize
+	return Vec2{X: float32(m.Params.Origin.X) * span, Y: float32(m.Params.Origin.Y) * span}
+}
+
+func (m *TileMap) cellOf(pos Vec2) (int, bool) {
+	pos = pos.Sub(m.Offset())
+	ts := m.Params.TileSize
+	c, r := int(pos.X/ts), int(pos.Y/ts)
+	if pos.X < 0 || pos.Y < 0 || c >= m.Params.Cols || r >= m.Params.Rows {
+		return 0, false
+	}
+	return r*m.Params.Cols + c, true
+}
+
+// overlapsSolid reports whether a circle touches a solid tile.
+func (m *TileMap) overlapsSolid(pos Vec2, r float32) bool {
+	if m == nil {
+		return false
+	}
+	_, hit := m.pushOut(pos, r)
+	return hit
+}
+
+// nearestOpen moves pos to the center of the closest walkable tile when it
+// lies inside solid terrain, e.g. an enemy spawned in the middle of a wall.
+func (m *TileMap) nearestOpen(pos Vec2) Vec2 {
+	off := m.Offset()
+	pos = pos.Sub(off)
+	ts := m.Params.TileSize
+	c0, r0 := int(math.Floor(float64(pos.X/ts))), int(math.Floor(float64(pos.Y/ts)))
+	if !m.At(c0, r0).Solid() {
+		return pos.Add(off)
+	}
+	for ring := 1; ring < max(m.Params.Cols, m.Params.Rows); ring++ {
+		best, bestD := pos, float32(math.MaxFloat32)
+		for r := r0 - ring; r <= r0+ring; r++ {
+			for c := c0 - ring; c <= c0+ring; c++ {
+				onRing := r == r0-ring || r == r0+ring || c == c0-ring || c == c0+ring
+				if !onRing || c < 0 || r < 0 || c >= m.Params.Cols || r >= m.Params.Rows || m.At(c, r).Solid() {
+					continue
+				}
+				center := Vec2{X: (float32(c) + 0.5) * ts, Y: (float32(r) + 0.5) * ts}
+				if d := dist2(center, pos); d < bestD {
+					best, bestD = center, d
+				}
+			}
+		}
+		if bestD < math.MaxFloat32 {
+			return best.Add(off)
+		}
+	}
+	return pos.Add(off)
+}
+
+// pushOut moves a circle out of the solid tiles it overlaps, one tile at a
+// time in scan order, and reports whether any were hit.
+func (m *TileMap) pushOut(pos Vec2, r float32) (Vec2, bool) {
+	off := m.Offset()
+	pos = pos.Sub(off)
+	ts := m.Params.TileSize
+	c0, c1 := int(math.Floor(float64((pos.X-r)/ts))), int(math.Floor(float64((pos.X+r)/ts)))
+	r0, r1 := int(math.Floor(float64((pos.Y-r)/ts))), int(math.Floor(float64((pos.Y+r)/ts)))
+	hit := false
+	for row := r0; row <= r1; row++ {
+		for col := c0; col <= c1; col++ {
+			if !m.At(col, row).Solid() {
+				continue
+			}
+			minX, minY := float32(col)*ts, float32(row)*ts
+			maxX, maxY := minX+ts, minY+ts
+			near := Vec2{X: clamp(pos.X, minX, maxX), Y: clamp(pos.Y, minY, maxY)}
+			delta := pos.Sub(near)
+			d2 := delta.X*delta.X + delta.Y*delta.Y
+			if d2 >= r*r {
+				continue
+			}
+			hit = true
+			if d2 > 0 {
+				d := float32(math.Sqrt(float64(d2)))
+				pos = near.Add(delta.Mul(r / d))
+				continue
+			}
+			// center inside the tile: leave through the closest edge
+			left, right := pos.X-minX, maxX-pos.X
+			up, down := pos.Y-minY, maxY-pos.Y
+			switch minf(minf(left, right), minf(up, down)) {
+			case left:
+				pos.X = minX - r
+			case right:
+				pos.X = maxX + r
+			case up:
+				pos.Y = minY - r
+			default:
+				pos.Y = maxY + r
+			}
+		}
+	}
+	return pos.Add(off), hit
+}
+
+// valueNoise is bilinear-smoothed random lattice noise in [0, 1).
+type valueNoise struct {
+	lattice []float32
+	cols    int
+	scale   int
+}
+
+func newValueNoise(rng *rand.Rand, cols, rows, scale int) valueNoise {
+	scale = max(scale, 1)
+	lc, lr := cols/scale+2, rows/scale+2
+	n := valueNoise{lattice: make([]float32, lc*lr), cols: lc, scale: scale}
+	for i := range n.lattice {
+		n.lattice[i] = rng.Float32()
+	}
+	return n
+}
+
+func (n valueNoise) at(c, r int) float32 {
+	fx := float32(c) / float32(n.scale)
+	fy := float32(r) / float32(n.scale)
+	x0, y0 := int(fx), int(fy)
+	tx, ty := smoothstep(fx-float32(x0)), smoothstep(fy-float32(y0))
+	v := func(x, y int) float32 { return n.lattice[y*n.cols+x] }
+	top := v(x0, y0) + (v(x0+1, y0)-v(x0, y0))*tx
+	bottom := v(x0, y0+1) + (v(x0+1, y0+1)-v(x0, y0+1))*tx
+	return top + (bottom-top)*ty
+}
+
+func smoothstep(t float32) float32 {
+	return t * t * (3 - 2*t)
+}
@@ -0,0 +1,187 @@
+package world_test
+
+import (
+	"testing"
+
+	"horde-lab/internal/world"
+)
+
+// newEliteWorld holds a single plain enemy next to the player, with elites
+// always rolling.
+func newEliteWorld(t *testing.T, weapons ...world.WeaponSlot) *world.World {
+	t.Helper()
+	w := newCombatWorld(t, weapons...)
+	w.Cfg.Elites.Chance = 1
+	w.Cfg.Elites.MaxChance = 1
+	w.Enemies = []world.Enemy{{ID: 1, Kind: world.EnemyNormal, Pos: world.Vec2{X: 1050, Y: 1000}, Speed: 120, R: 9, HP: 50, MaxHP: 50, XPValue: 5}}
+	return w
+}
+
+func TestEliteRollsScaleWithWave(t *testing.T) {
+	cases := []struct {
+		wave    int
+		affixes int
+	}{
+		{wave: 1, affixes: 0},
+		{wave: 2, affixes: 1},
+		{wave: 7, affixes: 2},
+		{wave: 30, affixes: 3},
+	}
+	for _, tc := range cases {
+		w := newEliteWorld(t)
+		w.Wave.Index = tc.wave
+		w.TestOnlyRollElite(0)
+
+		e := w.Enemies[0]
+		if len(e.Affixes) != tc.affixes {
+			t.Fatalf("wave %d: got %d affixes %+v, want %d", tc.wave, len(e.Affixes), e.Affixes, tc.affixes)
+		}
+		if tc.affixes == 0 {
+			continue
+		}
+		seen := map[world.AffixKind]bool{}
+		for _, a := range e.Affixes {
+			if seen[a.Kind] {
+				t.Fatalf("wave %d: affix %q rolled twice", tc.wave, a.Kind)
+			}
+			seen[a.Kind] = true
+			def, _ := w.Cfg.Elites.Affix(a.Kind)
+			if tc.wave < def.FromWave || a.Magnitude < def.Magnitude {
+				t.Fatalf("wave %d: affix %+v outside its content entry %+v", tc.wave, a, *def)
+			}
+		}
+		if e.MaxHP != 50*w.Cfg.Elites.HPMul || e.XPValue != 5*w.Cfg.Elites.XPMul {
+			t.Fatalf("wave %d: elite HP %.1f XP %.1f not scaled", tc.wave, e.MaxHP, e.XPValue)
+		}
+	}
+}
+
+func TestEliteRollsAreDeterministic(t *testing.T) {
+	roll := func() []world.EnemyAffix {
+		w := newEliteWorld(t)
+		w.Wave.Index = 20
+		w.TestOnlyRollElite(0)
+		return w.Enemies[0].Affixes
+	}
+	a, b := roll(), roll()
+	if len(a) != len(b) {
+		t.Fatalf("rolls differ: %+v vs %+v", a, b)
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			t.Fatalf("rolls differ: %+v vs %+v", a, b)
+		}
+	}
+
+	// before the elite wave the roll leaves the RNG untouched
+	w, ref := newEliteWorld(t), newEliteWorld(t)
+	w.TestOnlyRollElite(0)
+	if w.TestOnlyRandFloat32() != ref.TestOnlyRandFloat32() {
+		t.Fatalf("elite roll drew from the RNG before from_wave")
+	}
+}
+
+func TestArmorAndShieldReduceDamage(t *testing.T) {
+	w := newEliteWorld(t, world.WeaponSlot{Kind: world.WeaponWhip, Level: 1})
+	w.Enemies[0].HP, w.Enemies[0].MaxHP = 500, 500
+	w.Enemies[0].Shield = 10
+	w.Enemies[0].Affixes = []world.EnemyAffix{
+		{Kind: world.AffixArmored, Magnitude: 0.4},
+		{Kind: world.AffixShielded, Magnitude: 0.02},
+	}
+
+	w.Tick(1.0 / 60.0)
+
+	// 25 damage, 40% armor leaves 15; the shield soaks 10 of it
+	if e := w.Enemies[0]; e.Shield != 0 || !approxEqual(e.HP, 495) {
+		t.Fatalf("shield %.2f HP %.2f, want 0 and 495", e.Shield, e.HP)
+	}
+}
+
+func TestEliteDeathAffixes(t *testing.T) {
+	t.Run("splitting", func(t *testing.T) {
+		w := newEliteWorld(t, world.WeaponSlot{Kind: world.WeaponWhip, Level: 1})
+		w.Enemies[0].HP = 1
+		w.Enemies[0].Affixes = []world.EnemyAffix{{Kind: world.AffixSplitting, Magnitude: 0.4}}
+
+		w.Tick(1.0 / 60.0)
+
+		splits := 0
+		for _, e := range w.Enemies {
+			if e.Kind == world.EnemyNormal && e.MaxHP == 20 && len(e.Affixes) == 0 {
+				splits++
+			}
+		}
+		if splits != 2 {
+			t.Fatalf("expected 2 plain splits at 40%% HP, got %d in %+v", splits, w.Enemies)
+		}
+	})
+
+	t.Run("explosive", func(t *testing.T) {
+		w := newEliteWorld(t, world.WeaponSlot{Kind: world.WeaponWhip, Level: 1})
+		w.Enemies[0].HP = 1
+		w.Enemies[0].Affixes = []world.EnemyAffix{{Kind: world.AffixExplosive, Magnitude: 12}}
+		hp := w.Player.HP
+
+		w.Tick(1.0 / 60.0)
+
+		if got := hp - w.Player.HP; got != 12 {
+			t.Fatalf("explosion dealt %.2f, want 12", got)
+		}
+	})
+
+	t.Run("drop bonus", func(t *testing.T) {
+		w := newEliteWorld(t, world.WeaponSlot{Kind: world.WeaponWhip, Level: 1})
+		w.Cfg.Elites.DropBonus = 1
+		w.Enemies[0].HP = 1
+		w.Enemies[0].Affixes = []world.EnemyAffix{{Kind: world.AffixHasted, Magnitude: 0.3}}
+
+		w.Tick(1.0 / 60.0)
+
+		if len(w.Drops) != 1 {
+			t.Fatalf("expected a guaranteed elite drop, got %d", len(w.Drops))
+		}
+	})
+}
+
+func TestVampiricElitesHealOnContact(t *testing.T) {
+	w := newEliteWorld(t)
+	e := &w.Enemies[0]
+	e.Pos, e.TouchDamage, e.HP = w.Player.Pos, 10, 20
+	e.Affixes = []world.EnemyAffix{{Kind: world.AffixVampiric, Magnitude: 0.5}}
+
+	w.Tick(1.0 / 60.0)
+
+	if got := w.Enemies[0].HP; got != 25 {
+		t.Fatalf("vampiric HP = %.2f, want 25", got)
+	}
+}
+
+func TestElitesSurviveSnapshot(t *testing.T) {
+	w := newEliteWorld(t)
+	w.Wave.Index = 20
+	w.TestOnlyRollElite(0)
+
+	snap := w.BuildSnapshot()
+	w.Enemies[0].Affixes[0].Magnitude = -1
+	if snap.Enemies[0].Affixes[0].Magnitude == -1 {
+		t.Fatalf("snapshot shares the affix list with the world")
+	}
+}
+
+func TestParseEnemyContentRejectsBadElites(t *testing.T) {
+	const pre = `{"default":"a","enemies":[{"id":"a","radius":1,"hp":1,"role":"normal"}],"elites":`
+	cases := map[string]string{
+		"chance":    `{"max_chance":2}`,
+		"kind":      `{"affixes":[{"kind":"cursed","weight":1,"magnitude":1}]}`,
+		"duplicate": `{"affixes":[{"kind":"hasted","weight":1,"magnitude":1},{"kind":"hasted","weight":1,"magnitude":1}]}`,
+		"armor":     `{"affixes":[{"kind":"armored","weight":1,"magnitude":0.5,"per_wave":0.1}]}`,
+		"split":     `{"affixes":[{"kind":"splitting","weight":1,"magnitude":0.5}]}`,
+		"explosive": `{"affixes":[{"kind":"explosive","weight":1,"magnitude":5}]}`,
+	}
+	for name, elites := range cases {
+		if _, err := world.ParseEnemyContent([]byte(pre + elites + `}`)); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
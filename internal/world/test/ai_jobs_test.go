@@ -1,6 +1,7 @@
 package world_test
 
 import (
+	"reflect"
 	"testing"
 
 	"horde-lab/internal/jobs"
@@ -12,8 +13,9 @@ func TestTickUsesReadyIntentsOnNextTickWindow(t *testing.T) {
 	defer w.Close()
 
 	w.TestOnlyDisableAIPool()
+	w.Terrain = nil
 	w.Cfg.PlayerAttackRange = 0
-	w.Player.AttackRange = 0
+	w.Player.Base.AttackRange = 0
 	w.Player.Pos = world.Vec2{X: 100, Y: 0}
 	w.Enemies = []world.Enemy{
 		{
@@ -54,8 +56,9 @@ func TestTickFallsBackToPendingIntentWhenWorkerIsLate(t *testing.T) {
 	defer w.Close()
 
 	w.TestOnlyDisableAIPool()
+	w.Terrain = nil
 	w.Cfg.PlayerAttackRange = 0
-	w.Player.AttackRange = 0
+	w.Player.Base.AttackRange = 0
 	w.Player.Pos = world.Vec2{X: 100, Y: 0}
 	w.Enemies = []world.Enemy{
 		{
@@ -86,3 +89,46 @@ func TestTickFallsBackToPendingIntentWhenWorkerIsLate(t *testing.T) {
 		t.Fatalf("expected pending fallback intent to produce rightward strafe: beforeX=%.3f afterX=%.3f", before.X, after.X)
 	}
 }
+
+func TestStalledPoolReportsLateWorkAndMatches(t *testing.T) {
+	const dt = float32(1.0 / 60.0)
+	cfg := world.DefaultConfig()
+	cfg.BaseSpawnEvery, cfg.MinSpawnEvery = 0.1, 0.1
+	cfg.PlayerMaxHP, cfg.PlayerMaxHPCap = 1e6, 1e6
+
+	pooled := world.NewWorldWithConfig(2000, 2000, cfg, 3)
+	defer pooled.Close()
+	stalled := world.NewWorldWithConfig(2000, 2000, cfg, 3)
+	defer stalled.Close()
+	stalled.TestOnlyStallAIPool()
+
+	// long enough to enter the next wave
+	for tick := 0; pooled.TimeSurvived < cfg.WaveDuration+1; tick++ {
+		if pooled.GameOver || tick > 10000 {
+			t.Fatalf("run stopped at %.1fs", pooled.TimeSurvived)
+		}
+		pooled.Tick(dt)
+		stalled.Tick(dt)
+		if pooled.StateHash() != stalled.StateHash() {
+			t.Fatalf("tick %d: stalled run diverged", tick)
+		}
+		if pooled.Upgrade.Active {
+			pooled.Enqueue(world.MsgChooseUpgrade{Choice: 0})
+			stalled.Enqueue(world.MsgChooseUpgrade{Choice: 0})
+		}
+	}
+	if pooled.Wave.Index != 2 || !reflect.DeepEqual(pooled.Wave, stalled.Wave) {
+		t.Fatalf("waves differ: %+v vs %+v", pooled.Wave, stalled.Wave)
+	}
+
+	stats := map[jobs.Kind]jobs.PoolStats{}
+	for _, s := range stalled.JobStats() {
+		stats[s.Kind] = s
+	}
+	if s := stats[jobs.KindIntents]; s.Late == 0 || s.Rejected == 0 {
+		t.Fatalf("expected late and rejected intents on a stalled pool: %+v", s)
+	}
+	if s := stats[jobs.KindWave]; s.Late != 1 {
+		t.Fatalf("expected the prefetched wave to be built late: %+v", s)
+	}
+}
@@ -0,0 +1,106 @@
+package world_test
+
+import (
+	"testing"
+
+	"horde-lab/internal/world"
+)
+
+// The embedded archetypes must reproduce the wave tables that were hard-coded
+// before enemies moved to content, or existing seeds would play differently.
+func TestDefaultArchetypesMatchLegacyWaveTable(t *testing.T) {
+	for seed := int64(1); seed <= 4; seed++ {
+		w := world.NewWorldWithConfig(800, 600, world.DefaultConfig(), seed)
+		bias := int(seed % 4)
+		for index := 1; index <= 14; index++ {
+			wave := w.TestOnlyBuildWave(index)
+
+			runner := min(6, 1+(index-1)/2+bias%2)
+			tank := 0
+			if index >= 3 {
+				tank = min(4, 1+(index-3)/2+bias/2)
+			}
+			normal := max(2, 7-index/3-bias/3)
+
+			if got := wave.Weight(world.EnemyRunner); got != runner {
+				t.Fatalf("seed %d wave %d runner weight = %d, want %d", seed, index, got, runner)
+			}
+			if got := wave.Weight(world.EnemyTank); got != tank {
+				t.Fatalf("seed %d wave %d tank weight = %d, want %d", seed, index, got, tank)
+			}
+			if got := wave.Weight(world.EnemyNormal); got != normal {
+				t.Fatalf("seed %d wave %d normal weight = %d, want %d", seed, index, got, normal)
+			}
+
+			every := 0
+			if tank > 0 {
+				every = max(6, 18-index*2-bias)
+			}
+			gotEvery := 0
+			for _, g := range wave.Guarantees {
+				if g.Kind == world.EnemyTank {
+					gotEvery = g.Every
+				}
+			}
+			if gotEvery != every {
+				t.Fatalf("seed %d wave %d tank guarantee = %d, want %d", seed, index, gotEvery, every)
+			}
+		}
+		w.Close()
+	}
+}
+
+func TestCustomArchetypeSpawnsFromConfig(t *testing.T) {
+	cfg := world.DefaultConfig()
+	cfg.DefaultEnemy = "brute"
+	cfg.Enemies = []world.EnemyArchetype{{
+		ID:     "brute",
+		Radius: 20,
+		HP:     300,
+		XP:     30,
+		Role:   "tank",
+		Draw:   world.EnemyDrawStyle{Shape: "plated", Color: "#808080"},
+		Spawn:  world.SpawnCurve{FromWave: 1, Base: 1},
+	}}
+	if err := world.ValidateEnemyArchetypes(cfg.DefaultEnemy, cfg.Enemies); err != nil {
+		t.Fatalf("ValidateEnemyArchetypes failed: %v", err)
+	}
+
+	w := world.NewWorldWithConfig(2000, 2000, cfg, 3)
+	defer w.Close()
+	for range 120 {
+		w.Enqueue(world.MsgInput{})
+		w.Tick(1.0 / 60.0)
+	}
+
+	snap := w.BuildSnapshot()
+	if len(snap.Enemies) == 0 {
+		t.Fatal("expected custom archetype to spawn")
+	}
+	for _, e := range snap.Enemies {
+		if e.Kind != "brute" || e.MaxHP != 300 || e.R != 20 || e.XPValue != 30 {
+			t.Fatalf("enemy does not use the brute archetype: %+v", e)
+		}
+	}
+}
+
+func TestParseEnemyContentRejectsBadArchetypes(t *testing.T) {
+	cases := map[string]string{
+		"duplicate": `{"default":"a","enemies":[{"id":"a","radius":1,"hp":1,"role":"normal"},{"id":"a","radius":1,"hp":1,"role":"normal"}]}`,
+		"role":      `{"default":"a","enemies":[{"id":"a","radius":1,"hp":1,"role":"sniper"}]}`,
+		"default":   `{"default":"b","enemies":[{"id":"a","radius":1,"hp":1,"role":"normal"}]}`,
+		"stats":     `{"default":"a","enemies":[{"id":"a","radius":0,"hp":1,"role":"normal"}]}`,
+		"status":    `{"default":"a","enemies":[{"id":"a","radius":1,"hp":1,"role":"normal","on_hit":[{"kind":"bleed","duration":1}]}]}`,
+		"duration":  `{"default":"a","enemies":[{"id":"a","radius":1,"hp":1,"role":"normal","on_hit":[{"kind":"slow","magnitude":0.5}]}]}`,
+	}
+	for name, blob := range cases {
+		if _, err := world.ParseEnemyContent([]byte(blob)); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+
+	c := world.DefaultEnemyContent()
+	if len(c.Enemies) == 0 || c.Default != world.EnemyNormal {
+		t.Fatalf("unexpected embedded content: %+v", c)
+	}
+}
@@ -49,8 +49,7 @@ func TestPlayerMovementRespectsObstacleCollision(t *testing.T) {
 		w.Tick(dt)
 	}
 
-	minDist := obstacle.R + w.Cfg.ObstaclePadding + w.Player.R
-	if dist2f(w.Player.Pos, obstacle.Pos) < minDist*minDist {
+	if d, _ := obstacle.Distance(w.Player.Pos); d < w.Cfg.ObstaclePadding+w.Player.R-contactEps {
 		t.Fatalf("player overlapped obstacle after movement: player=%#v obstacle=%#v", w.Player.Pos, obstacle)
 	}
 }
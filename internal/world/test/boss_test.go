@@ -0,0 +1,207 @@
+package world_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"horde-lab/internal/world"
+)
+
+// newBossWorld starts wave 5 on the next tick, spawning its boss.
+func newBossWorld(t *testing.T) *world.World {
+	t.Helper()
+	w := newCombatWorld(t)
+	w.TimeSurvived = 4 * w.Cfg.WaveDuration
+	w.Tick(1.0 / 60.0)
+	if !w.Boss.Active {
+		t.Fatalf("expected a boss to spawn on wave %d", w.Wave.Index)
+	}
+	return w
+}
+
+func bossEnemy(t *testing.T, w *world.World) *world.Enemy {
+	t.Helper()
+	for i := range w.Enemies {
+		if w.Enemies[i].ID == w.Boss.EnemyID {
+			return &w.Enemies[i]
+		}
+	}
+	t.Fatalf("boss %d not among enemies", w.Boss.EnemyID)
+	return nil
+}
+
+func TestBossWavesRotateBosses(t *testing.T) {
+	w := newCombatWorld(t)
+	want := map[int]world.EnemyKind{4: "", 5: "lich", 10: "colossus", 15: "lich", 16: ""}
+	for index, boss := range want {
+		wave := w.TestOnlyBuildWave(index)
+		if wave.Boss != boss {
+			t.Errorf("wave %d boss = %q, want %q", index, wave.Boss, boss)
+		}
+		if boss != "" && wave.Label == "" {
+			t.Errorf("wave %d has no boss label", index)
+		}
+		if wave.Weight(boss) != 0 {
+			t.Errorf("wave %d spawns boss %q from the regular mix", index, boss)
+		}
+	}
+}
+
+func TestBossPhasesFollowHP(t *testing.T) {
+	w := newBossWorld(t)
+	boss := bossEnemy(t, w)
+	if boss.Kind != "lich" || boss.MaxHP != 2400 {
+		t.Fatalf("unexpected boss %+v", *boss)
+	}
+
+	boss.HP = boss.MaxHP * 0.5
+	w.Tick(1.0 / 60.0)
+	if w.Boss.Phase != 1 {
+		t.Fatalf("phase at 50%% HP = %d, want 1", w.Boss.Phase)
+	}
+
+	// a big hit skips straight past the middle threshold
+	bossEnemy(t, w).HP = bossEnemy(t, w).MaxHP * 0.1
+	w.Tick(1.0 / 60.0)
+	if w.Boss.Phase != 2 || w.Boss.Step != 0 {
+		t.Fatalf("phase at 10%% HP = %d step %d, want 2 step 0", w.Boss.Phase, w.Boss.Step)
+	}
+	if want := float32(60 * 1.5); bossEnemy(t, w).Speed != want {
+		t.Fatalf("phase 3 speed = %.2f, want %.2f", bossEnemy(t, w).Speed, want)
+	}
+}
+
+func TestBossRingAndSummonPattern(t *testing.T) {
+	w := newBossWorld(t)
+	enemies := len(w.Enemies)
+
+	// ring after its 0.8s windup
+	for range 48 {
+		w.Tick(1.0 / 60.0)
+	}
+	shots := 0
+	for _, s := range w.Shots {
+		if s.Source == "lich" {
+			shots++
+		}
+	}
+	if shots != 12 || w.Boss.Stage != world.BossRecover {
+		t.Fatalf("expected a 12-shot ring and recovery, got %d shots stage %d", shots, w.Boss.Stage)
+	}
+
+	// 1.6s recover, then a 0.6s summon windup; timers land a tick late in float32
+	for range 135 {
+		w.Tick(1.0 / 60.0)
+	}
+	summoned := 0
+	for _, e := range w.Enemies[enemies:] {
+		if e.Kind == world.EnemyNormal {
+			summoned++
+		}
+	}
+	if summoned != 4 || w.Boss.Step != 1 {
+		t.Fatalf("expected 4 summons on step 1, got %d on step %d", summoned, w.Boss.Step)
+	}
+}
+
+func TestBossChargeDashesAtPlayer(t *testing.T) {
+	w := newCombatWorld(t)
+	w.TimeSurvived = 9 * w.Cfg.WaveDuration
+	w.Wave = w.TestOnlyBuildWave(9)
+	w.Tick(1.0 / 60.0)
+	if w.Boss.Kind != "colossus" {
+		t.Fatalf("expected the colossus on wave 10, got %+v", w.Boss)
+	}
+	boss := bossEnemy(t, w)
+	boss.Pos = world.Vec2{X: 1400, Y: 1000}
+
+	// 1s windup
+	for range 60 {
+		w.Tick(1.0 / 60.0)
+	}
+	if w.Boss.Stage != world.BossCharging {
+		t.Fatalf("expected the charge to start, stage %d", w.Boss.Stage)
+	}
+	if w.Boss.Aim.X >= -0.99 {
+		t.Fatalf("expected the charge aimed at the player, aim %+v", w.Boss.Aim)
+	}
+
+	before := bossEnemy(t, w).Pos
+	w.Tick(1.0 / 60.0)
+	if moved := before.Sub(bossEnemy(t, w).Pos).Len(); !approxEqual(moved, 380.0/60.0) {
+		t.Fatalf("charge moved %.3f, want %.3f", moved, 380.0/60.0)
+	}
+}
+
+func TestBossDefeatDropsReward(t *testing.T) {
+	w := newBossWorld(t)
+	w.Player.Weapons = []world.WeaponSlot{{Kind: world.WeaponWhip, Level: 1}}
+	boss := bossEnemy(t, w)
+	boss.Pos = world.Vec2{X: 1050, Y: 1000}
+	boss.HP = 1
+	drops, orbs := len(w.Drops), len(w.Orbs)
+
+	w.Tick(1.0 / 60.0)
+
+	if w.Boss.Active {
+		t.Fatalf("expected the boss fight to end")
+	}
+	if len(w.Drops) != drops+1 {
+		t.Fatalf("expected a guaranteed weapon drop, drops %d -> %d", drops, len(w.Drops))
+	}
+	var xp float32
+	for _, o := range w.Orbs[orbs:] {
+		xp += o.Value
+	}
+	if xp < 60+80 {
+		t.Fatalf("expected boss XP plus reward XP, got %.0f", xp)
+	}
+}
+
+func TestBossFightSurvivesSaveLoad(t *testing.T) {
+	a := newBossWorld(t)
+	bossEnemy(t, a).HP *= 0.5
+	for range 40 {
+		a.Tick(1.0 / 60.0)
+	}
+
+	path := filepath.Join(t.TempDir(), "boss.json")
+	if err := a.SaveSnapshot(path); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	b := newCombatWorld(t)
+	if err := b.LoadSnapshot(path); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if b.Boss != a.Boss {
+		t.Fatalf("boss state %+v, want %+v", b.Boss, a.Boss)
+	}
+	if err := a.LoadSnapshot(path); err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	for i := range 240 {
+		a.Tick(1.0 / 60.0)
+		b.Tick(1.0 / 60.0)
+		if a.StateHash() != b.StateHash() {
+			t.Fatalf("state diverged %d ticks after load", i+1)
+		}
+	}
+}
+
+func TestParseEnemyContentRejectsBadBosses(t *testing.T) {
+	const pre = `{"default":"a","enemies":[{"id":"a","radius":1,"hp":1,"role":"normal"},{"id":"b","radius":1,"hp":1,"role":"tank","boss":`
+	cases := map[string]string{
+		"phases":  `{"phases":[]}`,
+		"order":   `{"phases":[{"hp_below":1,"pattern":[{"kind":"charge","speed":1,"duration":1}]},{"hp_below":1,"pattern":[{"kind":"charge","speed":1,"duration":1}]}]}`,
+		"attack":  `{"phases":[{"hp_below":1,"pattern":[{"kind":"laser"}]}]}`,
+		"summon":  `{"phases":[{"hp_below":1,"pattern":[{"kind":"summon","count":2,"summon":"ghost"}]}]}`,
+		"self":    `{"phases":[{"hp_below":1,"pattern":[{"kind":"summon","count":2,"summon":"b"}]}]}`,
+		"ring":    `{"phases":[{"hp_below":1,"pattern":[{"kind":"ring","speed":100,"duration":1}]}]}`,
+		"pattern": `{"phases":[{"hp_below":1,"pattern":[]}]}`,
+	}
+	for name, boss := range cases {
+		if _, err := world.ParseEnemyContent([]byte(pre + boss + `}]}`)); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package world_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"horde-lab/internal/world"
+)
+
+func newChunkedWorld(tb testing.TB, seed int64) *world.World {
+	tb.Helper()
+	w := newArena(tb, arena{seed: seed, keepMap: true})
+	w.Cfg.PlayerMaxHP, w.Player.HP, w.Player.MaxHP = 1e6, 1e6, 1e6
+	return w
+}
+
+func TestChunksDoNotDependOnStreaming(t *testing.T) {
+	const dt = float32(1.0 / 60.0)
+	streamed := newChunkedWorld(t, 5)
+	sync := newChunkedWorld(t, 5)
+	sync.TestOnlyDisableChunkStreamer()
+
+	// far enough to cross several chunks each way
+	step := world.Vec2{X: 23, Y: -9}
+	for tick := range 300 {
+		streamed.Player.Pos = streamed.Player.Pos.Add(step)
+		sync.Player.Pos = sync.Player.Pos.Add(step)
+		streamed.Tick(dt)
+		sync.Tick(dt)
+		if !reflect.DeepEqual(streamed.Obstacles, sync.Obstacles) || !reflect.DeepEqual(streamed.Terrain, sync.Terrain) {
+			t.Fatalf("tick %d: streamed window differs from the generated one", tick)
+		}
+		if streamed.StateHash() != sync.StateHash() {
+			t.Fatalf("tick %d: state hash diverged", tick)
+		}
+	}
+	if got := sync.ChunkStats(); got.Streamed != 0 || got.Generated == 0 || got.Unloaded == 0 {
+		t.Fatalf("unexpected stats without a streamer: %+v", got)
+	}
+}
+
+func TestWindowFollowsPlayerPastWorldBounds(t *testing.T) {
+	w := newChunkedWorld(t, 2)
+	before := w.Terrain.Offset()
+	size := world.DefaultConfig().ChunkSize
+
+	// walk well outside the nominal 2000 x 2000 box
+	for range 600 {
+		w.Player.Pos.X -= 10
+		w.Tick(1.0 / 60.0)
+	}
+	if w.Player.Pos.X > -3000 {
+		t.Fatalf("player held back at %v", w.Player.Pos)
+	}
+	off := w.Terrain.Offset()
+	if off.X >= before.X || off.Y != before.Y {
+		t.Fatalf("terrain window did not follow the player: %v -> %v", before, off)
+	}
+	span := float32(w.Terrain.Params.Cols) * w.Terrain.Params.TileSize
+	if p := w.Player.Pos.Sub(off); p.X < size || p.X > span-size {
+		t.Fatalf("player at %v is not inside the middle of the window at %v", w.Player.Pos, off)
+	}
+	for _, o := range w.Obstacles {
+		if d := o.Pos.Sub(w.Player.Pos); max(d.X, -d.X, d.Y, -d.Y) > span {
+			t.Fatalf("obstacle at %v kept from an unloaded chunk", o.Pos)
+		}
+	}
+	if w.ChunkStats().Unloaded == 0 {
+		t.Fatal("expected chunks behind the player to be unloaded")
+	}
+}
+
+func TestChunkBordersStayOpen(t *testing.T) {
+	w := newChunkedWorld(t, 9)
+	m := w.Terrain
+	n := m.Params.Chunk
+	walls := 0
+	for r := range m.Params.Rows {
+		for c := range m.Params.Cols {
+			if m.At(c, r) != world.TileWall {
+				continue
+			}
+			walls++
+			if c%n == 0 || c%n == n-1 || r%n == 0 || r%n == n-1 {
+				t.Fatalf("wall on a chunk border at tile (%d, %d)", c, r)
+			}
+		}
+	}
+	if walls == 0 {
+		t.Fatal("expected walls inside the chunks")
+	}
+}
+
+func TestStragglersAreRecycled(t *testing.T) {
+	w := newChunkedWorld(t, 1)
+	w.Terrain, w.Obstacles = nil, nil
+	p := w.Player.Pos
+	w.Enemies = []world.Enemy{
+		{ID: 1, Pos: p.Add(world.Vec2{X: -1500}), R: 8, Speed: 1, HP: 10, MaxHP: 10},
+		{ID: 2, Pos: p.Add(world.Vec2{X: 300}), R: 8, Speed: 1, HP: 10, MaxHP: 10},
+	}
+	w.Tick(1.0 / 60.0)
+
+	if w.Stats.EnemiesRecycled != 1 {
+		t.Fatalf("recycled %d enemies, want 1", w.Stats.EnemiesRecycled)
+	}
+	back := w.Enemies[0].Pos.Sub(p)
+	if back.X < w.Cfg.SpawnRadius-5 || back.Len() > w.Cfg.SpawnRadius+5 {
+		t.Fatalf("straggler moved to %v from the player, want ahead on the spawn ring", back)
+	}
+	if d := w.Enemies[1].Pos.Sub(p); d.X < 290 {
+		t.Fatalf("nearby enemy was moved to %v", d)
+	}
+}
+
+func TestChunkedSnapshotRoundTrip(t *testing.T) {
+	const dt = float32(1.0 / 60.0)
+	a := newChunkedWorld(t, 4)
+	for range 120 {
+		a.Player.Pos.Y += 12
+		a.Tick(dt)
+	}
+	snap := a.BuildSnapshot()
+
+	b := world.NewWorld(1, 1)
+	defer b.Close()
+	if err := b.ApplySnapshot(snap); err != nil {
+		t.Fatalf("ApplySnapshot failed: %v", err)
+	}
+	b.TestOnlyDisableAIPool()
+	for tick := range 120 {
+		a.Player.Pos.Y += 12
+		b.Player.Pos.Y += 12
+		a.Tick(dt)
+		b.Tick(dt)
+		if a.StateHash() != b.StateHash() {
+			t.Fatalf("tick %d: restored run diverged", tick)
+		}
+	}
+	if !reflect.DeepEqual(a.Terrain, b.Terrain) || !reflect.DeepEqual(a.Obstacles, b.Obstacles) {
+		t.Fatal("restored window differs")
+	}
+}
+
+func TestLoadDropsChunksStreamedForTheOldRun(t *testing.T) {
+	const dt = float32(1.0 / 60.0)
+	far := world.Vec2{X: 10 * world.DefaultConfig().ChunkSize, Y: 1000}
+
+	// jumping far away queues a whole new ring for seed 6; load seed 7
+	// there before those results are drained
+	w := newChunkedWorld(t, 6)
+	w.Tick(dt)
+	w.Player.Pos = far
+	w.Tick(dt)
+
+	want := newChunkedWorld(t, 7)
+	want.Player.Pos = far
+	snap := want.BuildSnapshot()
+	want.TestOnlyDisableChunkStreamer()
+	if err := w.ApplySnapshot(snap); err != nil {
+		t.Fatalf("ApplySnapshot failed: %v", err)
+	}
+	time.Sleep(50 * time.Millisecond)
+
+	step := world.Vec2{X: 19, Y: 11}
+	for tick := range 240 {
+		w.Player.Pos = w.Player.Pos.Add(step)
+		want.Player.Pos = want.Player.Pos.Add(step)
+		w.Tick(dt)
+		want.Tick(dt)
+		if !reflect.DeepEqual(w.Obstacles, want.Obstacles) || !reflect.DeepEqual(w.Terrain, want.Terrain) {
+			t.Fatalf("tick %d: window holds chunks from the previous run", tick)
+		}
+		if w.StateHash() != want.StateHash() {
+			t.Fatalf("tick %d: state hash diverged", tick)
+		}
+	}
+}
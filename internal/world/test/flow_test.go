@@ -0,0 +1,66 @@
+package world_test
+
+import (
+	"testing"
+
+	"horde-lab/internal/world"
+)
+
+// runBehindWall lets one enemy chase a still player from behind a long wall
+// and returns how close it got.
+func runBehindWall(t *testing.T, flowCell float32) float32 {
+	t.Helper()
+	cfg := world.DefaultConfig()
+	cfg.ChunkSize = 0
+	cfg.FlowCellSize = flowCell
+	cfg.BaseSpawnEvery, cfg.MinSpawnEvery = 1e9, 1e9
+	cfg.PlayerMaxHP, cfg.PlayerMaxHPCap = 1e6, 1e6
+	w := world.NewWorldWithConfig(1000, 1000, cfg, 1)
+	defer w.Close()
+	w.Terrain = nil
+	w.Obstacles = []world.Obstacle{{Pos: world.Vec2{X: 420, Y: 500}, R: 160, Shape: world.ShapeRect, Half: world.Vec2{X: 8, Y: 160}}}
+	w.Player.Weapons = nil
+	w.Player.Pos = world.Vec2{X: 500, Y: 500}
+	w.Enemies = []world.Enemy{{ID: 1, Pos: world.Vec2{X: 300, Y: 500}, R: 9, Speed: 90, HP: 10, MaxHP: 10, TouchDamage: 1}}
+
+	for range 600 {
+		w.Tick(1.0 / 60.0)
+	}
+	return w.Enemies[0].Pos.Sub(w.Player.Pos).Len()
+}
+
+func TestEnemiesPathAroundWalls(t *testing.T) {
+	if d := runBehindWall(t, 0); d < 100 {
+		t.Fatalf("expected the straight chase to stall behind the wall, got within %.1f", d)
+	}
+	if d := runBehindWall(t, 40); d > 40 {
+		t.Fatalf("enemy following the flow field ended %.1f from the player", d)
+	}
+}
+
+func TestFlowFieldRunsMatchWithoutPool(t *testing.T) {
+	const dt = float32(1.0 / 60.0)
+	cfg := world.DefaultConfig()
+	cfg.BaseSpawnEvery, cfg.MinSpawnEvery = 0.1, 0.1
+	cfg.PlayerMaxHP, cfg.PlayerMaxHPCap = 1e6, 1e6
+
+	a := world.NewWorldWithConfig(2000, 2000, cfg, 7)
+	defer a.Close()
+	b := world.NewWorldWithConfig(2000, 2000, cfg, 7)
+	defer b.Close()
+
+	for tick := range 600 {
+		step := world.Vec2{X: float32(tick%200) / 40, Y: 2}
+		a.Player.Pos = a.Player.Pos.Add(step)
+		b.Player.Pos = b.Player.Pos.Add(step)
+		a.Tick(dt)
+		b.Tick(dt)
+		if a.StateHash() != b.StateHash() {
+			t.Fatalf("tick %d: runs diverged", tick)
+		}
+		if tick == 300 {
+			// the rest of b runs on the synchronous fallback
+			b.TestOnlyStallAIPool()
+		}
+	}
+}
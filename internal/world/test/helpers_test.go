@@ -0,0 +1,38 @@
+package world_test
+
+import (
+	"testing"
+
+	"horde-lab/internal/world"
+)
+
+// arena configures newArena. The zero value is a 2000x2000 world on seed 1.
+type arena struct {
+	size    float32
+	seed    int64
+	keepMap bool // keep the generated obstacles, terrain and chunk streaming
+}
+
+// newArena builds a world for focused tests: no AI pool and no spawns, the
+// player in the middle with the default weapon, and an empty bounded map
+// unless keepMap is set.
+func newArena(tb testing.TB, a arena) *world.World {
+	tb.Helper()
+	if a.size == 0 {
+		a.size = 2000
+	}
+	cfg := world.DefaultConfig()
+	if !a.keepMap {
+		cfg.ChunkSize = 0
+	}
+	w := world.NewWorldWithConfig(a.size, a.size, cfg, a.seed)
+	tb.Cleanup(w.Close)
+
+	w.TestOnlyDisableAIPool()
+	w.Cfg.BaseSpawnEvery, w.Cfg.MinSpawnEvery = 1e9, 1e9
+	if !a.keepMap {
+		w.Obstacles = nil
+		w.Terrain = nil
+	}
+	return w
+}
@@ -0,0 +1,170 @@
+package world_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"horde-lab/internal/world"
+)
+
+// The golden files capture one run after 150 ticks in every persisted layout.
+// Whatever the source version, loading must produce the current snapshot.
+
+func loadGoldenSnapshot(t *testing.T, name string) world.Snapshot {
+	t.Helper()
+	w := world.NewWorld(1, 1)
+	defer w.Close()
+	if err := w.LoadSnapshot(filepath.Join("testdata", name)); err != nil {
+		t.Fatalf("LoadSnapshot(%s) failed: %v", name, err)
+	}
+	return w.BuildSnapshot()
+}
+
+func TestGoldenSnapshotsMigrateToCurrentVersion(t *testing.T) {
+	want := loadGoldenSnapshot(t, "snapshot_v10.json")
+	if want.Version != world.SnapshotVersion {
+		t.Fatalf("current snapshot version = %d, want %d", want.Version, world.SnapshotVersion)
+	}
+	if want.AITick == 0 || want.RNG.Inc == 0 || len(want.Cfg.Enemies) == 0 {
+		t.Fatalf("unexpected v10 golden contents: ai_tick=%d rng=%+v enemies=%d", want.AITick, want.RNG, len(want.Cfg.Enemies))
+	}
+
+	for _, name := range []string{"snapshot_v1.json", "snapshot_v2.json", "snapshot_v3.json", "snapshot_v4.json", "snapshot_v5.json", "snapshot_v6.json", "snapshot_v7.json", "snapshot_v8.json", "snapshot_v9.json"} {
+		got := loadGoldenSnapshot(t, name)
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("%s migrated differently\n got: %#v\nwant: %#v", name, got, want)
+		}
+	}
+}
+
+func TestGoldenReplayV1MigratesHeaderAndInitialSnapshot(t *testing.T) {
+	rep, err := world.LoadReplayFile(filepath.Join("testdata", "replay_v1.json"))
+	if err != nil {
+		t.Fatalf("LoadReplayFile failed: %v", err)
+	}
+	if rep.Header.Version != world.ReplayVersion {
+		t.Fatalf("replay version = %d, want %d", rep.Header.Version, world.ReplayVersion)
+	}
+	if len(rep.Frames) != 2 || !rep.Frames[0].Input.Right || rep.Frames[1].Choose != 1 {
+		t.Fatalf("unexpected replay frames: %#v", rep.Frames)
+	}
+
+	w := world.NewWorld(1, 1)
+	defer w.Close()
+	if err := w.ApplySnapshot(rep.Initial); err != nil {
+		t.Fatalf("ApplySnapshot(replay initial) failed: %v", err)
+	}
+	want := loadGoldenSnapshot(t, "snapshot_v10.json")
+	if got := w.BuildSnapshot(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("replay initial snapshot mismatch\n got: %#v\nwant: %#v", got, want)
+	}
+}
+
+func TestSnapshotMigrationsIgnoreLiveContent(t *testing.T) {
+	blob, err := os.ReadFile(filepath.Join("testdata", "snapshot_v1.json"))
+	if err != nil {
+		t.Fatalf("read golden: %v", err)
+	}
+	want, err := world.SnapshotMigrations.MigrateJSON(blob)
+	if err != nil {
+		t.Fatalf("MigrateJSON failed: %v", err)
+	}
+
+	// a later release retunes every archetype and drops a pickup
+	enemies := world.DefaultEnemyContent()
+	for i := range enemies.Enemies {
+		a := &enemies.Enemies[i]
+		a.Name += " Mk II"
+		a.DropChance *= 2
+		a.Spawn.Base++
+	}
+	t.Cleanup(world.TestOnlySetDefaultEnemyContent(enemies))
+	pickups := world.DefaultPickupContent()
+	pickups.Pickups = pickups.Pickups[1:]
+	t.Cleanup(world.TestOnlySetDefaultPickupContent(pickups))
+
+	got, err := world.SnapshotMigrations.MigrateJSON(blob)
+	if err != nil {
+		t.Fatalf("MigrateJSON with altered content failed: %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Fatalf("snapshot_v1 migrated differently after the content changed\n got: %s\nwant: %s", got, want)
+	}
+}
+
+func TestSnapshotV4MigrationDerivesPassivesFromUpgradedStats(t *testing.T) {
+	doc := `{"version":4,"cfg":{"PlayerDamage":25,"PlayerAttackCooldown":0.45},
+		"player":{"Damage":45,"AttackCooldown":0.325125,"XPMagnet":10}}`
+	blob, err := world.SnapshotMigrations.MigrateJSON([]byte(doc))
+	if err != nil {
+		t.Fatalf("MigrateJSON failed: %v", err)
+	}
+	var s world.Snapshot
+	if err := json.Unmarshal(blob, &s); err != nil {
+		t.Fatalf("decode migrated snapshot: %v", err)
+	}
+	want := []world.PassiveSlot{{Kind: world.PassiveMight, Level: 2}, {Kind: world.PassiveHaste, Level: 2}}
+	if !reflect.DeepEqual(s.Player.Passives, want) {
+		t.Fatalf("passives = %+v, want %+v", s.Player.Passives, want)
+	}
+}
+
+func TestSnapshotV5MigrationRecoversBaseStats(t *testing.T) {
+	doc := `{"version":5,"player":{"Damage":45,"AttackCooldown":0.325125,"XPMagnet":25,"MaxHP":130,"Speed":260,"AttackRange":180,
+		"Passives":[{"Kind":0,"Level":2},{"Kind":1,"Level":2},{"Kind":2,"Level":1}]}}`
+	blob, err := world.SnapshotMigrations.MigrateJSON([]byte(doc))
+	if err != nil {
+		t.Fatalf("MigrateJSON failed: %v", err)
+	}
+	var s world.Snapshot
+	if err := json.Unmarshal(blob, &s); err != nil {
+		t.Fatalf("decode migrated snapshot: %v", err)
+	}
+	want := world.PlayerStats{MaxHP: 130, Speed: 260, Damage: 25, AttackCooldown: 0.45, AttackRange: 180, XPMagnet: 10, Area: 1, Luck: 1}
+	if !approxEqual(s.Player.Base.AttackCooldown, want.AttackCooldown) {
+		t.Fatalf("base cooldown = %v, want %v", s.Player.Base.AttackCooldown, want.AttackCooldown)
+	}
+	s.Player.Base.AttackCooldown = want.AttackCooldown
+	if s.Player.Base != want {
+		t.Fatalf("base = %+v, want %+v", s.Player.Base, want)
+	}
+}
+
+func TestSnapshotV6MigrationAddsPickupsAndMenuCharges(t *testing.T) {
+	migrated := func(doc string) world.Snapshot {
+		t.Helper()
+		blob, err := world.SnapshotMigrations.MigrateJSON([]byte(doc))
+		if err != nil {
+			t.Fatalf("MigrateJSON failed: %v", err)
+		}
+		var s world.Snapshot
+		if err := json.Unmarshal(blob, &s); err != nil {
+			t.Fatalf("decode migrated snapshot: %v", err)
+		}
+		return s
+	}
+
+	s := migrated(`{"version":6,"cfg":{},"upgrade":{"Active":true,"Pending":1}}`)
+	var kinds []world.PickupKind
+	for _, d := range s.Cfg.Pickups {
+		kinds = append(kinds, d.Kind)
+	}
+	want := []world.PickupKind{world.PickupChest, world.PickupFood, world.PickupVacuum, world.PickupBomb, world.PickupGold}
+	if !reflect.DeepEqual(kinds, want) {
+		t.Fatalf("pickup kinds = %v, want the v7 content %v", kinds, want)
+	}
+	if s.Cfg.UpgradeChoices != 3 || s.Upgrade.Rerolls != 2 || s.Upgrade.Skips != 2 || s.Upgrade.Banishes != 2 {
+		t.Fatalf("expected the v7 menu config and charges, got %+v", s.Upgrade)
+	}
+
+	// runs saved with the menu config keep the charges they had left
+	s = migrated(`{"version":6,"cfg":{"UpgradeChoices":3,"UpgradeRerolls":2,"UpgradeSkips":2,"UpgradeBanishes":2},
+		"upgrade":{"Rerolls":0,"Skips":1,"Banishes":2}}`)
+	if s.Upgrade.Rerolls != 0 || s.Upgrade.Skips != 1 {
+		t.Fatalf("charges overwritten: %+v", s.Upgrade)
+	}
+}
@@ -0,0 +1,220 @@
+package world_test
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+	"testing/quick"
+
+	"horde-lab/internal/shared/input"
+	"horde-lab/internal/world"
+)
+
+// contactEps absorbs the float32 error of landing exactly on a surface.
+const contactEps = 0.05
+
+func newObstacleWorld(tb testing.TB, obstacles ...world.Obstacle) *world.World {
+	tb.Helper()
+	w := newArena(tb, arena{size: 1000})
+	w.Obstacles = obstacles
+	return w
+}
+
+// randomObstacle builds a circle, rect or convex polygon of bounding radius
+// r centered on pos.
+func randomObstacle(rng *rand.Rand, pos world.Vec2) world.Obstacle {
+	r := 10 + rng.Float32()*70
+	o := world.Obstacle{Pos: pos, R: r}
+	switch rng.Intn(3) {
+	case 1:
+		a := 0.1 + rng.Float64()*1.37
+		o.Shape = world.ShapeRect
+		o.Half = world.Vec2{X: r * float32(math.Cos(a)), Y: r * float32(math.Sin(a))}
+	case 2:
+		n := 3 + rng.Intn(6)
+		start := rng.Float64() * 2 * math.Pi
+		o.Shape = world.ShapePolygon
+		for i := range n {
+			a := start + 2*math.Pi*(float64(i)+(rng.Float64()-0.5)*0.6)/float64(n)
+			o.Points = append(o.Points, world.Vec2{X: r * float32(math.Cos(a)), Y: r * float32(math.Sin(a))})
+		}
+	}
+	return o
+}
+
+func randomVec(rng *rand.Rand, length float32) world.Vec2 {
+	a := rng.Float64() * 2 * math.Pi
+	l := rng.Float32() * length
+	return world.Vec2{X: l * float32(math.Cos(a)), Y: l * float32(math.Sin(a))}
+}
+
+func TestMoveEntityNeverEndsInsideObstacle(t *testing.T) {
+	center := world.Vec2{X: 500, Y: 500}
+	prop := func(seed int64) bool {
+		rng := rand.New(rand.NewSource(seed))
+		o := randomObstacle(rng, center)
+		w := newObstacleWorld(t, o)
+		r := 4 + rng.Float32()*16
+		clear := r + w.Cfg.ObstaclePadding
+
+		from := center.Add(randomVec(rng, 250))
+		if d, _ := o.Distance(from); d < clear {
+			return true // movers never start inside
+		}
+		// long steps would tunnel through without the sweep
+		to := from.Add(randomVec(rng, 300))
+		got := w.TestOnlyMoveEntity(from, to, r)
+		if d, _ := o.Distance(got); d < clear-contactEps {
+			t.Logf("seed %d: %+v moved %v -> %v ended %.3f from the surface, want >= %.3f", seed, o, from, to, d, clear)
+			return false
+		}
+		return true
+	}
+	if err := quick.Check(prop, &quick.Config{MaxCount: 3000}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMoveEntityDoesNotTunnelThinWalls(t *testing.T) {
+	wall := world.Obstacle{Pos: world.Vec2{X: 500, Y: 500}, R: 60, Shape: world.ShapeRect, Half: world.Vec2{X: 1, Y: 60}}
+	w := newObstacleWorld(t, wall)
+
+	got := w.TestOnlyMoveEntity(world.Vec2{X: 400, Y: 500}, world.Vec2{X: 700, Y: 520}, 8)
+	if got.X >= wall.Pos.X {
+		t.Fatalf("mover tunnelled through the wall to %v", got)
+	}
+	if got.Y <= 500 {
+		t.Fatalf("expected the blocked step to slide along the wall, got %v", got)
+	}
+}
+
+func TestLineOfSightMatchesSampledSegment(t *testing.T) {
+	center := world.Vec2{X: 500, Y: 500}
+	prop := func(seed int64) bool {
+		rng := rand.New(rand.NewSource(seed))
+		o := randomObstacle(rng, center)
+		w := newObstacleWorld(t, o)
+		a := center.Add(randomVec(rng, 200))
+		b := center.Add(randomVec(rng, 200))
+
+		blocked, grazes := false, false
+		for k := range 257 {
+			p := a.Add(b.Sub(a).Mul(float32(k) / 256))
+			d, _ := o.Distance(p)
+			blocked = blocked || d < -0.5
+			grazes = grazes || d < 0.5
+		}
+		los := w.TestOnlyLineOfSight(a, b)
+		if los != w.TestOnlyLineOfSight(b, a) && !grazes {
+			t.Logf("seed %d: line of sight %v -> %v is not symmetric", seed, a, b)
+			return false
+		}
+		if los && blocked {
+			t.Logf("seed %d: %v -> %v passes through %+v", seed, a, b, o)
+			return false
+		}
+		if !los && !grazes {
+			t.Logf("seed %d: %v -> %v blocked though it clears %+v", seed, a, b, o)
+			return false
+		}
+		return true
+	}
+	if err := quick.Check(prop, &quick.Config{MaxCount: 3000}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWhipDoesNotHitThroughWalls(t *testing.T) {
+	w := newCombatWorld(t, world.WeaponSlot{Kind: world.WeaponWhip, Level: 1})
+	w.Obstacles = []world.Obstacle{{Pos: world.Vec2{X: 1030, Y: 1000}, R: 30, Shape: world.ShapeRect, Half: world.Vec2{X: 3, Y: 30}}}
+	w.Enemies = []world.Enemy{
+		{ID: 1, Pos: world.Vec2{X: 1060, Y: 1000}, R: 9, HP: 500, MaxHP: 500},
+		{ID: 2, Pos: world.Vec2{X: 1000, Y: 1080}, R: 9, HP: 500, MaxHP: 500},
+	}
+	w.Tick(1.0 / 60.0)
+
+	if w.Enemies[0].HP != 500 {
+		t.Fatalf("whip hit the enemy behind the wall: HP = %.2f", w.Enemies[0].HP)
+	}
+	if w.Enemies[1].HP >= 500 {
+		t.Fatal("expected the whip to hit the farther, visible enemy")
+	}
+}
+
+func TestRunnerHoldsFireWithoutLineOfSight(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		walls []world.Obstacle
+		shots int
+	}{
+		{name: "open", shots: 1},
+		{name: "walled", walls: []world.Obstacle{{Pos: world.Vec2{X: 1050, Y: 1000}, R: 40, Shape: world.ShapeRect, Half: world.Vec2{X: 4, Y: 40}}}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			w := newCombatWorld(t, world.WeaponSlot{Kind: world.WeaponNova, Level: 1})
+			w.Obstacles = tc.walls
+			w.Enemies = []world.Enemy{{ID: 1, Kind: world.EnemyRunner, Pos: world.Vec2{X: 1100, Y: 1000}, R: 7, HP: 1e6, MaxHP: 1e6}}
+			w.Tick(1.0 / 60.0)
+			if len(w.Shots) != tc.shots {
+				t.Fatalf("runner fired %d shots, want %d", len(w.Shots), tc.shots)
+			}
+		})
+	}
+}
+
+func TestNothingEndsInsideObstacles(t *testing.T) {
+	const dt = float32(1.0 / 60.0)
+	cfg := world.DefaultConfig()
+	cfg.ObstacleCount = 40
+	cfg.ObstacleRectChance, cfg.ObstaclePolyChance = 0.45, 0.45
+	cfg.BaseSpawnEvery, cfg.MinSpawnEvery = 0.2, 0.2
+	cfg.PlayerMaxHP, cfg.PlayerMaxHPCap = 1e6, 1e6
+
+	for seed := int64(1); seed <= 3; seed++ {
+		w := world.NewWorldWithConfig(2000, 2000, cfg, seed)
+		w.TestOnlyDisableAIPool()
+		w.Player.Weapons = []world.WeaponSlot{
+			{Kind: world.WeaponWhip, Level: 1},
+			{Kind: world.WeaponNova, Level: 1},
+		}
+		rng := rand.New(rand.NewSource(seed))
+		var in input.State
+		for tick := range 1200 {
+			if tick%30 == 0 {
+				in = input.State{Up: rng.Intn(2) == 0, Down: rng.Intn(2) == 0, Left: rng.Intn(2) == 0, Right: rng.Intn(2) == 0}
+			}
+			w.Enqueue(world.MsgInput{Input: in})
+			w.Tick(dt)
+			if w.Upgrade.Active {
+				w.Enqueue(world.MsgChooseUpgrade{Choice: 0})
+			}
+
+			pad := w.Cfg.ObstaclePadding
+			for i := range w.Obstacles {
+				o := &w.Obstacles[i]
+				if d, _ := o.Distance(w.Player.Pos); d < w.Player.R+pad-contactEps {
+					t.Fatalf("seed %d tick %d: player %.3f from %+v", seed, tick, d, *o)
+				}
+				for _, e := range w.Enemies {
+					if d, _ := o.Distance(e.Pos); d < e.R+pad-contactEps {
+						t.Fatalf("seed %d tick %d: enemy %d %.3f from %+v", seed, tick, e.ID, d, *o)
+					}
+				}
+				for _, s := range w.Shots {
+					if d, _ := o.Distance(s.Pos); d < s.R+pad {
+						t.Fatalf("seed %d tick %d: enemy shot %.3f from %+v", seed, tick, d, *o)
+					}
+				}
+				for _, s := range w.PlayerShots {
+					if s.Style != world.AttackProjectile {
+						continue // orbiters and returning boomerangs pass over obstacles
+					}
+					if d, _ := o.Distance(s.Pos); d < s.R-contactEps {
+						t.Fatalf("seed %d tick %d: weapon %d shot %.3f from %+v", seed, tick, s.Weapon, d, *o)
+					}
+				}
+			}
+		}
+		w.Close()
+	}
+}
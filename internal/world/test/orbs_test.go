@@ -0,0 +1,132 @@
+package world_test
+
+import (
+	"math"
+	"testing"
+
+	"horde-lab/internal/world"
+)
+
+func newOrbWorld(tb testing.TB) *world.World {
+	tb.Helper()
+	w := newArena(tb, arena{size: 4000})
+	w.Player.Weapons = nil
+	return w
+}
+
+func orbValue(orbs []world.XPOrb) float64 {
+	var v float64
+	for _, o := range orbs {
+		v += float64(o.Value)
+	}
+	return v
+}
+
+func TestXPOrbsAccelerateTowardPlayer(t *testing.T) {
+	w := newOrbWorld(t)
+	// magnet radius is 10 + 10 + 10, plus the orb radius
+	w.Orbs = []world.XPOrb{
+		{Pos: world.Vec2{X: 2035, Y: 2000}, R: 6, Value: 1},
+		{Pos: world.Vec2{X: 2200, Y: 2000}, R: 6, Value: 1},
+	}
+
+	w.Tick(1.0 / 60.0)
+	pulled, resting := w.Orbs[0], w.Orbs[1]
+	if pulled.Speed <= 0 || pulled.Pos.X >= 2035 {
+		t.Fatalf("expected the near orb to start moving, got %+v", pulled)
+	}
+	if resting.Speed != 0 || resting.Pos.X != 2200 {
+		t.Fatalf("expected the far orb to rest, got %+v", resting)
+	}
+
+	w.Tick(1.0 / 60.0)
+	if w.Orbs[0].Speed <= pulled.Speed {
+		t.Fatalf("expected the pull to accelerate: %.1f -> %.1f", pulled.Speed, w.Orbs[0].Speed)
+	}
+
+	for range 30 {
+		w.Tick(1.0 / 60.0)
+	}
+	if len(w.Orbs) != 1 || w.Player.XP != 1 {
+		t.Fatalf("expected the pulled orb collected, orbs %+v XP %.1f", w.Orbs, w.Player.XP)
+	}
+}
+
+func TestXPOrbsWithoutAccelCollectInstantly(t *testing.T) {
+	w := newOrbWorld(t)
+	w.Cfg.XPOrbAccel = 0
+	w.Orbs = []world.XPOrb{{Pos: world.Vec2{X: 2035, Y: 2000}, R: 6, Value: 2}}
+	w.Tick(1.0 / 60.0)
+	if len(w.Orbs) != 0 || w.Player.XP != 2 {
+		t.Fatalf("expected an instant pickup, orbs %+v XP %.1f", w.Orbs, w.Player.XP)
+	}
+}
+
+func TestXPOrbsMergeAboveCap(t *testing.T) {
+	w := newOrbWorld(t)
+	w.Cfg.XPOrbCap = 14
+	// 8 clusters of 5 close orbs, then 6 lone orbs far apart
+	for c := range 8 {
+		for k := range 5 {
+			w.Orbs = append(w.Orbs, world.XPOrb{
+				Pos:   world.Vec2{X: 400 + float32(c)*300 + float32(k)*4, Y: 400},
+				R:     6,
+				Value: 1,
+			})
+		}
+	}
+	for k := range 6 {
+		w.Orbs = append(w.Orbs, world.XPOrb{Pos: world.Vec2{X: 400 + float32(k)*500, Y: 3600}, R: 6, Value: 2})
+	}
+	want := orbValue(w.Orbs)
+
+	w.Tick(1.0 / 60.0)
+
+	if len(w.Orbs) != 14 {
+		t.Fatalf("orb count %d, want the cap of 14", len(w.Orbs))
+	}
+	if got := orbValue(w.Orbs); got != want {
+		t.Fatalf("merging changed the XP on the ground: %.0f -> %.0f", want, got)
+	}
+	for _, o := range w.Orbs[:8] {
+		if o.Value != 5 {
+			t.Fatalf("expected each cluster merged into a 5 XP gem, got %+v", w.Orbs[:8])
+		}
+	}
+}
+
+// floodOrbs drops orbs around the map every tick, the way kills all over a
+// crowded late game do, and returns the largest orb count seen.
+func floodOrbs(w *world.World, ticks int) int {
+	most := 0
+	for i := range ticks {
+		for k := range 6 {
+			ang := float64(i*6+k) * 2.399963
+			r := 200 + float64((i*37+k*101)%1700)
+			w.Orbs = append(w.Orbs, world.XPOrb{
+				Pos:   world.Vec2{X: 2000 + float32(math.Cos(ang)*r), Y: 2000 + float32(math.Sin(ang)*r)},
+				R:     6,
+				Value: 1,
+			})
+		}
+		w.Tick(1.0 / 60.0)
+		most = max(most, len(w.Orbs))
+	}
+	return most
+}
+
+// BenchmarkTickOrbFlood runs a long headless flood of orb drops and reports
+// the peak orb count, which the cap keeps bounded however long it runs.
+func BenchmarkTickOrbFlood(b *testing.B) {
+	w := newOrbWorld(b)
+	w.Player.HP, w.Player.Base.MaxHP = 1e9, 1e9
+	// keep the level-up menu from pausing the run
+	w.Player.XPToNext = 1e12
+	b.ResetTimer()
+	most := floodOrbs(w, b.N+36000)
+	b.StopTimer()
+	b.ReportMetric(float64(most), "max_orbs")
+	if most > w.Cfg.XPOrbCap {
+		b.Fatalf("orb count reached %d, cap %d", most, w.Cfg.XPOrbCap)
+	}
+}
@@ -0,0 +1,207 @@
+package world_test
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"horde-lab/internal/world"
+)
+
+func newPickupWorld(t *testing.T) *world.World {
+	t.Helper()
+	return newCombatWorld(t, world.WeaponSlot{Kind: world.WeaponWhip, Level: 1})
+}
+
+// pickupAt places a pickup of kind at an offset from the player.
+func pickupAt(w *world.World, kind world.PickupKind, dx float32) {
+	def, _ := w.Cfg.Pickup(kind)
+	w.Pickups = append(w.Pickups, world.Pickup{
+		Pos:  world.Vec2{X: w.Player.Pos.X + dx, Y: w.Player.Pos.Y},
+		R:    def.Radius,
+		Kind: kind,
+	})
+}
+
+func upgradeLevels(p world.Player) int {
+	n := 0
+	for _, s := range p.Passives {
+		n += s.Level
+	}
+	for _, s := range p.Weapons {
+		n += s.Level
+	}
+	return n
+}
+
+func TestBossDropsChestThatGrantsUpgrades(t *testing.T) {
+	w := newBossWorld(t)
+	w.Player.Weapons = []world.WeaponSlot{{Kind: world.WeaponWhip, Level: 1}}
+	boss := bossEnemy(t, w)
+	boss.Pos = world.Vec2{X: 1050, Y: 1000}
+	boss.HP = 1
+	w.Tick(1.0 / 60.0)
+
+	chests := 0
+	for _, p := range w.Pickups {
+		if p.Kind == world.PickupChest {
+			chests++
+		}
+	}
+	if chests != 1 {
+		t.Fatalf("expected the boss to drop a chest, pickups %+v", w.Pickups)
+	}
+
+	w = newPickupWorld(t)
+	chest, _ := w.Cfg.Pickup(world.PickupChest)
+	chest.Grants = []world.ChestGrant{{Count: 3, Weight: 1}}
+	before := upgradeLevels(w.Player)
+	pickupAt(w, world.PickupChest, 20)
+	w.Tick(1.0 / 60.0)
+	if len(w.Pickups) != 0 {
+		t.Fatal("expected the chest to be collected")
+	}
+	if got := upgradeLevels(w.Player) - before; got != 3 {
+		t.Fatalf("chest granted %d upgrades, want 3", got)
+	}
+	if w.Upgrade.Active {
+		t.Fatal("chest upgrades must not open the level-up menu")
+	}
+}
+
+func TestPickupEffects(t *testing.T) {
+	w := newPickupWorld(t)
+	w.Player.HP = 20
+	pickupAt(w, world.PickupFood, 0)
+	pickupAt(w, world.PickupGold, 0)
+	w.Tick(1.0 / 60.0)
+	if w.Player.HP < 50 || w.Player.Gold != 1 {
+		t.Fatalf("food and gold: HP %.1f gold %d", w.Player.HP, w.Player.Gold)
+	}
+
+	w.Orbs = []world.XPOrb{
+		{Pos: world.Vec2{X: 100, Y: 100}, R: 6, Value: 3},
+		{Pos: world.Vec2{X: 1900, Y: 1900}, R: 6, Value: 4},
+	}
+	xp := w.Player.XP
+	pickupAt(w, world.PickupVacuum, 0)
+	w.Tick(1.0 / 60.0)
+	if len(w.Orbs) != 0 || w.Player.XP != xp+7 {
+		t.Fatalf("vacuum left %d orbs, XP %.1f", len(w.Orbs), w.Player.XP)
+	}
+
+	w.Enemies = []world.Enemy{
+		{ID: 1, Pos: world.Vec2{X: 1200, Y: 1000}, R: 9, HP: 500, MaxHP: 500},
+		{ID: 2, Pos: world.Vec2{X: 1000, Y: 1400}, R: 9, HP: 500, MaxHP: 500},
+	}
+	pickupAt(w, world.PickupBomb, 0)
+	w.Tick(1.0 / 60.0)
+	if len(w.Enemies) != 1 || w.Enemies[0].ID != 2 {
+		t.Fatalf("bomb should clear only the nearby enemy, left %+v", w.Enemies)
+	}
+}
+
+func TestFrenzyBuffRefreshesAndExpires(t *testing.T) {
+	w := newPickupWorld(t)
+	def, _ := w.Cfg.Pickup(world.PickupFrenzy)
+	def.Duration = 1
+	w.Tick(1.0 / 60.0)
+	base := w.Player.AttackCooldown
+
+	pickupAt(w, world.PickupFrenzy, 0)
+	w.Tick(1.0 / 60.0)
+	if !approxEqual(w.Player.AttackCooldown, base*def.Buff.Mul) || len(w.Player.Buffs) != 1 {
+		t.Fatalf("cooldown %.3f with buffs %+v, want %.3f", w.Player.AttackCooldown, w.Player.Buffs, base*def.Buff.Mul)
+	}
+
+	// a second draught restarts the timer instead of stacking
+	for range 30 {
+		w.Tick(1.0 / 60.0)
+	}
+	pickupAt(w, world.PickupFrenzy, 0)
+	w.Tick(1.0 / 60.0)
+	if len(w.Player.Buffs) != 1 || !approxEqual(w.Player.AttackCooldown, base*def.Buff.Mul) {
+		t.Fatalf("second frenzy stacked: cooldown %.3f, buffs %+v", w.Player.AttackCooldown, w.Player.Buffs)
+	}
+	for range 50 {
+		w.Tick(1.0 / 60.0)
+	}
+	if len(w.Player.Buffs) != 1 {
+		t.Fatal("expected the refreshed frenzy to outlast the first one")
+	}
+
+	// a shorter draught restarts the timer too, cutting the running one short
+	def.Duration = 0.1
+	pickupAt(w, world.PickupFrenzy, 0)
+	w.Tick(1.0 / 60.0)
+	if len(w.Player.Buffs) != 1 || w.Player.Buffs[0].Remaining > def.Duration {
+		t.Fatalf("buffs %+v after a %.2fs frenzy, want the timer restarted", w.Player.Buffs, def.Duration)
+	}
+	for range 10 {
+		w.Tick(1.0 / 60.0)
+	}
+	if len(w.Player.Buffs) != 0 || w.Player.AttackCooldown != base {
+		t.Fatalf("cooldown %.3f with buffs %+v after expiry, want %.3f", w.Player.AttackCooldown, w.Player.Buffs, base)
+	}
+}
+
+func TestPickupsRespectMagnetAndExpire(t *testing.T) {
+	w := newPickupWorld(t)
+	pickupAt(w, world.PickupGold, 60)
+	w.Tick(1.0 / 60.0)
+	if len(w.Pickups) != 1 {
+		t.Fatal("gold collected outside the pickup radius")
+	}
+	w.Player.Base.XPMagnet = 50
+	w.Tick(1.0 / 60.0)
+	if len(w.Pickups) != 0 || w.Player.Gold != 1 {
+		t.Fatal("expected a bigger magnet to collect the gold")
+	}
+
+	pickupAt(w, world.PickupFood, 500)
+	w.Pickups[0].Life = 0.5
+	for range 31 {
+		w.Tick(1.0 / 60.0)
+	}
+	if len(w.Pickups) != 0 {
+		t.Fatal("expected the food to expire")
+	}
+}
+
+func TestPickupsSurviveSaveLoad(t *testing.T) {
+	w := newPickupWorld(t)
+	pickupAt(w, world.PickupChest, 300)
+	pickupAt(w, world.PickupBomb, -300)
+	w.Pickups[1].Life = 12
+
+	path := filepath.Join(t.TempDir(), "pickups.json")
+	if err := w.SaveSnapshot(path); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	b := newPickupWorld(t)
+	if err := b.LoadSnapshot(path); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if !reflect.DeepEqual(b.Pickups, w.Pickups) || !reflect.DeepEqual(b.Cfg.Pickups, w.Cfg.Pickups) {
+		t.Fatalf("pickups %+v, want %+v", b.Pickups, w.Pickups)
+	}
+}
+
+func TestParsePickupContentRejectsBadDefs(t *testing.T) {
+	cases := map[string]string{
+		"kind":      `{"pickups":[{"kind":"anvil","radius":5}]}`,
+		"duplicate": `{"pickups":[{"kind":"gold","radius":5,"value":1},{"kind":"gold","radius":5,"value":1}]}`,
+		"radius":    `{"pickups":[{"kind":"gold","value":1}]}`,
+		"bomb":      `{"pickups":[{"kind":"bomb","radius":5,"value":10}]}`,
+		"chest":     `{"pickups":[{"kind":"chest","radius":5}]}`,
+		"grant":     `{"pickups":[{"kind":"chest","radius":5,"grants":[{"count":0,"weight":1}]}]}`,
+		"chance":    `{"pickups":[{"kind":"food","radius":5,"value":5,"chance":-1}]}`,
+		"frenzy":    `{"pickups":[{"kind":"frenzy","radius":5,"duration":5}]}`,
+		"buff stat": `{"pickups":[{"kind":"frenzy","radius":5,"duration":5,"buff":{"stat":"charm","add":1}}]}`,
+	}
+	for name, doc := range cases {
+		if _, err := world.ParsePickupContent([]byte(doc)); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package world_test
+
+import (
+	"testing"
+
+	"horde-lab/internal/world"
+)
+
+func enemyHP(w *world.World, id int) float32 {
+	for _, e := range w.Enemies {
+		if e.ID == id {
+			return e.HP
+		}
+	}
+	return 0
+}
+
+// fireOnce ticks until the slot's projectiles are gone, holding the weapon
+// on cooldown after the first volley.
+func fireOnce(t *testing.T, w *world.World, maxTicks int) {
+	t.Helper()
+	w.Tick(1.0 / 60.0)
+	if len(w.PlayerShots) == 0 {
+		t.Fatalf("expected the weapon to fire")
+	}
+	for range maxTicks {
+		w.Player.Weapons[0].Timer = 10
+		w.Tick(1.0 / 60.0)
+		if len(w.PlayerShots) == 0 {
+			return
+		}
+	}
+	t.Fatalf("projectiles still alive after %d ticks", maxTicks)
+}
+
+func TestBoltPierceAndBounce(t *testing.T) {
+	cases := []struct {
+		name  string
+		level int
+		hit   []bool
+	}{
+		// no pierce: hit the first enemy, bounce once to the next one
+		{name: "level 1", level: 1, hit: []bool{true, true, false}},
+		// pierce 1 passes through two enemies, then bounces to the third
+		{name: "level 4", level: 4, hit: []bool{true, true, true}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := newCombatWorld(t, world.WeaponSlot{Kind: world.WeaponBolt, Level: tc.level})
+			w.Enemies = []world.Enemy{
+				{ID: 1, Pos: world.Vec2{X: 1100, Y: 1000}, R: 9, HP: 500, MaxHP: 500},
+				{ID: 2, Pos: world.Vec2{X: 1150, Y: 1000}, R: 9, HP: 500, MaxHP: 500},
+				{ID: 3, Pos: world.Vec2{X: 1200, Y: 1000}, R: 9, HP: 500, MaxHP: 500},
+			}
+
+			fireOnce(t, w, 120)
+
+			for i, want := range tc.hit {
+				if got := enemyHP(w, i+1) < 500; got != want {
+					t.Fatalf("enemy %d hit = %v, want %v (HP %.2f)", i+1, got, want, enemyHP(w, i+1))
+				}
+			}
+		})
+	}
+}
+
+func TestBoltBouncesOffObstacles(t *testing.T) {
+	w := newCombatWorld(t, world.WeaponSlot{Kind: world.WeaponBolt, Level: 1})
+	w.Obstacles = []world.Obstacle{{Pos: world.Vec2{X: 1060, Y: 1000}, R: 20}}
+	w.Enemies = []world.Enemy{
+		{ID: 1, Pos: world.Vec2{X: 1150, Y: 1000}, R: 9, HP: 500, MaxHP: 500},
+	}
+
+	w.Tick(1.0 / 60.0)
+	for range 8 {
+		w.Tick(1.0 / 60.0)
+	}
+	if len(w.PlayerShots) != 1 {
+		t.Fatalf("expected the bolt to survive one bounce, got %d shots", len(w.PlayerShots))
+	}
+	if s := w.PlayerShots[0]; s.Bounces != 0 || s.Vel.X >= 0 {
+		t.Fatalf("expected a reflected bolt with no bounces left, got %+v", s)
+	}
+}
+
+func TestOrbitersCirclePlayer(t *testing.T) {
+	w := newCombatWorld(t, world.WeaponSlot{Kind: world.WeaponOrbit, Level: 1})
+	w.Enemies = []world.Enemy{
+		{ID: 1, Pos: world.Vec2{X: 1070, Y: 1000}, R: 9, HP: 500, MaxHP: 500},
+	}
+
+	w.Tick(1.0 / 60.0)
+	if len(w.PlayerShots) != 2 {
+		t.Fatalf("expected 2 orbiters, got %d", len(w.PlayerShots))
+	}
+	if enemyHP(w, 1) >= 500 {
+		t.Fatalf("expected the orbiter spawned on the enemy to hit it")
+	}
+
+	w.Player.Pos = world.Vec2{X: 1300, Y: 1300}
+	w.Tick(1.0 / 60.0)
+	for _, s := range w.PlayerShots {
+		if d := s.Pos.Sub(w.Player.Pos).Len(); !approxEqual(d, 70) {
+			t.Fatalf("orbiter %.2f from player, want 70", d)
+		}
+	}
+}
+
+func TestSickleHitsOnTheWayOutAndBack(t *testing.T) {
+	w := newCombatWorld(t, world.WeaponSlot{Kind: world.WeaponSickle, Level: 1})
+	w.Enemies = []world.Enemy{
+		{ID: 1, Pos: world.Vec2{X: 1100, Y: 1000}, R: 9, HP: 500, MaxHP: 500},
+	}
+
+	fireOnce(t, w, 300)
+
+	dmg := 25 * float32(1.10)
+	if want := 500 - 2*dmg; !approxEqual(enemyHP(w, 1), want) {
+		t.Fatalf("enemy HP = %.2f, want %.2f (two hits)", enemyHP(w, 1), want)
+	}
+}
+
+func TestPlayerShotsSurviveSnapshot(t *testing.T) {
+	weapons := []world.WeaponSlot{
+		{Kind: world.WeaponBolt, Level: 4},
+		{Kind: world.WeaponOrbit, Level: 3},
+		{Kind: world.WeaponSickle, Level: 3},
+	}
+	a := newCombatWorld(t, weapons...)
+	a.Enemies = []world.Enemy{
+		{ID: 1, Pos: world.Vec2{X: 1080, Y: 1000}, R: 9, HP: 900, MaxHP: 900},
+		{ID: 2, Pos: world.Vec2{X: 1000, Y: 1120}, R: 9, HP: 900, MaxHP: 900},
+		{ID: 3, Pos: world.Vec2{X: 900, Y: 950}, R: 9, HP: 900, MaxHP: 900},
+	}
+	for range 10 {
+		a.Tick(1.0 / 60.0)
+	}
+	if len(a.PlayerShots) == 0 {
+		t.Fatalf("expected projectiles in flight")
+	}
+
+	// restore both, so they also share the AI pool state a restore sets up
+	snap := a.BuildSnapshot()
+	b := newCombatWorld(t)
+	for _, w := range []*world.World{a, b} {
+		if err := w.ApplySnapshot(snap); err != nil {
+			t.Fatalf("apply snapshot: %v", err)
+		}
+	}
+	for i := range 60 {
+		a.Tick(1.0 / 60.0)
+		b.Tick(1.0 / 60.0)
+		if a.StateHash() != b.StateHash() {
+			t.Fatalf("state diverged %d ticks after restore", i+1)
+		}
+	}
+}
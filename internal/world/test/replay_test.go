@@ -1,6 +1,10 @@
 package world_test
 
 import (
+	"encoding/binary"
+	"encoding/json"
+	"math"
+	"os"
 	"path/filepath"
 	"reflect"
 	"testing"
@@ -141,3 +145,171 @@ func deterministicReplayFrames() []world.ReplayFrame {
 
 	return frames
 }
+
+func TestReplayCodecsRoundTripToIdenticalFiles(t *testing.T) {
+	w := newSnapshotFixtureWorld()
+	defer w.Close()
+
+	initial := w.BuildSnapshot()
+	header, err := world.BuildReplayHeader(initial, 1.0/60.0)
+	if err != nil {
+		t.Fatalf("BuildReplayHeader failed: %v", err)
+	}
+
+	frames := deterministicReplayFrames()
+	frames[100].Choose = 1
+	frames[101].Restart = true
+	frames[102].Reroll = true
+	frames[103].Skip = true
+	frames[104].Choose, frames[104].Banish = 3, true
+	// A tick gap must survive even though recordings are normally contiguous.
+	for i := 150; i < len(frames); i++ {
+		frames[i].Tick += 5
+	}
+	want := world.ReplayFile{
+		Header:  header,
+		Initial: initial,
+		Frames:  frames,
+		Checkpoints: []world.ReplayCheckpoint{
+			{Tick: 59, Digest: w.StateDigest()},
+		},
+		Keyframes: []world.ReplayKeyframe{
+			{Frame: 120, Snapshot: w.BuildSnapshot()},
+		},
+	}
+
+	dir := t.TempDir()
+	jsonPath := filepath.Join(dir, "replay.json")
+	binPath := filepath.Join(dir, "replay"+world.ReplayBinaryExt)
+	for _, path := range []string{jsonPath, binPath} {
+		if err := world.SaveReplayFile(path, want); err != nil {
+			t.Fatalf("SaveReplayFile(%s) failed: %v", path, err)
+		}
+		got, err := world.LoadReplayFile(path)
+		if err != nil {
+			t.Fatalf("LoadReplayFile(%s) failed: %v", path, err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("replay mismatch after %s round-trip\n got: %#v\nwant: %#v", filepath.Ext(path), got, want)
+		}
+	}
+
+	jsonInfo, err := os.Stat(jsonPath)
+	if err != nil {
+		t.Fatalf("stat json replay: %v", err)
+	}
+	binInfo, err := os.Stat(binPath)
+	if err != nil {
+		t.Fatalf("stat binary replay: %v", err)
+	}
+	if binInfo.Size() >= jsonInfo.Size()/4 {
+		t.Fatalf("binary replay not compact: %d bytes vs %d bytes JSON", binInfo.Size(), jsonInfo.Size())
+	}
+
+	// The codec is detected from magic bytes, not the extension.
+	renamed := filepath.Join(dir, "renamed.json")
+	if err := os.Rename(binPath, renamed); err != nil {
+		t.Fatalf("rename binary replay: %v", err)
+	}
+	got, err := world.LoadReplayFile(renamed)
+	if err != nil {
+		t.Fatalf("LoadReplayFile(renamed) failed: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatal("renamed binary replay decoded differently")
+	}
+}
+
+func TestBinaryReplayRejectsHugeFrameCount(t *testing.T) {
+	w := world.NewWorld(2000, 2000)
+	defer w.Close()
+	initial, err := json.Marshal(w.BuildSnapshot())
+	if err != nil {
+		t.Fatalf("marshal snapshot: %v", err)
+	}
+
+	// a v1 file whose single run claims 2^40 idle frames in a handful of bytes
+	blob := []byte("HLRP")
+	blob = binary.AppendUvarint(blob, 1)
+	blob = binary.AppendVarint(blob, world.ReplayVersion)
+	blob = binary.LittleEndian.AppendUint32(blob, math.Float32bits(1.0/60.0))
+	blob = binary.AppendVarint(blob, 1)
+	blob = binary.AppendUvarint(blob, 0)
+	blob = binary.AppendUvarint(blob, uint64(len(initial)))
+	blob = append(blob, initial...)
+	blob = binary.AppendUvarint(blob, 1<<40)
+	blob = binary.AppendUvarint(blob, 0)
+	blob = binary.AppendUvarint(blob, 1<<40)
+	blob = append(blob, 0)
+	blob = binary.AppendUvarint(blob, 0)
+
+	path := filepath.Join(t.TempDir(), "huge"+world.ReplayBinaryExt)
+	if err := os.WriteFile(path, blob, 0o644); err != nil {
+		t.Fatalf("write replay: %v", err)
+	}
+	if _, err := world.LoadReplayFile(path); err == nil {
+		t.Fatal("expected a replay declaring 2^40 frames to be rejected")
+	}
+}
+
+func TestReplayKeepsMetaBonuses(t *testing.T) {
+	const dt = float32(1.0 / 60.0)
+
+	cfg := world.DefaultConfig()
+	cfg.Meta = world.MetaBonuses{MaxHP: 20, Damage: 0.1, Magnet: 16, Revivals: 1, Rerolls: 2}
+	original := world.NewWorldWithConfig(2000, 2000, cfg, 7)
+	defer original.Close()
+	if p := original.Player; p.MaxHP != 120 || p.HP != 120 || p.Revivals != 1 || original.Upgrade.Rerolls != 4 {
+		t.Fatalf("meta bonuses not applied: MaxHP %.0f HP %.0f revivals %d rerolls %d",
+			p.MaxHP, p.HP, p.Revivals, original.Upgrade.Rerolls)
+	}
+
+	initial := original.BuildSnapshot()
+	frames := deterministicReplayFrames()
+	// restart inside the pause window; the rebuilt player must keep the bonuses
+	frames[65].Restart = true
+	frames[70].TogglePause = false
+	runReplayFrames(original, frames, dt)
+	// bonuses bought after recording started must not leak into playback
+	original.SetMeta(world.MetaBonuses{})
+	wantFinal := original.BuildSnapshot()
+	wantFinal.Cfg.Meta = cfg.Meta
+
+	path := filepath.Join(t.TempDir(), "meta"+world.ReplayBinaryExt)
+	header, err := world.BuildReplayHeader(initial, dt)
+	if err != nil {
+		t.Fatalf("BuildReplayHeader failed: %v", err)
+	}
+	if err := world.SaveReplayFile(path, world.ReplayFile{Header: header, Initial: initial, Frames: frames}); err != nil {
+		t.Fatalf("SaveReplayFile failed: %v", err)
+	}
+	rep, err := world.LoadReplayFile(path)
+	if err != nil {
+		t.Fatalf("LoadReplayFile failed: %v", err)
+	}
+
+	// played back on a world built without any bonuses
+	replayed := world.NewWorld(1, 1)
+	defer replayed.Close()
+	if err := replayed.ApplySnapshot(rep.Initial); err != nil {
+		t.Fatalf("ApplySnapshot failed: %v", err)
+	}
+	runReplayFrames(replayed, rep.Frames, dt)
+	gotFinal := replayed.BuildSnapshot()
+	if !reflect.DeepEqual(gotFinal, wantFinal) {
+		t.Fatalf("replay did not reproduce the meta run\n got: %#v\nwant: %#v", gotFinal, wantFinal)
+	}
+	if gotFinal.TimeSurvived >= float32(len(frames))*dt-1 || gotFinal.Player.Base.MaxHP != 120 || gotFinal.Upgrade.Rerolls != 4 {
+		t.Fatalf("restart in playback lost the bonuses: %+v", gotFinal.Player.Base)
+	}
+
+	bare := world.NewWorld(2000, 2000)
+	defer bare.Close()
+	plain, err := world.BuildReplayHeader(bare.BuildSnapshot(), dt)
+	if err != nil {
+		t.Fatalf("BuildReplayHeader failed: %v", err)
+	}
+	if plain.ConfigHash == header.ConfigHash {
+		t.Fatal("config hash should cover the meta bonuses")
+	}
+}
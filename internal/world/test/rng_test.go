@@ -0,0 +1,96 @@
+package world_test
+
+import (
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"horde-lab/internal/shared/input"
+	"horde-lab/internal/world"
+)
+
+func TestSnapshotSaveLoadKeepsRNGStreamBitIdentical(t *testing.T) {
+	source := world.NewWorld(2000, 2000)
+	defer source.Close()
+
+	for tick := range 600 {
+		in := input.State{Right: tick%120 < 60, Down: tick%90 < 30}
+		source.Enqueue(world.MsgInput{Input: in})
+		source.Tick(1.0 / 60.0)
+	}
+	if source.BuildSnapshot().RNGCalls == 0 {
+		t.Fatal("fixture run should have consumed randomness")
+	}
+
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+	if err := source.SaveSnapshot(path); err != nil {
+		t.Fatalf("SaveSnapshot failed: %v", err)
+	}
+	loaded := world.NewWorld(1, 1)
+	defer loaded.Close()
+	if err := loaded.LoadSnapshot(path); err != nil {
+		t.Fatalf("LoadSnapshot failed: %v", err)
+	}
+
+	assertSameDraws(t, source, loaded, 1000)
+}
+
+func TestLoadV1SnapshotMigratesRNGState(t *testing.T) {
+	const calls = 37
+
+	src := world.NewWorld(2000, 2000)
+	defer src.Close()
+	snap := src.BuildSnapshot()
+
+	// Shape the snapshot like a v1 file: no stored generator state.
+	snap.Version = 1
+	snap.RNG = world.RNGState{}
+	snap.RNGCalls = calls
+	blob, err := json.Marshal(snap)
+	if err != nil {
+		t.Fatalf("marshal v1 fixture: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "snapshot_v1.json")
+	if err := os.WriteFile(path, blob, 0o644); err != nil {
+		t.Fatalf("write v1 fixture: %v", err)
+	}
+
+	migrated := world.NewWorld(1, 1)
+	defer migrated.Close()
+	if err := migrated.LoadSnapshot(path); err != nil {
+		t.Fatalf("LoadSnapshot v1 failed: %v", err)
+	}
+	if got := migrated.BuildSnapshot().Version; got != world.SnapshotVersion {
+		t.Fatalf("migrated snapshot version: got %d want %d", got, world.SnapshotVersion)
+	}
+
+	reference := world.NewWorld(2000, 2000)
+	defer reference.Close()
+	for range calls {
+		reference.TestOnlyRandFloat32()
+	}
+
+	assertSameDraws(t, reference, migrated, 1000)
+}
+
+func assertSameDraws(t *testing.T, want, got *world.World, n int) {
+	t.Helper()
+	for i := range n {
+		if i%3 == 2 {
+			a, b := want.TestOnlyRandIntn(1000+i), got.TestOnlyRandIntn(1000+i)
+			if a != b {
+				t.Fatalf("draw %d (intn) diverged: got %d want %d", i, b, a)
+			}
+			continue
+		}
+		a, b := want.TestOnlyRandFloat32(), got.TestOnlyRandFloat32()
+		if math.Float32bits(a) != math.Float32bits(b) {
+			t.Fatalf("draw %d (float32) diverged: got %v want %v", i, b, a)
+		}
+		if b < 0 || b >= 1 {
+			t.Fatalf("draw %d out of [0,1): %v", i, b)
+		}
+	}
+}
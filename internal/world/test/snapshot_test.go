@@ -4,6 +4,7 @@ import (
 	"path/filepath"
 	"reflect"
 	"testing"
+	"time"
 
 	"horde-lab/internal/shared/input"
 	"horde-lab/internal/world"
@@ -67,11 +68,14 @@ func newSnapshotFixtureWorld() *world.World {
 
 	w.Player.Pos = world.Vec2{X: 321, Y: 222}
 	w.Player.Speed = 280
-	w.Player.AttackTimer = 0.13
 	w.Player.AttackCooldown = 0.39
 	w.Player.AttackRange = 0
 	w.Player.Damage = 47
-	w.Player.Weapon = world.WeaponNova
+	w.Player.Weapons = []world.WeaponSlot{
+		{Kind: world.WeaponNova, Level: 3, Timer: 0.13},
+		{Kind: world.WeaponTwinFang, Level: 1},
+	}
+	w.Player.Passives = []world.PassiveSlot{{Kind: world.PassiveMight, Level: 2}}
 	w.Player.HP = 88
 	w.Player.MaxHP = 120
 	w.Player.Level = 4
@@ -119,7 +123,7 @@ func newSnapshotFixtureWorld() *world.World {
 	}
 	w.Upgrade = world.UpgradeMenu{
 		Active: true,
-		Options: [2]world.UpgradeOption{
+		Options: []world.UpgradeOption{
 			{Kind: world.UpDamage, Title: "1) +Damage", Desc: "Increase damage by +10"},
 			{Kind: world.UpMagnet, Title: "2) Magnet", Desc: "Increase XP pickup radius by +15"},
 		},
@@ -139,3 +143,49 @@ func newSnapshotFixtureWorld() *world.World {
 
 	return w
 }
+
+func TestQueuedSaveLandsBeforeLoad(t *testing.T) {
+	w := newSnapshotFixtureWorld()
+	defer w.Close()
+	saved := w.Player.Pos
+
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+	saveReply, loadReply := make(chan error, 1), make(chan error, 1)
+	w.Enqueue(world.MsgSaveSnapshot{Path: path, Reply: saveReply})
+	w.Enqueue(world.MsgInput{Input: input.State{Right: true}})
+	w.Enqueue(world.MsgLoadSnapshot{Path: path, Reply: loadReply})
+	w.Tick(1.0 / 60.0)
+
+	if err := <-saveReply; err != nil {
+		t.Fatalf("save failed: %v", err)
+	}
+	if err := <-loadReply; err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	if w.Player.Pos != saved {
+		t.Fatalf("loaded player at %v, want the saved %v", w.Player.Pos, saved)
+	}
+}
+
+func TestSaveAfterPoolCloseStillWrites(t *testing.T) {
+	w := newSnapshotFixtureWorld()
+	defer w.Close()
+	w.TestOnlyStallAIPool()
+
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+	reply := make(chan error, 1)
+	w.Enqueue(world.MsgSaveSnapshot{Path: path, Reply: reply})
+	w.Tick(1.0 / 60.0)
+
+	select {
+	case err := <-reply:
+		if err != nil {
+			t.Fatalf("save failed: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("save queued on closed workers never ran")
+	}
+	if err := w.LoadSnapshot(path); err != nil {
+		t.Fatalf("saved snapshot does not load: %v", err)
+	}
+}
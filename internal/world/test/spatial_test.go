@@ -0,0 +1,87 @@
+package world_test
+
+import (
+	"math"
+	"testing"
+
+	"horde-lab/internal/world"
+)
+
+func TestWhipTargetsNearestEnemyAcrossGridCells(t *testing.T) {
+	w := world.NewWorld(2000, 2000)
+	defer w.Close()
+
+	w.TestOnlyDisableAIPool()
+	w.Obstacles = nil
+	w.Terrain = nil
+	w.Player.Pos = world.Vec2{X: 1000, Y: 1000}
+	w.Enemies = []world.Enemy{
+		{ID: 1, Pos: world.Vec2{X: 1150, Y: 1000}, R: 9, HP: 500, MaxHP: 500},
+		{ID: 2, Pos: world.Vec2{X: 930, Y: 960}, R: 9, HP: 500, MaxHP: 500},
+		{ID: 3, Pos: world.Vec2{X: 1000, Y: 1170}, R: 9, HP: 500, MaxHP: 500},
+	}
+
+	w.Tick(1.0 / 60.0)
+
+	for _, e := range w.Enemies {
+		hit := e.HP < e.MaxHP
+		if hit != (e.ID == 2) {
+			t.Fatalf("expected only enemy 2 (nearest, different cell) to be hit, got %+v", w.Enemies)
+		}
+	}
+}
+
+func TestContactDamageFindsEnemyInNeighbourCell(t *testing.T) {
+	w := world.NewWorld(2000, 2000)
+	defer w.Close()
+
+	w.TestOnlyDisableAIPool()
+	w.Obstacles = nil
+	w.Terrain = nil
+	w.Player.Base.AttackRange = 0
+	// Player sits just left of a cell boundary, the enemy just right of it.
+	w.Player.Pos = world.Vec2{X: 1023, Y: 1000}
+	w.Enemies = []world.Enemy{
+		{ID: 1, Pos: world.Vec2{X: 1040, Y: 1000}, R: 9, HP: 500, MaxHP: 500, TouchDamage: 7},
+	}
+
+	w.Tick(1.0 / 60.0)
+
+	if got := w.Stats.DamageTaken; got != 7 {
+		t.Fatalf("expected contact damage across cell boundary: got %.1f want 7", got)
+	}
+}
+
+func BenchmarkTickCrowd500(b *testing.B)  { benchmarkTickCrowd(b, 500) }
+func BenchmarkTickCrowd2000(b *testing.B) { benchmarkTickCrowd(b, 2000) }
+
+func benchmarkTickCrowd(b *testing.B, n int) {
+	w := world.NewWorld(4000, 4000)
+	defer w.Close()
+
+	w.TestOnlyDisableAIPool()
+	w.Cfg.BaseSpawnEvery = 1e9
+	w.Cfg.MinSpawnEvery = 1e9
+	w.Player.Pos = world.Vec2{X: 2000, Y: 2000}
+	w.Player.HP = 1e9
+	w.Player.Base.MaxHP = 1e9
+	w.Enemies = make([]world.Enemy, 0, n)
+	for i := range n {
+		ang := float64(i) * 2.399963
+		r := 40 + 900*math.Sqrt(float64(i)/float64(n))
+		w.Enemies = append(w.Enemies, world.Enemy{
+			ID:    i,
+			Pos:   world.Vec2{X: 2000 + float32(math.Cos(ang)*r), Y: 2000 + float32(math.Sin(ang)*r)},
+			R:     9,
+			HP:    1e9,
+			MaxHP: 1e9,
+		})
+	}
+
+	const dt = float32(1.0 / 60.0)
+	b.ResetTimer()
+	for range b.N {
+		w.Enqueue(world.MsgInput{})
+		w.Tick(dt)
+	}
+}
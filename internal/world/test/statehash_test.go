@@ -0,0 +1,108 @@
+package world_test
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"horde-lab/internal/world"
+)
+
+func TestStateHashMatchesForIdenticalRuns(t *testing.T) {
+	const dt = float32(1.0 / 60.0)
+
+	a := world.NewWorld(2000, 2000)
+	defer a.Close()
+	b := world.NewWorld(2000, 2000)
+	defer b.Close()
+
+	for _, frame := range deterministicReplayFrames() {
+		a.EnqueueFrame(frame)
+		b.EnqueueFrame(frame)
+		a.Tick(dt)
+		b.Tick(dt)
+		if a.StateHash() != b.StateHash() {
+			t.Fatalf("state hash diverged at tick %d", frame.Tick)
+		}
+	}
+
+	before := b.StateDigest()
+	b.Player.Pos.X += 0.25
+	after := b.StateDigest()
+	if after.Hash == before.Hash || after.Player == before.Player {
+		t.Fatal("moving the player should change the player and overall hashes")
+	}
+	if after.Enemies != before.Enemies || after.RNG != before.RNG || after.Timers != before.Timers {
+		t.Fatal("unrelated section hashes changed")
+	}
+}
+
+func TestReplayVerifierReportsFirstDivergentCheckpoint(t *testing.T) {
+	const dt = float32(1.0 / 60.0)
+
+	recorded := world.NewWorld(2000, 2000)
+	defer recorded.Close()
+	// walls could push the perturbed player back onto the recorded path
+	recorded.Terrain = nil
+
+	initial := recorded.BuildSnapshot()
+	rep := world.ReplayFile{Initial: initial, Frames: deterministicReplayFrames()}
+	for _, frame := range rep.Frames {
+		recorded.EnqueueFrame(frame)
+		recorded.Tick(dt)
+		rep.RecordCheckpoint(recorded, frame.Tick)
+	}
+	if len(rep.Checkpoints) != len(rep.Frames)/world.ReplayCheckpointInterval {
+		t.Fatalf("unexpected checkpoint count: %d", len(rep.Checkpoints))
+	}
+
+	replayed := world.NewWorld(1, 1)
+	defer replayed.Close()
+	if err := replayed.ApplySnapshot(initial); err != nil {
+		t.Fatalf("ApplySnapshot failed: %v", err)
+	}
+
+	verifier := world.NewReplayVerifier(rep.Checkpoints)
+	var desync *world.DesyncError
+	for _, frame := range rep.Frames {
+		replayed.EnqueueFrame(frame)
+		replayed.Tick(dt)
+		if frame.Tick == 100 {
+			replayed.Player.Pos.Y -= 3
+		}
+		if err := verifier.Check(replayed, frame.Tick); err != nil {
+			if desync != nil {
+				t.Fatalf("verifier reported a second desync: %v", err)
+			}
+			if !errors.As(err, &desync) {
+				t.Fatalf("expected *world.DesyncError, got %T", err)
+			}
+		}
+	}
+
+	if desync == nil {
+		t.Fatal("expected a desync after perturbing the player")
+	}
+	if desync.Tick != 119 {
+		t.Fatalf("desync tick = %d, want 119", desync.Tick)
+	}
+	if !slices.ContainsFunc(desync.Diff, func(line string) bool {
+		return strings.HasPrefix(line, "summary.player_pos.Y:")
+	}) {
+		t.Fatalf("diff should name the player position, got %q", desync.Diff)
+	}
+
+	kf := world.ReplayKeyframe{Frame: len(rep.Frames), Snapshot: recorded.BuildSnapshot()}
+	desync.DiffKeyframe(kf, replayed)
+	if desync.FieldsFrame != kf.Frame || !slices.ContainsFunc(desync.Fields, func(line string) bool {
+		return strings.HasPrefix(line, "player.Pos.Y:")
+	}) {
+		t.Fatalf("keyframe diff should name the player field, got frame %d %q", desync.FieldsFrame, desync.Fields)
+	}
+	fields := desync.Fields
+	desync.DiffKeyframe(world.ReplayKeyframe{Frame: kf.Frame + 1}, replayed)
+	if desync.FieldsFrame != kf.Frame || !slices.Equal(desync.Fields, fields) {
+		t.Fatal("a later keyframe replaced the first diff")
+	}
+}
@@ -0,0 +1,190 @@
+package world_test
+
+import (
+	"testing"
+
+	"horde-lab/internal/world"
+)
+
+func TestPlayerStatsAreOrderIndependent(t *testing.T) {
+	passives := []world.PassiveSlot{
+		{Kind: world.PassiveMight, Level: 3},
+		{Kind: world.PassiveHaste, Level: 4},
+		{Kind: world.PassiveWings, Level: 2},
+		{Kind: world.PassiveArea, Level: 5},
+		{Kind: world.PassiveVitality, Level: 1},
+	}
+	buffs := []world.StatBuff{
+		{Mod: world.StatMod{Stat: world.StatSpeed, Mul: 1.3}, Remaining: 5},
+		{Mod: world.StatMod{Stat: world.StatDamage, Add: 7}, Remaining: 5},
+		{Mod: world.StatMod{Stat: world.StatSpeed, Add: 15}, Remaining: 5},
+		{Mod: world.StatMod{Stat: world.StatCooldown, Mul: 0.9}, Remaining: 5},
+		{Mod: world.StatMod{Stat: world.StatArea, Mul: 1.07}, Remaining: 5},
+	}
+
+	stats := func(p []world.PassiveSlot, b []world.StatBuff) world.Player {
+		w := newCombatWorld(t)
+		w.Player.Passives, w.Player.Buffs = p, b
+		w.Tick(1.0 / 60.0)
+		pl := w.Player
+		pl.Pos, pl.Buffs, pl.Passives = world.Vec2{}, nil, nil
+		return pl
+	}
+	want := stats(passives, buffs)
+
+	// every rotation and the reversal of both lists
+	for r := range len(passives) {
+		p := append(append([]world.PassiveSlot{}, passives[r:]...), passives[:r]...)
+		b := append(append([]world.StatBuff{}, buffs[r:]...), buffs[:r]...)
+		if got := stats(p, b); got.Speed != want.Speed || got.Damage != want.Damage ||
+			got.AttackCooldown != want.AttackCooldown || got.Area != want.Area || got.MaxHP != want.MaxHP {
+			t.Fatalf("rotation %d: got %+v, want %+v", r, got, want)
+		}
+	}
+	rp, rb := append([]world.PassiveSlot{}, passives...), append([]world.StatBuff{}, buffs...)
+	for i, j := 0, len(rp)-1; i < j; i, j = i+1, j-1 {
+		rp[i], rp[j] = rp[j], rp[i]
+	}
+	for i, j := 0, len(rb)-1; i < j; i, j = i+1, j-1 {
+		rb[i], rb[j] = rb[j], rb[i]
+	}
+	if got := stats(rp, rb); got.Speed != want.Speed || got.Damage != want.Damage || got.AttackCooldown != want.AttackCooldown {
+		t.Fatalf("reversed: got %+v, want %+v", got, want)
+	}
+
+	if wantSpeed := float32((260 + 15) * 1.1 * 1.1 * 1.3); !approxEqual(want.Speed, wantSpeed) {
+		t.Fatalf("speed = %.3f, want %.3f", want.Speed, wantSpeed)
+	}
+	if want.Damage != 25+30+7 {
+		t.Fatalf("damage = %.2f, want 62", want.Damage)
+	}
+}
+
+func TestStatsRecomputeFromBaseEveryTick(t *testing.T) {
+	w := newCombatWorld(t)
+	w.Player.Damage = 999
+	w.Player.Buffs = []world.StatBuff{{Mod: world.StatMod{Stat: world.StatDamage, Add: 5}, Remaining: 0.02}}
+
+	w.Tick(1.0 / 60.0)
+	if w.Player.Damage != 30 {
+		t.Fatalf("damage with buff = %.2f, want 30", w.Player.Damage)
+	}
+	w.Tick(1.0 / 60.0)
+	if w.Player.Damage != 25 || len(w.Player.Buffs) != 0 {
+		t.Fatalf("damage after the buff expired = %.2f (buffs %+v), want 25", w.Player.Damage, w.Player.Buffs)
+	}
+}
+
+func TestReappliedBuffRestartsItsTimer(t *testing.T) {
+	w := newCombatWorld(t)
+	might := world.StatMod{Stat: world.StatDamage, Add: 5}
+	w.TestOnlyAddBuff(might, 1)
+	for range 30 {
+		w.Tick(1.0 / 60.0)
+	}
+
+	// a shorter copy does not stack and cuts the running one short
+	w.TestOnlyAddBuff(might, 0.05)
+	if len(w.Player.Buffs) != 1 || w.Player.Buffs[0].Remaining != 0.05 {
+		t.Fatalf("buffs after re-applying = %+v, want one with 0.05s left", w.Player.Buffs)
+	}
+	for range 4 {
+		w.Tick(1.0 / 60.0)
+	}
+	if w.Player.Damage != 25 || len(w.Player.Buffs) != 0 {
+		t.Fatalf("damage after the buff expired = %.2f (buffs %+v), want 25", w.Player.Damage, w.Player.Buffs)
+	}
+}
+
+func TestPassiveEffects(t *testing.T) {
+	t.Run("vitality raises max HP and heals the gain", func(t *testing.T) {
+		w := newCombatWorld(t)
+		w.Player.HP = 50
+		w.TestOnlyTakePassive(world.PassiveVitality)
+		if w.Player.MaxHP != 120 || w.Player.HP != 70 {
+			t.Fatalf("HP %.0f/%.0f, want 70/120", w.Player.HP, w.Player.MaxHP)
+		}
+	})
+
+	t.Run("armor reduces hits", func(t *testing.T) {
+		w := newCombatWorld(t)
+		w.TestOnlyTakePassive(world.PassiveArmor)
+		w.TestOnlyTakePassive(world.PassiveArmor)
+		w.Enemies = []world.Enemy{{ID: 1, Pos: w.Player.Pos, R: 9, HP: 500, MaxHP: 500, TouchDamage: 10}}
+		w.Tick(1.0 / 60.0)
+		if w.Stats.DamageTaken != 8 {
+			t.Fatalf("damage taken %.2f, want 8", w.Stats.DamageTaken)
+		}
+	})
+
+	t.Run("regen heals over time", func(t *testing.T) {
+		w := newCombatWorld(t)
+		w.Cfg.BaseSpawnEvery, w.Cfg.MinSpawnEvery = 1e9, 1e9
+		w.TestOnlyTakePassive(world.PassiveRegen)
+		w.Player.HP = 50
+		for range 10 {
+			w.Tick(0.5)
+		}
+		if !approxEqual(w.Player.HP, 51) {
+			t.Fatalf("HP after 5s of regen = %.3f, want 51", w.Player.HP)
+		}
+	})
+
+	t.Run("duplicator adds projectiles", func(t *testing.T) {
+		w := newCombatWorld(t, world.WeaponSlot{Kind: world.WeaponOrbit, Level: 1})
+		w.TestOnlyTakePassive(world.PassiveDuplicator)
+		w.Enemies = []world.Enemy{{ID: 1, Pos: world.Vec2{X: 1070, Y: 1000}, R: 9, HP: 500, MaxHP: 500}}
+		w.Tick(1.0 / 60.0)
+		if len(w.PlayerShots) != 3 {
+			t.Fatalf("expected 3 orbiters, got %d", len(w.PlayerShots))
+		}
+	})
+
+	t.Run("revival saves a lethal hit once", func(t *testing.T) {
+		w := newCombatWorld(t)
+		w.TestOnlyTakePassive(world.PassiveRevival)
+		w.Player.HP = 5
+		w.Enemies = []world.Enemy{{ID: 1, Pos: w.Player.Pos, R: 9, HP: 500, MaxHP: 500, TouchDamage: 10}}
+
+		w.Tick(1.0 / 60.0)
+		if w.GameOver || w.Player.HP != 50 || w.Player.RevivesUsed != 1 {
+			t.Fatalf("expected a revive at half HP, game over %v HP %.0f", w.GameOver, w.Player.HP)
+		}
+		w.Player.HP, w.Player.HurtTimer = 5, 0
+		w.Enemies[0].Pos = w.Player.Pos
+		w.Tick(1.0 / 60.0)
+		if !w.GameOver {
+			t.Fatalf("expected the second lethal hit to end the run")
+		}
+	})
+}
+
+func TestUpgradeMenuOffersPassives(t *testing.T) {
+	w := newCombatWorld(t)
+	for _, kind := range []world.PassiveKind{world.PassiveDuplicator, world.PassiveDuplicator, world.PassiveRevival, world.PassiveRevival} {
+		w.TestOnlyTakePassive(kind)
+	}
+
+	seen := map[world.PassiveKind]bool{}
+	for range 200 {
+		w.Player.XP = w.Player.XPToNext
+		w.Tick(1.0 / 60.0)
+		for _, o := range w.Upgrade.Options {
+			if o.Kind == world.UpPassive {
+				seen[o.Passive] = true
+			}
+		}
+		w.Upgrade = world.UpgradeMenu{}
+	}
+	if seen[world.PassiveDuplicator] || seen[world.PassiveRevival] {
+		t.Fatalf("maxed passives offered: %v", seen)
+	}
+	if !seen[world.PassiveArmor] || !seen[world.PassiveLuck] {
+		t.Fatalf("expected passive offers, saw %v", seen)
+	}
+
+	chooseUpgrade(w, world.UpgradeOption{Kind: world.UpPassive, Passive: world.PassiveWings})
+	if w.Player.PassiveLevel(world.PassiveWings) != 1 || !approxEqual(w.Player.Speed, 286) {
+		t.Fatalf("wings level %d speed %.2f, want 1 and 286", w.Player.PassiveLevel(world.PassiveWings), w.Player.Speed)
+	}
+}
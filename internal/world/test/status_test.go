@@ -0,0 +1,194 @@
+package world_test
+
+import (
+	"testing"
+
+	"horde-lab/internal/shared/input"
+	"horde-lab/internal/world"
+)
+
+func TestStatusStackingRules(t *testing.T) {
+	cases := []struct {
+		name    string
+		applies []world.StatusApply
+		want    world.StatusEffect
+	}{
+		{
+			name: "burn stacks intensity up to the cap",
+			applies: []world.StatusApply{
+				{Kind: world.StatusBurn, Magnitude: 3, Duration: 2},
+				{Kind: world.StatusBurn, Magnitude: 3, Duration: 1},
+				{Kind: world.StatusBurn, Magnitude: 3, Duration: 2},
+				{Kind: world.StatusBurn, Magnitude: 3, Duration: 2},
+				{Kind: world.StatusBurn, Magnitude: 3, Duration: 2},
+				{Kind: world.StatusBurn, Magnitude: 3, Duration: 2},
+			},
+			want: world.StatusEffect{Kind: world.StatusBurn, Stacks: 5, Magnitude: 3, Remaining: 2},
+		},
+		{
+			name: "poison extends duration up to the cap",
+			applies: []world.StatusApply{
+				{Kind: world.StatusPoison, Magnitude: 4, Duration: 3},
+				{Kind: world.StatusPoison, Magnitude: 2, Duration: 3},
+				{Kind: world.StatusPoison, Magnitude: 4, Duration: 3},
+			},
+			want: world.StatusEffect{Kind: world.StatusPoison, Stacks: 1, Magnitude: 4, Remaining: 8},
+		},
+		{
+			name: "slow keeps the strongest and longest",
+			applies: []world.StatusApply{
+				{Kind: world.StatusSlow, Magnitude: 0.3, Duration: 2},
+				{Kind: world.StatusSlow, Magnitude: 0.5, Duration: 1},
+			},
+			want: world.StatusEffect{Kind: world.StatusSlow, Stacks: 1, Magnitude: 0.5, Remaining: 2},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := newCombatWorld(t)
+			w.Enemies = []world.Enemy{{ID: 1, Pos: world.Vec2{X: 1300, Y: 1000}, R: 9, HP: 100, MaxHP: 100}}
+			for _, a := range tc.applies {
+				w.TestOnlyApplyEnemyStatus(0, a)
+			}
+
+			got := w.Enemies[0].Statuses
+			if len(got) != 1 {
+				t.Fatalf("expected one effect, got %+v", got)
+			}
+			got[0].TickT = 0
+			if got[0] != tc.want {
+				t.Fatalf("got %+v, want %+v", got[0], tc.want)
+			}
+		})
+	}
+}
+
+func TestBurnTicksAndExpires(t *testing.T) {
+	w := newCombatWorld(t)
+	w.Enemies = []world.Enemy{{ID: 1, Pos: world.Vec2{X: 1300, Y: 1000}, R: 9, HP: 100, MaxHP: 100}}
+	burn := world.StatusApply{Kind: world.StatusBurn, Magnitude: 3, Duration: 1}
+	w.TestOnlyApplyEnemyStatus(0, burn)
+	w.TestOnlyApplyEnemyStatus(0, burn)
+
+	// ticks at 0.5s and 1s, two stacks of 3 each
+	for range 6 {
+		w.Tick(0.25)
+	}
+	if got := enemyHP(w, 1); got != 88 {
+		t.Fatalf("enemy HP = %.2f, want 88", got)
+	}
+	if len(w.Enemies[0].Statuses) != 0 {
+		t.Fatalf("expected burn to expire, got %+v", w.Enemies[0].Statuses)
+	}
+}
+
+func TestVulnerableAmplifiesHits(t *testing.T) {
+	w := newCombatWorld(t, world.WeaponSlot{Kind: world.WeaponWhip, Level: 1})
+	w.Enemies = []world.Enemy{{ID: 1, Pos: world.Vec2{X: 1050, Y: 1000}, R: 9, HP: 500, MaxHP: 500}}
+	vuln := world.StatusApply{Kind: world.StatusVuln, Magnitude: 0.15, Duration: 5}
+	w.TestOnlyApplyEnemyStatus(0, vuln)
+	w.TestOnlyApplyEnemyStatus(0, vuln)
+
+	w.Tick(1.0 / 60.0)
+
+	if want := float32(500 - 25*1.3); !approxEqual(enemyHP(w, 1), want) {
+		t.Fatalf("enemy HP = %.2f, want %.2f", enemyHP(w, 1), want)
+	}
+}
+
+func TestSlowAndStunScaleEnemyMovement(t *testing.T) {
+	w := newCombatWorld(t)
+	start := []world.Vec2{{X: 1300, Y: 1000}, {X: 700, Y: 1000}, {X: 1000, Y: 1300}}
+	w.Enemies = []world.Enemy{
+		{ID: 1, Pos: start[0], Speed: 100, R: 9, HP: 100, MaxHP: 100},
+		{ID: 2, Pos: start[1], Speed: 100, R: 9, HP: 100, MaxHP: 100},
+		{ID: 3, Pos: start[2], Speed: 100, R: 9, HP: 100, MaxHP: 100},
+	}
+	w.TestOnlyApplyEnemyStatus(1, world.StatusApply{Kind: world.StatusSlow, Magnitude: 0.5, Duration: 1})
+	w.TestOnlyApplyEnemyStatus(2, world.StatusApply{Kind: world.StatusStun, Duration: 1})
+
+	w.Tick(0.1)
+
+	for i, want := range []float32{10, 5, 0} {
+		if got := w.Enemies[i].Pos.Sub(start[i]).Len(); !approxEqual(got, want) {
+			t.Fatalf("enemy %d moved %.3f, want %.3f", i+1, got, want)
+		}
+	}
+}
+
+func TestPlayerStatuses(t *testing.T) {
+	t.Run("stun blocks movement and attacks", func(t *testing.T) {
+		w := newCombatWorld(t, world.WeaponSlot{Kind: world.WeaponWhip, Level: 1})
+		w.Enemies = []world.Enemy{{ID: 1, Pos: world.Vec2{X: 1050, Y: 1000}, R: 9, HP: 500, MaxHP: 500}}
+		w.Player.Statuses = []world.StatusEffect{{Kind: world.StatusStun, Stacks: 1, Remaining: 1}}
+		start := w.Player.Pos
+
+		w.Enqueue(world.MsgInput{Input: input.State{Right: true}})
+		w.Tick(1.0 / 60.0)
+
+		if w.Player.Pos != start || enemyHP(w, 1) != 500 {
+			t.Fatalf("stunned player moved to %+v or attacked (enemy HP %.2f)", w.Player.Pos, enemyHP(w, 1))
+		}
+	})
+
+	t.Run("poison ignores the hurt timer", func(t *testing.T) {
+		w := newCombatWorld(t)
+		w.Player.HurtTimer = 10
+		w.Player.Statuses = []world.StatusEffect{{Kind: world.StatusPoison, Stacks: 1, Magnitude: 2, Remaining: 3, TickT: 1}}
+		hp := w.Player.HP
+
+		for range 4 {
+			w.Tick(0.5)
+		}
+		if got := hp - w.Player.HP; got != 4 {
+			t.Fatalf("poison dealt %.2f, want 4", got)
+		}
+	})
+
+	t.Run("enemy contact applies on-hit statuses", func(t *testing.T) {
+		w := newCombatWorld(t)
+		w.Enemies = []world.Enemy{{ID: 1, Kind: world.EnemyTank, Pos: w.Player.Pos, R: 14, HP: 140, MaxHP: 140, TouchDamage: 18}}
+
+		w.Tick(1.0 / 60.0)
+
+		if len(w.Player.Statuses) != 1 || w.Player.Statuses[0].Kind != world.StatusSlow {
+			t.Fatalf("expected tank contact to slow the player, got %+v", w.Player.Statuses)
+		}
+	})
+}
+
+func TestStatusesSurviveSnapshot(t *testing.T) {
+	a := newCombatWorld(t, world.WeaponSlot{Kind: world.WeaponTwinFang, Level: 1})
+	a.Enemies = []world.Enemy{
+		{ID: 1, Pos: world.Vec2{X: 1060, Y: 1000}, Speed: 60, R: 9, HP: 900, MaxHP: 900},
+		{ID: 2, Pos: world.Vec2{X: 1000, Y: 1070}, Speed: 60, R: 9, HP: 900, MaxHP: 900},
+	}
+	a.TestOnlyApplyEnemyStatus(1, world.StatusApply{Kind: world.StatusSlow, Magnitude: 0.4, Duration: 2})
+	a.Player.Statuses = []world.StatusEffect{{Kind: world.StatusBurn, Stacks: 2, Magnitude: 1, Remaining: 2, TickT: 0.5}}
+	a.Tick(1.0 / 60.0)
+
+	snap := a.BuildSnapshot()
+	if len(snap.Enemies[0].Statuses) == 0 || len(snap.Player.Statuses) == 0 {
+		t.Fatalf("expected statuses in snapshot, got %+v / %+v", snap.Enemies[0].Statuses, snap.Player.Statuses)
+	}
+	remaining := snap.Enemies[0].Statuses[0].Remaining
+	a.Tick(1.0 / 60.0)
+	if snap.Enemies[0].Statuses[0].Remaining != remaining {
+		t.Fatalf("ticking the world changed the snapshot's statuses")
+	}
+
+	// restore both, so they also share the AI pool state a restore sets up
+	b := newCombatWorld(t)
+	for _, w := range []*world.World{a, b} {
+		if err := w.ApplySnapshot(snap); err != nil {
+			t.Fatalf("apply snapshot: %v", err)
+		}
+	}
+	for i := range 90 {
+		a.Tick(1.0 / 60.0)
+		b.Tick(1.0 / 60.0)
+		if a.StateHash() != b.StateHash() {
+			t.Fatalf("state diverged %d ticks after restore", i+1)
+		}
+	}
+}
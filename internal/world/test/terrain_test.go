@@ -0,0 +1,138 @@
+package world_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"horde-lab/internal/shared/input"
+	"horde-lab/internal/world"
+)
+
+func TestTerrainReproducesFromSeed(t *testing.T) {
+	a := world.NewWorldWithConfig(2000, 2000, world.DefaultConfig(), 11)
+	b := world.NewWorldWithConfig(2000, 2000, world.DefaultConfig(), 11)
+	defer a.Close()
+	defer b.Close()
+	if a.Terrain == nil || !reflect.DeepEqual(a.Terrain, b.Terrain) {
+		t.Fatal("expected the same seed to generate the same terrain")
+	}
+
+	walls := 0
+	for _, tile := range a.Terrain.Tiles {
+		if tile == world.TileWall {
+			walls++
+		}
+	}
+	if walls == 0 || walls > len(a.Terrain.Tiles)/3 {
+		t.Fatalf("%d of %d tiles are walls", walls, len(a.Terrain.Tiles))
+	}
+	if a.Terrain.TileAt(a.Player.Pos) != world.TileFloor {
+		t.Fatal("expected the player to start on open floor")
+	}
+
+	// snapshots carry the params, not the tiles
+	snap := a.BuildSnapshot()
+	blob, err := json.Marshal(snap.Terrain)
+	if err != nil {
+		t.Fatalf("marshal terrain: %v", err)
+	}
+	if len(blob) > 200 {
+		t.Fatalf("terrain stored as %d bytes: %s", len(blob), blob)
+	}
+	loaded := world.NewWorld(1, 1)
+	defer loaded.Close()
+	if err := loaded.ApplySnapshot(snap); err != nil {
+		t.Fatalf("ApplySnapshot failed: %v", err)
+	}
+	if !reflect.DeepEqual(loaded.Terrain, a.Terrain) {
+		t.Fatal("terrain regenerated differently from the snapshot params")
+	}
+}
+
+func TestBiomesVaryPerRunUnlessForced(t *testing.T) {
+	seen := map[world.Biome]bool{}
+	for seed := int64(1); seed <= 30; seed++ {
+		w := world.NewWorldWithConfig(400, 400, world.DefaultConfig(), seed)
+		seen[w.Terrain.Params.Biome] = true
+		w.Close()
+	}
+	for _, b := range []world.Biome{world.BiomeGraveyard, world.BiomeForest, world.BiomeRuins} {
+		if !seen[b] {
+			t.Fatalf("biome %q never picked, saw %v", b, seen)
+		}
+	}
+
+	cfg := world.DefaultConfig()
+	cfg.Biome = world.BiomeRuins
+	for seed := int64(1); seed <= 5; seed++ {
+		w := world.NewWorldWithConfig(400, 400, cfg, seed)
+		if w.Terrain.Params.Biome != world.BiomeRuins {
+			t.Fatalf("seed %d: biome %q, want the forced ruins", seed, w.Terrain.Params.Biome)
+		}
+		w.Close()
+	}
+
+	cfg.TerrainTileSize = 0
+	w := world.NewWorldWithConfig(400, 400, cfg, 1)
+	defer w.Close()
+	if w.Terrain != nil || w.BuildSnapshot().Terrain != (world.TerrainParams{}) {
+		t.Fatal("expected no terrain with a zero tile size")
+	}
+}
+
+// solidBeside finds a solid tile of kind with walkable floor to its left.
+func solidBeside(t *testing.T, m *world.TileMap, kind world.TileKind) (col, row int) {
+	t.Helper()
+	for r := 0; r < m.Params.Rows; r++ {
+		for c := 2; c < m.Params.Cols; c++ {
+			if m.At(c, r) == kind && !m.At(c-1, r).Solid() && !m.At(c-2, r).Solid() &&
+				!m.At(c-1, r-1).Solid() && !m.At(c-1, r+1).Solid() {
+				return c, r
+			}
+		}
+	}
+	t.Fatalf("no tile of kind %d with open floor beside it", kind)
+	return 0, 0
+}
+
+func TestWallsAndWaterBlockMovement(t *testing.T) {
+	for _, kind := range []world.TileKind{world.TileWall, world.TileWater} {
+		w := world.NewWorldWithConfig(2000, 2000, world.DefaultConfig(), 3)
+		w.TestOnlyDisableAIPool()
+		w.Obstacles = nil
+		w.Cfg.BaseSpawnEvery, w.Cfg.MinSpawnEvery = 1e9, 1e9
+
+		ts := w.Terrain.Params.TileSize
+		off := w.Terrain.Offset()
+		c, r := solidBeside(t, w.Terrain, kind)
+		edge := off.X + float32(c)*ts
+		w.Player.Pos = world.Vec2{X: edge - ts*1.5, Y: off.Y + (float32(r)+0.5)*ts}
+		for range 60 {
+			w.Enqueue(world.MsgInput{Input: input.State{Right: true}})
+			w.Tick(1.0 / 60.0)
+		}
+		if got := w.Player.Pos.X + w.Player.R; got > edge+0.01 || got < edge-1 {
+			t.Fatalf("tile kind %d: player edge at %.2f, want it stopped at %.2f", kind, got, edge)
+		}
+		w.Close()
+	}
+}
+
+func TestSpawnsStayOutOfSolidTerrain(t *testing.T) {
+	w := world.NewWorldWithConfig(2000, 2000, world.DefaultConfig(), 5)
+	defer w.Close()
+	w.TestOnlyDisableAIPool()
+	w.Player.HP, w.Player.Base.MaxHP = 1e9, 1e9
+	for range 900 {
+		w.Tick(1.0 / 60.0)
+		for _, e := range w.Enemies {
+			if w.Terrain.TileAt(e.Pos).Solid() {
+				t.Fatalf("enemy %d stands on solid terrain at %+v", e.ID, e.Pos)
+			}
+		}
+	}
+	if len(w.Enemies) == 0 {
+		t.Fatal("expected enemies to spawn")
+	}
+}
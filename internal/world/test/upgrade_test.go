@@ -0,0 +1,212 @@
+package world_test
+
+import (
+	"strings"
+	"testing"
+
+	"horde-lab/internal/world"
+)
+
+// openUpgradeMenu levels the player up once and returns the offer.
+func openUpgradeMenu(t *testing.T, w *world.World) []world.UpgradeOption {
+	t.Helper()
+	w.Player.XP = w.Player.XPToNext
+	w.Tick(1.0 / 60.0)
+	if !w.Upgrade.Active {
+		t.Fatal("expected the level-up menu to open")
+	}
+	return w.Upgrade.Options
+}
+
+func upgradeKey(o world.UpgradeOption) world.UpgradeKey {
+	return world.UpgradeKey{Kind: o.Kind, Weapon: o.Weapon, Passive: o.Passive}
+}
+
+// banishAllBut banishes every stat and passive offer except keep.
+func banishAllBut(w *world.World, keep ...world.UpgradeKey) {
+	keys := []world.UpgradeKey{{Kind: world.UpDamage}, {Kind: world.UpAttackSpeed}, {Kind: world.UpMagnet}}
+	for _, kind := range []world.PassiveKind{
+		world.PassiveArmor, world.PassiveVitality, world.PassiveWings, world.PassiveArea,
+		world.PassiveDuplicator, world.PassiveLuck, world.PassiveRegen, world.PassiveRevival,
+	} {
+		keys = append(keys, world.UpgradeKey{Kind: world.UpPassive, Passive: kind})
+	}
+	for _, k := range keys {
+		banned := true
+		for _, kk := range keep {
+			banned = banned && k != kk
+		}
+		if banned {
+			w.Upgrade.Banished = append(w.Upgrade.Banished, k)
+		}
+	}
+}
+
+func TestUpgradeOfferSizeFollowsConfigAndLuck(t *testing.T) {
+	cases := []struct {
+		name    string
+		choices int
+		luck    float32
+		want    int
+	}{
+		{"default", 3, 1, 3},
+		{"lucky", 3, 2, 4},
+		{"legacy", 0, 1, 2},
+	}
+	for _, c := range cases {
+		w := newCombatWorld(t, world.WeaponSlot{Kind: world.WeaponWhip, Level: 1})
+		w.Cfg.UpgradeChoices = c.choices
+		w.Player.Base.Luck = c.luck
+		opts := openUpgradeMenu(t, w)
+		if len(opts) != c.want {
+			t.Fatalf("%s: %d options, want %d", c.name, len(opts), c.want)
+		}
+		seen := map[world.UpgradeKey]bool{}
+		for i, o := range opts {
+			if seen[upgradeKey(o)] {
+				t.Fatalf("%s: option %+v offered twice", c.name, o)
+			}
+			seen[upgradeKey(o)] = true
+			if prefix := string(rune('1'+i)) + ") "; !strings.HasPrefix(o.Title, prefix) {
+				t.Fatalf("%s: option %d titled %q", c.name, i, o.Title)
+			}
+		}
+	}
+}
+
+func TestUpgradeOfferFallsBackToHealAndGold(t *testing.T) {
+	w := newCombatWorld(t)
+	w.Cfg.WeaponSlots = 0
+	banishAllBut(w)
+	w.Player.HP = 10
+
+	opts := openUpgradeMenu(t, w)
+	if len(opts) != 2 || opts[0].Kind != world.UpHeal || opts[1].Kind != world.UpGold {
+		t.Fatalf("expected heal and gold fallbacks, got %+v", opts)
+	}
+
+	banishes := w.Upgrade.Banishes
+	w.Enqueue(world.MsgBanishUpgrade{Choice: 0})
+	w.Tick(1.0 / 60.0)
+	if w.Upgrade.Banishes != banishes || !w.Upgrade.Active {
+		t.Fatal("heal must not be banishable")
+	}
+
+	w.Enqueue(world.MsgChooseUpgrade{Choice: 0})
+	w.Tick(1.0 / 60.0)
+	if w.Player.HP < 40 {
+		t.Fatalf("heal left HP at %.1f", w.Player.HP)
+	}
+	openUpgradeMenu(t, w)
+	w.Enqueue(world.MsgChooseUpgrade{Choice: 1})
+	w.Tick(1.0 / 60.0)
+	if w.Player.Gold < 25 {
+		t.Fatalf("gold = %d, want at least 25", w.Player.Gold)
+	}
+}
+
+func TestRerollSkipAndBanishSpendCharges(t *testing.T) {
+	w := newCombatWorld(t, world.WeaponSlot{Kind: world.WeaponWhip, Level: 1})
+	openUpgradeMenu(t, w)
+	start := w.Upgrade
+
+	w.Enqueue(world.MsgRerollUpgrades{})
+	w.Tick(1.0 / 60.0)
+	if w.Upgrade.Rerolls != start.Rerolls-1 || !w.Upgrade.Active || w.Upgrade.Pending != start.Pending {
+		t.Fatalf("reroll: %+v", w.Upgrade)
+	}
+
+	banned := upgradeKey(w.Upgrade.Options[0])
+	w.Enqueue(world.MsgBanishUpgrade{Choice: 0})
+	w.Tick(1.0 / 60.0)
+	if w.Upgrade.Banishes != start.Banishes-1 || len(w.Upgrade.Banished) != 1 || !w.Upgrade.Active {
+		t.Fatalf("banish: %+v", w.Upgrade)
+	}
+
+	passives := len(w.Player.Passives)
+	w.Enqueue(world.MsgSkipUpgrade{})
+	w.Tick(1.0 / 60.0)
+	if w.Upgrade.Skips != start.Skips-1 || w.Upgrade.Active || len(w.Player.Passives) != passives {
+		t.Fatalf("skip: %+v", w.Upgrade)
+	}
+
+	for range 100 {
+		for _, o := range openUpgradeMenu(t, w) {
+			if upgradeKey(o) == banned {
+				t.Fatalf("banished option %+v offered again", banned)
+			}
+		}
+		w.Enqueue(world.MsgChooseUpgrade{Choice: 0})
+		w.Tick(1.0 / 60.0)
+	}
+
+	// out of charges, the actions do nothing
+	openUpgradeMenu(t, w)
+	w.Upgrade.Rerolls, w.Upgrade.Skips = 0, 0
+	opts := w.Upgrade.Options
+	w.Enqueue(world.MsgRerollUpgrades{})
+	w.Enqueue(world.MsgSkipUpgrade{})
+	w.Tick(1.0 / 60.0)
+	if !w.Upgrade.Active || w.Upgrade.Options[0] != opts[0] {
+		t.Fatal("expected the offer to stay without charges")
+	}
+}
+
+func TestUpgradeRarityGrantsExtraLevels(t *testing.T) {
+	w := newCombatWorld(t)
+	w.Cfg.WeaponSlots = 0
+	banishAllBut(w, world.UpgradeKey{Kind: world.UpPassive, Passive: world.PassiveDuplicator})
+	// luck this high makes every roll epic
+	w.Player.Base.Luck = 40
+
+	opts := openUpgradeMenu(t, w)
+	if len(opts) != 3 {
+		t.Fatalf("expected duplicator, heal and gold, got %+v", opts)
+	}
+	// Duplicator only has two levels, so its epic roll is capped at rare.
+	if opts[0].Passive != world.PassiveDuplicator || opts[0].Rarity != world.RarityRare {
+		t.Fatalf("duplicator offer %+v, want rare", opts[0])
+	}
+	if opts[1].Rarity != world.RarityEpic || opts[1].Desc != "Restore 90 HP" {
+		t.Fatalf("heal offer %+v, want epic for 90 HP", opts[1])
+	}
+
+	w.Enqueue(world.MsgChooseUpgrade{Choice: 0})
+	w.Tick(1.0 / 60.0)
+	if got := w.Player.PassiveLevel(world.PassiveDuplicator); got != 2 {
+		t.Fatalf("duplicator level %d, want 2", got)
+	}
+
+	chooseUpgrade(w, world.UpgradeOption{Kind: world.UpPassive, Passive: world.PassiveWings, Rarity: world.RarityEpic})
+	if got := w.Player.PassiveLevel(world.PassiveWings); got != 3 {
+		t.Fatalf("epic wings level %d, want 3", got)
+	}
+}
+
+func TestUpgradeActionsReplayDeterministically(t *testing.T) {
+	frames := []world.ReplayFrame{
+		{Choose: -1, Reroll: true},
+		{Choose: 1, Banish: true},
+		{Choose: 2},
+		{Choose: -1, Skip: true},
+		{Choose: 0},
+	}
+	run := func() *world.World {
+		w := newCombatWorld(t, world.WeaponSlot{Kind: world.WeaponWhip, Level: 1})
+		w.Cfg.BaseSpawnEvery, w.Cfg.MinSpawnEvery = 1e9, 1e9
+		for _, f := range frames {
+			w.Player.XP = w.Player.XPToNext
+			w.Tick(1.0 / 60.0)
+			w.EnqueueFrame(f)
+			w.Tick(1.0 / 60.0)
+		}
+		return w
+	}
+	a, b := run(), run()
+	if a.StateHash() != b.StateHash() {
+		t.Fatalf("upgrade actions diverged: %v", world.DiffSnapshots(a.BuildSnapshot(), b.BuildSnapshot()))
+	}
+	if a.Upgrade.Rerolls != 1 || a.Upgrade.Banishes != 1 || a.Upgrade.Skips != 1 {
+		t.Fatalf("expected one of each charge spent, got %+v", a.Upgrade)
+	}
+}
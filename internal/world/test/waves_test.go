@@ -29,7 +29,7 @@ func TestWaveDirectorAdvancesAndPersistsAcrossSnapshots(t *testing.T) {
 	if snap.Wave.Label == "" {
 		t.Fatal("expected generated wave label")
 	}
-	if snap.Wave.TankWeight <= 0 {
+	if snap.Wave.Weight(world.EnemyTank) <= 0 {
 		t.Fatalf("expected tank weight in later waves, got %+v", snap.Wave)
 	}
 	if snap.SpawnEvery >= snap.Cfg.BaseSpawnEvery {
@@ -62,10 +62,8 @@ func TestWaveDirectorAdvancesAndPersistsAcrossSnapshots(t *testing.T) {
 }
 
 func neutralizeEnemyPressure(w *world.World) {
-	w.Cfg.EnemySpeed = 0
-	w.Cfg.EnemyRunnerSpeed = 0
-	w.Cfg.EnemyTankSpeed = 0
-	w.Cfg.EnemyTouchDamage = 0
-	w.Cfg.EnemyRunnerTouchDamage = 0
-	w.Cfg.EnemyTankTouchDamage = 0
+	for i := range w.Cfg.Enemies {
+		w.Cfg.Enemies[i].Speed = 0
+		w.Cfg.Enemies[i].TouchDamage = 0
+	}
 }
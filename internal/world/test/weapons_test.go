@@ -0,0 +1,206 @@
+package world_test
+
+import (
+	"testing"
+
+	"horde-lab/internal/world"
+)
+
+func newCombatWorld(t *testing.T, weapons ...world.WeaponSlot) *world.World {
+	t.Helper()
+	w := newArena(t, arena{})
+	w.Player.Weapons = weapons
+	return w
+}
+
+func TestWeaponSlotsFireInInventoryOrder(t *testing.T) {
+	// Fang alone kills enemy 1. Firing Fang first leaves the Whip to hit
+	// enemy 2; the other order would spend both attacks on enemy 1.
+	fang := 25 * float32(1.35)
+	w := newCombatWorld(t,
+		world.WeaponSlot{Kind: world.WeaponFang, Level: 1},
+		world.WeaponSlot{Kind: world.WeaponWhip, Level: 1},
+	)
+	w.Enemies = []world.Enemy{
+		{ID: 1, Pos: world.Vec2{X: 1050, Y: 1000}, R: 9, HP: fang, MaxHP: fang},
+		{ID: 2, Pos: world.Vec2{X: 1000, Y: 1100}, R: 9, HP: 500, MaxHP: 500},
+	}
+
+	w.Tick(1.0 / 60.0)
+
+	if len(w.Enemies) != 1 || w.Enemies[0].ID != 2 {
+		t.Fatalf("expected Fang to kill enemy 1 first, got %+v", w.Enemies)
+	}
+	if got := w.Enemies[0].HP; got != 475 {
+		t.Fatalf("expected Whip to hit enemy 2 for 25, HP = %.2f", got)
+	}
+}
+
+func TestWeaponSlotsKeepIndependentCooldowns(t *testing.T) {
+	w := newCombatWorld(t,
+		world.WeaponSlot{Kind: world.WeaponWhip, Level: 1},
+		world.WeaponSlot{Kind: world.WeaponFang, Level: 1},
+	)
+	w.Enemies = []world.Enemy{
+		{ID: 1, Pos: world.Vec2{X: 1050, Y: 1000}, R: 9, HP: 5000, MaxHP: 5000},
+	}
+
+	const dt = float32(1.0 / 60.0)
+	hits := 0
+	prevHP := w.Enemies[0].HP
+	for range 60 {
+		w.Tick(dt)
+		if w.Enemies[0].HP < prevHP {
+			hits++
+		}
+		prevHP = w.Enemies[0].HP
+	}
+
+	// Whip fires at 0, 0.45 and 0.9s; Fang at 0 and 0.585s. Tick 0 has both
+	// hits at once.
+	if hits != 4 {
+		t.Fatalf("expected 4 ticks with hits in 1s, got %d", hits)
+	}
+	whip, fang := w.Player.Weapons[0], w.Player.Weapons[1]
+	if whip.Timer == fang.Timer {
+		t.Fatalf("expected slot timers to run independently, both at %.3f", whip.Timer)
+	}
+	if want := 5000 - 25*3 - 25*1.35*2; !approxEqual(w.Enemies[0].HP, float32(want)) {
+		t.Fatalf("enemy HP = %.2f, want %.2f", w.Enemies[0].HP, want)
+	}
+}
+
+func TestWeaponDropPickupRules(t *testing.T) {
+	pick := func(w *world.World, kind world.WeaponKind) {
+		w.Drops = []world.WeaponDrop{{Pos: w.Player.Pos, R: 8, Kind: kind}}
+		w.Tick(1.0 / 60.0)
+	}
+
+	w := newCombatWorld(t, world.WeaponSlot{Kind: world.WeaponWhip, Level: 1})
+	w.Cfg.WeaponSlots = 2
+
+	pick(w, world.WeaponWhip)
+	if len(w.Player.Weapons) != 1 || w.Player.Weapons[0].Level != 2 {
+		t.Fatalf("duplicate pickup should level the slot: %+v", w.Player.Weapons)
+	}
+
+	pick(w, world.WeaponSpear)
+	if len(w.Player.Weapons) != 2 || w.Player.Weapons[1].Kind != world.WeaponSpear {
+		t.Fatalf("new weapon should take a free slot: %+v", w.Player.Weapons)
+	}
+
+	// Inventory is full: Nova levels the lowest-level slot (Spear).
+	pick(w, world.WeaponNova)
+	if w.Player.HasWeapon(world.WeaponNova) || w.Player.Weapons[1].Level != 2 || len(w.Drops) != 0 {
+		t.Fatalf("full inventory should level the lowest slot: %+v drops=%d", w.Player.Weapons, len(w.Drops))
+	}
+
+	for i := range w.Player.Weapons {
+		w.Player.Weapons[i].Level = world.MaxWeaponLevel
+	}
+	pick(w, world.WeaponNova)
+	if len(w.Drops) != 1 {
+		t.Fatalf("drop should stay on the ground when every slot is maxed, drops=%d", len(w.Drops))
+	}
+}
+
+func TestMultiWeaponRunsAreDeterministic(t *testing.T) {
+	run := func() *world.World {
+		w := world.NewWorld(2000, 2000)
+		t.Cleanup(w.Close)
+		for _, kind := range []world.WeaponKind{world.WeaponSpear, world.WeaponNova, world.WeaponFang} {
+			w.Player.Weapons = append(w.Player.Weapons, world.WeaponSlot{Kind: kind, Level: 1})
+		}
+		for range 900 {
+			w.Enqueue(world.MsgInput{})
+			w.Tick(1.0 / 60.0)
+		}
+		return w
+	}
+
+	a, b := run(), run()
+	if a.StateHash() != b.StateHash() {
+		t.Fatalf("multi-weapon runs diverged: %v", world.DiffSnapshots(a.BuildSnapshot(), b.BuildSnapshot()))
+	}
+	if a.Stats.EnemiesKilled == 0 {
+		t.Fatal("expected the weapons to kill something")
+	}
+}
+
+func chooseUpgrade(w *world.World, opt world.UpgradeOption) {
+	w.Upgrade = world.UpgradeMenu{Active: true, Pending: 1, Options: []world.UpgradeOption{opt, opt}}
+	w.Enqueue(world.MsgChooseUpgrade{Choice: 0})
+	w.Tick(1.0 / 60.0)
+}
+
+func TestWeaponEvolvesOnceMaxedWithPassive(t *testing.T) {
+	w := newCombatWorld(t, world.WeaponSlot{Kind: world.WeaponWhip, Level: 7})
+
+	chooseUpgrade(w, world.UpgradeOption{Kind: world.UpWeaponLevel, Weapon: world.WeaponWhip})
+	if got := w.Player.Weapons[0]; got.Kind != world.WeaponWhip || got.Level != 8 || !got.MaxLevel() {
+		t.Fatalf("expected a max-level whip without Might, got %+v", got)
+	}
+
+	chooseUpgrade(w, world.UpgradeOption{Kind: world.UpDamage})
+	if got := w.Player.Weapons[0]; got.Kind != world.WeaponChainLash || got.Level != 1 {
+		t.Fatalf("expected whip to evolve into Chain Lash once Might is owned, got %+v", got)
+	}
+	if w.Player.PassiveLevel(world.PassiveMight) != 1 {
+		t.Fatalf("expected +Damage to grant Might, passives=%+v", w.Player.Passives)
+	}
+}
+
+func TestWeaponEvolvesWhenPassiveComesFirst(t *testing.T) {
+	w := newCombatWorld(t, world.WeaponSlot{Kind: world.WeaponSpear, Level: 7})
+	chooseUpgrade(w, world.UpgradeOption{Kind: world.UpAttackSpeed})
+	if w.Player.Weapons[0].Kind != world.WeaponSpear {
+		t.Fatalf("spear evolved before reaching max level: %+v", w.Player.Weapons[0])
+	}
+
+	// A spear drop levels the owned spear to max and completes the recipe.
+	w.Drops = []world.WeaponDrop{{Pos: w.Player.Pos, R: 8, Kind: world.WeaponSpear}}
+	w.Tick(1.0 / 60.0)
+	if got := w.Player.Weapons[0]; got.Kind != world.WeaponGungnir {
+		t.Fatalf("expected Gungnir after maxing spear with Haste, got %+v", got)
+	}
+
+	// Further spear drops no longer add a plain spear next to Gungnir.
+	w.Drops = []world.WeaponDrop{{Pos: w.Player.Pos, R: 8, Kind: world.WeaponSpear}}
+	w.Tick(1.0 / 60.0)
+	if len(w.Player.Weapons) != 1 {
+		t.Fatalf("spear drop added a slot next to its evolution: %+v", w.Player.Weapons)
+	}
+}
+
+func TestWeaponLevelTablesAccumulate(t *testing.T) {
+	if got := world.WeaponStatsAt(world.WeaponSpear, 1).Targets; got != 2 {
+		t.Fatalf("spear level 1 targets = %d, want 2", got)
+	}
+	if got := world.WeaponStatsAt(world.WeaponSpear, 8).Targets; got != 4 {
+		t.Fatalf("spear level 8 targets = %d, want 4", got)
+	}
+	l1, l8 := world.WeaponStatsAt(world.WeaponWhip, 1), world.WeaponStatsAt(world.WeaponWhip, 8)
+	if l8.DamageMul <= l1.DamageMul || l8.CooldownMul >= l1.CooldownMul {
+		t.Fatalf("whip did not improve from level 1 to 8: %+v -> %+v", l1, l8)
+	}
+	if world.WeaponStatsAt(world.WeaponWhip, 99) != l8 {
+		t.Fatal("levels past the table should clamp to the max level")
+	}
+}
+
+func TestEvolvedLineAttackHitsEveryEnemyInLine(t *testing.T) {
+	w := newCombatWorld(t, world.WeaponSlot{Kind: world.WeaponGungnir, Level: 1})
+	w.Enemies = []world.Enemy{
+		{ID: 1, Pos: world.Vec2{X: 1060, Y: 1000}, R: 9, HP: 500, MaxHP: 500},
+		{ID: 2, Pos: world.Vec2{X: 1200, Y: 1010}, R: 9, HP: 500, MaxHP: 500},
+		{ID: 3, Pos: world.Vec2{X: 1100, Y: 1080}, R: 9, HP: 500, MaxHP: 500},
+	}
+
+	w.Tick(1.0 / 60.0)
+
+	for _, e := range w.Enemies {
+		if hit := e.HP < e.MaxHP; hit != (e.ID != 3) {
+			t.Fatalf("expected enemies 1 and 2 on the line to be hit, got %+v", w.Enemies)
+		}
+	}
+}
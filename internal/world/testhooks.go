@@ -3,22 +3,77 @@ package world
 import "horde-lab/internal/jobs"
 
 func (w *World) TestOnlyDisableAIPool() {
-	if w.aiPool != nil {
-		w.aiPool.Close()
-		w.aiPool = nil
+	if w.pool != nil {
+		w.pool.Close()
+		w.pool = nil
 	}
 }
 
 func (w *World) TestOnlySetAIReadyResult(res jobs.IntentResult) {
-	if w.aiReadyResults == nil {
-		w.aiReadyResults = make(map[uint64]jobs.IntentResult, 8)
-	}
-	w.aiReadyResults[res.Tick] = res
+	w.aiIntents.Deliver(res.Tick, res)
 }
 
 func (w *World) TestOnlySetAIPendingRequest(req jobs.IntentRequest) {
-	if w.aiPendingRequests == nil {
-		w.aiPendingRequests = make(map[uint64]jobs.IntentRequest, 8)
+	w.aiIntents.Track(req.Tick, req)
+}
+
+func (w *World) TestOnlyRandFloat32() float32 {
+	return w.randFloat32()
+}
+
+func (w *World) TestOnlyRandIntn(n int) int {
+	return w.randIntn(n)
+}
+
+func (w *World) TestOnlyBuildWave(index int) WaveState {
+	return buildWaveState(w.Cfg, index, w.rngSeed)
+}
+
+func (w *World) TestOnlyApplyEnemyStatus(idx int, a StatusApply) {
+	w.applyEnemyStatuses(idx, []StatusApply{a})
+}
+
+func (w *World) TestOnlyRollElite(idx int) {
+	w.rollElite(idx)
+}
+
+func (w *World) TestOnlyTakePassive(kind PassiveKind) {
+	w.takePassive(kind)
+}
+
+func (w *World) TestOnlyAddBuff(mod StatMod, seconds float32) {
+	w.Player.addBuff(mod, seconds)
+}
+
+func (w *World) TestOnlyMoveEntity(from, to Vec2, r float32) Vec2 {
+	return w.moveEntity(from, to, r)
+}
+
+func (w *World) TestOnlyLineOfSight(a, b Vec2) bool {
+	return w.lineOfSight(a, b)
+}
+
+func (w *World) TestOnlyDisableChunkStreamer() {
+	w.chunks.close()
+}
+
+func (w *World) TestOnlyStallAIPool() {
+	if w.pool != nil {
+		w.pool.Close()
 	}
-	w.aiPendingRequests[req.Tick] = req
+	// stopped workers leave every request to the synchronous fallback
+	w.pool = newWorldJobs()
+	w.pool.Close()
+}
+
+func TestOnlySetDefaultEnemyContent(c EnemyContent) (restore func()) {
+	old := defaultEnemyContent
+	defaultEnemyContent = c
+	return func() { defaultEnemyContent = old }
+}
+
+func TestOnlySetDefaultPickupContent(c PickupContent) (restore func()) {
+	old := defaultPickupContent
+	defaultPickupContent = c
+	return func() { defaultPickupContent = old }
 }
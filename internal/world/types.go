@@ -19,3 +19,4 @@ func (v Vec2) Norm() Vec2 {
 func (v Vec2) Add(o Vec2) Vec2    { return Vec2{v.X + o.X, v.Y + o.Y} }
 func (v Vec2) Sub(o Vec2) Vec2    { return Vec2{v.X - o.X, v.Y - o.Y} }
 func (v Vec2) Mul(s float32) Vec2 { return Vec2{v.X * s, v.Y * s} }
+func (v Vec2) Dot(o Vec2) float32 { return v.X*o.X + v.Y*o.Y }
@@ -1,67 +1,253 @@
 package world
 
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+)
+
 type UpgradeKind int
 
 const (
 	UpDamage UpgradeKind = iota
 	UpAttackSpeed
 	UpMagnet
+	UpWeaponLevel
+	UpPassive
+	UpNewWeapon // takes a free weapon slot
+	UpHeal      // fallback once the pools run dry
+	UpGold      // fallback once the pools run dry
+)
+
+// UpgradeRarity is rolled per option. Each tier grants one more level (or
+// one more portion of HP or gold) than the last.
+type UpgradeRarity int
+
+const (
+	RarityCommon UpgradeRarity = iota
+	RarityRare
+	RarityEpic
+)
+
+var rarityNames = map[UpgradeRarity]string{RarityRare: "Rare", RarityEpic: "Epic"}
+
+// Base chances of a rare and an epic roll; Player.Luck scales both.
+const (
+	rareChance = 0.15
+	epicChance = 0.03
+)
+
+// maxUpgradeChoices caps an offer. Luck past 1 is the chance of one extra
+// choice on top of Config.UpgradeChoices.
+const maxUpgradeChoices = 4
+
+// What one level of the fallback options grants.
+const (
+	healPerLevel = 30
+	goldPerLevel = 25
 )
 
+// upgradePoolWeights is the draw weight of every option in a pool.
+var upgradePoolWeights = map[UpgradeKind]int{
+	UpNewWeapon:   3,
+	UpWeaponLevel: 4,
+	UpPassive:     2,
+	UpDamage:      2,
+	UpAttackSpeed: 2,
+	UpMagnet:      2,
+}
+
 type UpgradeOption struct {
-	Kind  UpgradeKind
-	Title string
-	Desc  string
+	Kind    UpgradeKind
+	Weapon  WeaponKind  // weapon to add or level for UpNewWeapon and UpWeaponLevel
+	Passive PassiveKind // passive to take for UpPassive
+	Rarity  UpgradeRarity
+	Title   string
+	Desc    string
+}
+
+// UpgradeKey is what an option upgrades, regardless of level and rarity.
+type UpgradeKey struct {
+	Kind    UpgradeKind
+	Weapon  WeaponKind
+	Passive PassiveKind
+}
+
+func (o UpgradeOption) key() UpgradeKey {
+	return UpgradeKey{Kind: o.Kind, Weapon: o.Weapon, Passive: o.Passive}
+}
+
+// upgradePassives maps stat upgrades to the passive they count towards.
+var upgradePassives = map[UpgradeKind]PassiveKind{
+	UpDamage:      PassiveMight,
+	UpAttackSpeed: PassiveHaste,
+	UpMagnet:      PassiveMagnet,
 }
 
 type UpgradeMenu struct {
 	Active  bool
-	Options [2]UpgradeOption
+	Options []UpgradeOption
 	Pending int // how many level-up choices still need to be picked
+
+	// charges left this run, and the options banishing removed for good
+	Rerolls  int
+	Skips    int
+	Banishes int
+	Banished []UpgradeKey
+}
+
+func (m UpgradeMenu) clone() UpgradeMenu {
+	m.Options = slices.Clone(m.Options)
+	m.Banished = slices.Clone(m.Banished)
+	return m
 }
 
 func (w *World) openUpgradeMenuIfNeeded() {
 	if w.Upgrade.Pending <= 0 || w.Upgrade.Active {
 		return
 	}
+	w.rollUpgradeOptions()
+	w.Upgrade.Active = true
+}
 
-	// v0.1: always present two fixed choices
+// upgradeCandidates lists, in pool order, every option the player could be
+// offered right now. Banished options are left out.
+func (w *World) upgradeCandidates() []UpgradeOption {
+	p := &w.Player
 	pool := []UpgradeOption{
-		{
-			Kind:  UpDamage,
-			Title: "1) +Damage",
-			Desc:  "Increase damage by +10",
-		},
-		{
-			Kind:  UpAttackSpeed,
-			Title: "2) Faster Attack",
-			Desc:  "Reduce attack cooldown by 15%",
-		},
-		{
-			Kind:  UpMagnet,
-			Title: "Magnet",
-			Desc:  "Increase XP pickup radius by +15",
-		},
-	}
-
-	// pick 2 distinct options from pool
-	// First pick:
-	i := w.randIntn(len(pool))
-	first := pool[i]
-	pool = append(pool[:i], pool[i+1:]...)
-
-	// Second pick:
-	j := w.randIntn(len(pool))
-	second := pool[j]
-
-	// Assign to menu. We want keys 1 and 2 to always correspond.
-	// Ensure the titles match "1)" and "2)".
-	first.Title = "1) " + stripPrefix(first.Title)
-	second.Title = "2) " + stripPrefix(second.Title)
-
-	w.Upgrade.Options[0] = first
-	w.Upgrade.Options[1] = second
-	w.Upgrade.Active = true
+		{Kind: UpDamage, Title: "+Damage", Desc: passiveDefs[PassiveMight].Desc},
+		{Kind: UpAttackSpeed, Title: "Faster Attack", Desc: passiveDefs[PassiveHaste].Desc},
+		{Kind: UpMagnet, Title: "Magnet", Desc: passiveDefs[PassiveMagnet].Desc},
+	}
+	if len(p.Weapons) < w.Cfg.WeaponSlots {
+		for _, kind := range weaponOrder {
+			if !p.HasWeapon(kind) {
+				pool = append(pool, UpgradeOption{
+					Kind:   UpNewWeapon,
+					Weapon: kind,
+					Title:  "New " + WeaponName(kind),
+					Desc:   "Add to a free weapon slot",
+				})
+			}
+		}
+	}
+	for _, slot := range p.Weapons {
+		if slot.MaxLevel() {
+			continue
+		}
+		pool = append(pool, UpgradeOption{
+			Kind:   UpWeaponLevel,
+			Weapon: slot.Kind,
+			Title:  fmt.Sprintf("%s Lv %d", WeaponName(slot.Kind), slot.Level+1),
+			Desc:   describeWeaponLevel(weaponDef(slot.Kind).Levels[slot.Level-1]),
+		})
+	}
+	for _, kind := range offeredPassives {
+		if !p.passiveMaxed(kind) {
+			pool = append(pool, passiveOffer(p, kind))
+		}
+	}
+	return slices.DeleteFunc(pool, func(o UpgradeOption) bool {
+		return slices.Contains(w.Upgrade.Banished, o.key())
+	})
+}
+
+// rollUpgradeOptions draws a fresh offer: weighted picks without repeats,
+// topped up with heal and gold when the pools run dry, each with its own
+// rarity roll.
+func (w *World) rollUpgradeOptions() {
+	n := max(w.Cfg.UpgradeChoices, 2)
+	if n < maxUpgradeChoices && w.Player.Luck > 1 && w.randFloat32() < w.Player.Luck-1 {
+		n++
+	}
+	n = min(n, maxUpgradeChoices)
+
+	pool := w.upgradeCandidates()
+	opts := make([]UpgradeOption, 0, n)
+	for len(opts) < n && len(pool) > 0 {
+		i := w.pickUpgrade(pool)
+		opts = append(opts, pool[i])
+		pool = slices.Delete(pool, i, i+1)
+	}
+	if len(opts) < n {
+		opts = append(opts, UpgradeOption{Kind: UpHeal, Title: "Heal"})
+	}
+	if len(opts) < n {
+		opts = append(opts, UpgradeOption{Kind: UpGold, Title: "Gold"})
+	}
+
+	for i := range opts {
+		o := &opts[i]
+		o.Rarity = min(w.rollRarity(), UpgradeRarity(w.upgradeRoom(*o)-1))
+		levels := o.Rarity.levels()
+		switch o.Kind {
+		case UpHeal:
+			o.Desc = fmt.Sprintf("Restore %d HP", healPerLevel*levels)
+		case UpGold:
+			o.Desc = fmt.Sprintf("Gain %d gold", goldPerLevel*levels)
+		default:
+			if levels > 1 {
+				o.Desc += fmt.Sprintf(" (x%d)", levels)
+			}
+		}
+		if name, ok := rarityNames[o.Rarity]; ok {
+			o.Title = name + " " + o.Title
+		}
+		// keys 1-4 always match the option order
+		o.Title = fmt.Sprintf("%d) %s", i+1, o.Title)
+	}
+	w.Upgrade.Options = opts
+}
+
+// pickUpgrade draws a weighted index into pool.
+func (w *World) pickUpgrade(pool []UpgradeOption) int {
+	total := 0
+	for _, o := range pool {
+		total += upgradePoolWeights[o.Kind]
+	}
+	roll := w.randIntn(total)
+	for i, o := range pool {
+		if roll < upgradePoolWeights[o.Kind] {
+			return i
+		}
+		roll -= upgradePoolWeights[o.Kind]
+	}
+	return len(pool) - 1
+}
+
+func (w *World) rollRarity() UpgradeRarity {
+	r := w.randFloat32()
+	switch {
+	case r < epicChance*w.Player.Luck:
+		return RarityEpic
+	case r < rareChance*w.Player.Luck:
+		return RarityRare
+	}
+	return RarityCommon
+}
+
+// levels is how many times an option of this rarity applies.
+func (r UpgradeRarity) levels() int { return int(r) + 1 }
+
+// upgradeRoom is how many levels opt can still grant, so a rarity roll
+// never promises more than the option can give.
+func (w *World) upgradeRoom(opt UpgradeOption) int {
+	p := &w.Player
+	switch opt.Kind {
+	case UpNewWeapon:
+		return weaponDef(opt.Weapon).MaxLevel()
+	case UpWeaponLevel:
+		if i := p.weaponSlot(opt.Weapon); i >= 0 {
+			return weaponDef(p.Weapons[i].Kind).MaxLevel() - p.Weapons[i].Level
+		}
+		return 1
+	case UpPassive:
+		if limit := passiveDefs[opt.Passive].MaxLevel; limit > 0 {
+			return limit - p.PassiveLevel(opt.Passive)
+		}
+	}
+	return math.MaxInt
 }
 
 func (w *World) applyUpGradeChoice(choice int) {
@@ -69,26 +255,48 @@ func (w *World) applyUpGradeChoice(choice int) {
 		return
 	}
 
-	if choice < 0 || choice > 1 {
+	if choice < 0 || choice >= len(w.Upgrade.Options) {
 		return
 	}
 
 	opt := w.Upgrade.Options[choice]
+	for range opt.Rarity.levels() {
+		w.applyUpgrade(opt)
+	}
+	w.finishUpgradeChoice()
+}
 
+// applyUpgrade applies one level of opt.
+func (w *World) applyUpgrade(opt UpgradeOption) {
+	p := &w.Player
+
+	// stat upgrades only add passives; the stat pipeline turns them into stats
+	passive, ok := upgradePassives[opt.Kind]
 	switch opt.Kind {
-	case UpDamage:
-		w.Player.Damage += 10
-	case UpAttackSpeed:
-		// Lower cooldown means faster attacks. Clamp to avoid going to 0
-		w.Player.AttackCooldown = maxf(0.12, w.Player.AttackCooldown*0.85)
-		// If timer is loner than new cooldown, clamp it too
-		if w.Player.AttackTimer > w.Player.AttackCooldown {
-			w.Player.AttackTimer = w.Player.AttackCooldown
+	case UpWeaponLevel:
+		if i := p.weaponSlot(opt.Weapon); i >= 0 {
+			w.levelWeaponSlot(i)
+		}
+	case UpNewWeapon:
+		if i := p.weaponSlot(opt.Weapon); i >= 0 {
+			w.levelWeaponSlot(i)
+		} else if len(p.Weapons) < w.Cfg.WeaponSlots {
+			p.Weapons = append(p.Weapons, WeaponSlot{Kind: opt.Weapon, Level: 1})
 		}
-	case UpMagnet:
-		w.Player.XPMagnet += 15
+	case UpPassive:
+		passive, ok = opt.Passive, !p.passiveMaxed(opt.Passive)
+	case UpHeal:
+		p.HP = min(p.MaxHP, p.HP+healPerLevel)
+	case UpGold:
+		p.Gold += goldPerLevel
+	}
+	if ok {
+		w.takePassive(passive)
 	}
+}
 
+// finishUpgradeChoice consumes one pending choice and opens the next offer.
+func (w *World) finishUpgradeChoice() {
 	// consume one pending upgrade choice
 	if w.Upgrade.Pending > 0 {
 		w.Upgrade.Pending--
@@ -104,16 +312,89 @@ func (w *World) applyUpGradeChoice(choice int) {
 	w.Upgrade.Active = false
 }
 
+// rerollUpgrades spends a reroll on a fresh offer.
+func (w *World) rerollUpgrades() {
+	m := &w.Upgrade
+	if !m.Active || m.Rerolls <= 0 {
+		return
+	}
+	m.Rerolls--
+	w.rollUpgradeOptions()
+}
+
+// skipUpgrade spends a skip to pass on the current offer.
+func (w *World) skipUpgrade() {
+	m := &w.Upgrade
+	if !m.Active || m.Skips <= 0 {
+		return
+	}
+	m.Skips--
+	w.finishUpgradeChoice()
+}
+
+// banishUpgrade spends a banish to remove an option from every later offer,
+// then rerolls the current one. Heal and gold cannot be banished.
+func (w *World) banishUpgrade(choice int) {
+	m := &w.Upgrade
+	if !m.Active || m.Banishes <= 0 || choice < 0 || choice >= len(m.Options) {
+		return
+	}
+	opt := m.Options[choice]
+	if opt.Kind == UpHeal || opt.Kind == UpGold {
+		return
+	}
+	m.Banishes--
+	m.Banished = append(m.Banished, opt.key())
+	w.rollUpgradeOptions()
+}
+
+// takePassive adds a level of kind and applies it right away: extra max HP
+// comes with matching HP, and weapon timers longer than the new cooldown
+// are cut short.
+func (w *World) takePassive(kind PassiveKind) {
+	p := &w.Player
+	maxHP := p.MaxHP
+	p.addPassive(kind)
+	p.refreshStats()
+	if gain := p.MaxHP - maxHP; gain > 0 {
+		p.HP += gain
+	}
+	for i := range p.Weapons {
+		slot := &p.Weapons[i]
+		slot.Timer = minf(slot.Timer, p.WeaponCooldown(*slot))
+	}
+	w.evolveWeapons()
+}
+
+// describeWeaponLevel lists a level's stat changes, e.g. "+10% damage, +1 target".
+func describeWeaponLevel(l WeaponLevel) string {
+	var parts []string
+	pct := func(v float32, what string) {
+		if v != 0 {
+			parts = append(parts, fmt.Sprintf("%+.0f%% %s", v*100, what))
+		}
+	}
+	pct(l.DamageMul, "damage")
+	pct(l.CooldownMul, "cooldown")
+	pct(l.RangeMul, "range")
+	if l.AttackRadius != 0 {
+		parts = append(parts, fmt.Sprintf("%+.0f area", l.AttackRadius))
+	}
+	count := func(v int, what string) {
+		if v != 0 {
+			parts = append(parts, fmt.Sprintf("%+d %s", v, what))
+		}
+	}
+	count(l.Targets, "target")
+	count(l.Projectiles, "projectile")
+	count(l.Pierce, "pierce")
+	count(l.Bounces, "bounce")
+	return strings.Join(parts, ", ")
+}
+
 func maxf(a, b float32) float32 {
 	if a > b {
 		return a
 	}
 	return b
 }
-
-func stripPrefix(s string) string {
-	if len(s) >= 3 && (s[0] == '1' || s[0] == '2') && s[1] == ')' {
-		return s[3:]
-	}
-	return s
-}
@@ -11,46 +11,70 @@ func buildWaveState(cfg Config, index int, seed int64) WaveState {
 	}
 
 	seedBias := positiveModInt(int(seed), 4)
-	runnerWeight := minInt(6, 1+(index-1)/2+(seedBias%2))
-	tankWeight := 0
-	if index >= 3 {
-		tankWeight = minInt(4, 1+(index-3)/2+seedBias/2)
+	spawns := make([]WaveSpawn, 0, len(cfg.Enemies))
+	var guarantees []WaveGuarantee
+	label := ""
+	for _, a := range cfg.Enemies {
+		if a.Boss != nil {
+			continue
+		}
+		weight := a.Spawn.weight(index, seedBias)
+		if weight <= 0 {
+			continue
+		}
+		spawns = append(spawns, WaveSpawn{Kind: a.ID, Weight: weight})
+		if a.Guarantee != nil {
+			guarantees = append(guarantees, WaveGuarantee{Kind: a.ID, Every: a.Guarantee.every(index, seedBias)})
+		}
+		if label == "" && a.Surge != nil && weight >= a.Surge.MinWeight {
+			label = a.Surge.Label
+		}
 	}
-	normalWeight := maxInt(2, 7-index/3-seedBias/3)
-	guaranteedTankAt := 0
-	if tankWeight > 0 {
-		guaranteedTankAt = maxInt(6, 18-index*2-seedBias)
+	if label == "" {
+		label = waveLabel(index)
+	}
+	boss := bossForWave(cfg, index)
+	if a, ok := cfg.Archetype(boss); ok {
+		label = a.Boss.Label
 	}
 
 	return WaveState{
-		Index:            index,
-		Label:            waveLabel(index, runnerWeight, tankWeight),
-		StartTime:        float32(index-1) * duration,
-		Duration:         duration,
-		SpawnRateScale:   1 + 0.14*float32(index-1),
-		NormalWeight:     normalWeight,
-		RunnerWeight:     runnerWeight,
-		TankWeight:       tankWeight,
-		GuaranteedTankAt: guaranteedTankAt,
+		Index:          index,
+		Label:          label,
+		StartTime:      float32(index-1) * duration,
+		Duration:       duration,
+		SpawnRateScale: 1 + 0.14*float32(index-1),
+		Spawns:         spawns,
+		Guarantees:     guarantees,
+		Boss:           boss,
+	}
+}
+
+// Weight returns kind's spawn weight in the wave.
+func (s WaveState) Weight(kind EnemyKind) int {
+	for _, sp := range s.Spawns {
+		if sp.Kind == kind {
+			return sp.Weight
+		}
 	}
+	return 0
 }
 
 func buildWaveStateForTime(cfg Config, t float32, seed int64) WaveState {
+	return buildWaveState(cfg, waveIndexAt(cfg, t), seed)
+}
+
+func waveIndexAt(cfg Config, t float32) int {
 	duration := cfg.WaveDuration
 	if duration <= 0 {
 		duration = 20
 	}
-
-	index := 1 + int(t/duration)
-	return buildWaveState(cfg, index, seed)
+	return 1 + int(t/duration)
 }
 
-func waveLabel(index, runnerWeight, tankWeight int) string {
+// waveLabel names waves without an archetype surge.
+func waveLabel(index int) string {
 	switch {
-	case tankWeight >= 3:
-		return "Bulwark Surge"
-	case runnerWeight >= 5:
-		return "Raptor Swarm"
 	case index%4 == 0:
 		return "Harvest Moon"
 	case index%3 == 0:
@@ -60,25 +84,23 @@ func waveLabel(index, runnerWeight, tankWeight int) string {
 	}
 }
 
+// updateWaveState enters the wave TimeSurvived falls in, using the state
+// prefetched on the pool when the previous wave began.
 func (w *World) updateWaveState() {
-	next := buildWaveStateForTime(w.Cfg, w.TimeSurvived, w.rngSeed)
-	if next.Index != w.Wave.Index {
-		w.Wave = next
+	index := max(waveIndexAt(w.Cfg, w.TimeSurvived), 1)
+	if index == w.Wave.Index {
+		return
 	}
-}
-
-func maxInt(a, b int) int {
-	if a > b {
-		return a
+	next, ok := w.waves.Take(uint64(index))
+	if !ok {
+		next = buildWaveState(w.Cfg, index, w.rngSeed)
 	}
-	return b
-}
-
-func minInt(a, b int) int {
-	if a < b {
-		return a
+	w.waves.Prune(uint64(index))
+	w.Wave = next
+	if next.Boss != "" {
+		w.spawnBoss(next.Boss)
 	}
-	return b
+	w.prefetchWave(index + 1)
 }
 
 func positiveModInt(v, m int) int {
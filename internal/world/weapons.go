@@ -1,5 +1,7 @@
 package world
 
+import "slices"
+
 type WeaponKind int
 
 const (
@@ -7,6 +9,17 @@ const (
 	WeaponSpear
 	WeaponNova
 	WeaponFang
+
+	// evolutions, see weaponEvolutions
+	WeaponChainLash
+	WeaponGungnir
+	WeaponVoidNova
+	WeaponTwinFang
+
+	// projectile weapons
+	WeaponBolt
+	WeaponOrbit
+	WeaponSickle
 )
 
 type WeaponDef struct {
@@ -17,17 +30,69 @@ type WeaponDef struct {
 	RangeMul     float32
 	DropWeight   int
 	DropRadius   float32
-	AttackRadius float32 // used by radial attack styles
+	AttackRadius float32 // radial and vortex radius, chain jump range, line half-width
+	Targets      int     // enemies hit per attack by multi-target styles
+	Pull         float32 // vortex pull distance per hit
+	Inflicts     []StatusApply
+
+	// Projectile configures AttackProjectile, AttackOrbit and
+	// AttackBoomerang weapons; AttackRadius is the orbit radius.
+	Projectile ProjectileDef
+
+	// Levels[i] is added on reaching level i+2, so a weapon maxes out at
+	// len(Levels)+1.
+	Levels []WeaponLevel
+}
+
+// ProjectileDef describes the projectiles one attack fires.
+type ProjectileDef struct {
+	Count   int     // projectiles per attack
+	Speed   float32 // px/s; rad/s for orbiters
+	Life    float32 // seconds
+	Radius  float32
+	Pierce  int     // extra enemies a projectile passes through
+	Bounces int     // ricochets off obstacles or on to the next enemy
+	Homing  float32 // max turn rate towards the nearest enemy, rad/s
+	Spread  float32 // radians between projectiles of one attack
+}
+
+// WeaponLevel is the stat change a weapon gains on reaching one level.
+type WeaponLevel struct {
+	DamageMul    float32
+	CooldownMul  float32 // negative attacks faster
+	RangeMul     float32
+	AttackRadius float32
+	Targets      int
+	Projectiles  int
+	Pierce       int
+	Bounces      int
 }
 
+// MaxLevel is the highest level the weapon can reach.
+func (d WeaponDef) MaxLevel() int { return len(d.Levels) + 1 }
+
 type WeaponAttackStyle int
 
 const (
 	AttackSingle WeaponAttackStyle = iota
 	AttackPierce
 	AttackRadial
+	AttackChain  // hits the nearest enemy, then jumps to the nearest unhit one
+	AttackLine   // hits every enemy on the segment towards the nearest enemy
+	AttackVortex // radial hit that also drags enemies towards the player
+	AttackMulti  // one hit on each of the nearest Targets enemies
+
+	// Projectile styles spawn PlayerProjectiles instead of hitting at once.
+	AttackProjectile // fired at the nearest enemy, may pierce, bounce and home
+	AttackOrbit      // circles the player for the projectile's lifetime
+	AttackBoomerang  // flies out, slows to a stop at range and returns
 )
 
+// Projectile reports whether the style fires PlayerProjectiles.
+func (s WeaponAttackStyle) Projectile() bool {
+	return s == AttackProjectile || s == AttackOrbit || s == AttackBoomerang
+}
+
 var weaponDefs = map[WeaponKind]WeaponDef{
 	WeaponWhip: {
 		Name:        "Whip",
@@ -37,6 +102,15 @@ var weaponDefs = map[WeaponKind]WeaponDef{
 		RangeMul:    1.00,
 		DropWeight:  30,
 		DropRadius:  9,
+		Levels: []WeaponLevel{
+			{DamageMul: 0.10},
+			{RangeMul: 0.10},
+			{DamageMul: 0.15},
+			{CooldownMul: -0.08},
+			{DamageMul: 0.15},
+			{RangeMul: 0.10},
+			{DamageMul: 0.20},
+		},
 	},
 	WeaponSpear: {
 		Name:        "Spear",
@@ -46,6 +120,16 @@ var weaponDefs = map[WeaponKind]WeaponDef{
 		RangeMul:    1.15,
 		DropWeight:  26,
 		DropRadius:  8,
+		Targets:     2,
+		Levels: []WeaponLevel{
+			{DamageMul: 0.10},
+			{Targets: 1},
+			{RangeMul: 0.10},
+			{DamageMul: 0.10},
+			{Targets: 1},
+			{CooldownMul: -0.08},
+			{DamageMul: 0.15},
+		},
 	},
 	WeaponNova: {
 		Name:         "Blood Nova",
@@ -56,6 +140,16 @@ var weaponDefs = map[WeaponKind]WeaponDef{
 		AttackRadius: 108,
 		DropWeight:   16,
 		DropRadius:   11,
+		Targets:      64,
+		Levels: []WeaponLevel{
+			{AttackRadius: 10},
+			{DamageMul: 0.08},
+			{CooldownMul: -0.10},
+			{AttackRadius: 12},
+			{DamageMul: 0.08},
+			{CooldownMul: -0.10},
+			{AttackRadius: 14, DamageMul: 0.10},
+		},
 	},
 	WeaponFang: {
 		Name:        "Fang Dagger",
@@ -65,14 +159,207 @@ var weaponDefs = map[WeaponKind]WeaponDef{
 		RangeMul:    0.80,
 		DropWeight:  22,
 		DropRadius:  7,
+		Levels: []WeaponLevel{
+			{DamageMul: 0.15},
+			{CooldownMul: -0.08},
+			{DamageMul: 0.15},
+			{RangeMul: 0.10},
+			{CooldownMul: -0.08},
+			{DamageMul: 0.20},
+			{DamageMul: 0.25},
+		},
+	},
+
+	WeaponChainLash: {
+		Name:         "Chain Lash",
+		AttackStyle:  AttackChain,
+		DamageMul:    1.90,
+		CooldownMul:  0.90,
+		RangeMul:     1.20,
+		AttackRadius: 110,
+		Targets:      5,
+		DropRadius:   9,
+		Inflicts:     []StatusApply{{Kind: StatusStun, Duration: 0.35}},
+	},
+	WeaponGungnir: {
+		Name:         "Gungnir",
+		AttackStyle:  AttackLine,
+		DamageMul:    1.60,
+		CooldownMul:  0.70,
+		RangeMul:     1.60,
+		AttackRadius: 16,
+		DropRadius:   8,
+		Inflicts:     []StatusApply{{Kind: StatusVuln, Magnitude: 0.15, Duration: 3}},
+	},
+	WeaponVoidNova: {
+		Name:         "Void Nova",
+		AttackStyle:  AttackVortex,
+		DamageMul:    0.95,
+		CooldownMul:  1.20,
+		RangeMul:     0.82,
+		AttackRadius: 170,
+		Targets:      96,
+		Pull:         28,
+		DropRadius:   11,
+		Inflicts:     []StatusApply{{Kind: StatusSlow, Magnitude: 0.5, Duration: 2}},
+	},
+	WeaponTwinFang: {
+		Name:        "Twin Fangs",
+		AttackStyle: AttackMulti,
+		DamageMul:   2.30,
+		CooldownMul: 1.00,
+		RangeMul:    0.95,
+		Targets:     3,
+		DropRadius:  7,
+		Inflicts:    []StatusApply{{Kind: StatusPoison, Magnitude: 4, Duration: 3}},
+	},
+
+	WeaponBolt: {
+		Name:        "Arcane Bolt",
+		AttackStyle: AttackProjectile,
+		DamageMul:   0.90,
+		CooldownMul: 1.20,
+		RangeMul:    1.40,
+		DropWeight:  20,
+		DropRadius:  8,
+		Projectile:  ProjectileDef{Count: 1, Speed: 460, Life: 1.2, Radius: 5, Bounces: 1, Homing: 5, Spread: 0.18},
+		Inflicts:    []StatusApply{{Kind: StatusBurn, Magnitude: 3, Duration: 2}},
+		Levels: []WeaponLevel{
+			{Projectiles: 1},
+			{DamageMul: 0.10},
+			{Pierce: 1},
+			{Projectiles: 1},
+			{CooldownMul: -0.10},
+			{Bounces: 1},
+			{DamageMul: 0.20},
+		},
+	},
+	WeaponOrbit: {
+		Name:         "Bone Ring",
+		AttackStyle:  AttackOrbit,
+		DamageMul:    0.60,
+		CooldownMul:  8.00,
+		RangeMul:     1.00,
+		AttackRadius: 70,
+		DropWeight:   18,
+		DropRadius:   10,
+		Projectile:   ProjectileDef{Count: 2, Speed: 3.4, Life: 3, Radius: 9},
+		Inflicts:     []StatusApply{{Kind: StatusFreeze, Duration: 0.6, Chance: 0.15}},
+		Levels: []WeaponLevel{
+			{Projectiles: 1},
+			{AttackRadius: 10},
+			{DamageMul: 0.10},
+			{Projectiles: 1},
+			{CooldownMul: -1.00},
+			{AttackRadius: 10},
+			{Projectiles: 1},
+		},
 	},
+	WeaponSickle: {
+		Name:        "Sickle",
+		AttackStyle: AttackBoomerang,
+		DamageMul:   1.10,
+		CooldownMul: 2.60,
+		RangeMul:    1.10,
+		DropWeight:  18,
+		DropRadius:  10,
+		Projectile:  ProjectileDef{Count: 1, Speed: 420, Life: 2.5, Radius: 10, Spread: 0.5},
+		Inflicts:    []StatusApply{{Kind: StatusSlow, Magnitude: 0.4, Duration: 1.5}},
+		Levels: []WeaponLevel{
+			{DamageMul: 0.10},
+			{Projectiles: 1},
+			{RangeMul: 0.10},
+			{DamageMul: 0.15},
+			{CooldownMul: -0.30},
+			{Projectiles: 1},
+			{DamageMul: 0.20},
+		},
+	},
+}
+
+// WeaponEvolution turns a max-level Base weapon into Into once the player
+// owns Passive.
+type WeaponEvolution struct {
+	Base    WeaponKind
+	Passive PassiveKind
+	Into    WeaponKind
+}
+
+var weaponEvolutions = []WeaponEvolution{
+	{Base: WeaponWhip, Passive: PassiveMight, Into: WeaponChainLash},
+	{Base: WeaponSpear, Passive: PassiveHaste, Into: WeaponGungnir},
+	{Base: WeaponNova, Passive: PassiveMagnet, Into: WeaponVoidNova},
+	{Base: WeaponFang, Passive: PassiveMight, Into: WeaponTwinFang},
+}
+
+// WeaponEvolutions lists the evolution recipes.
+func WeaponEvolutions() []WeaponEvolution {
+	return slices.Clone(weaponEvolutions)
 }
 
+// MaxWeaponLevel is the longest level table any weapon has.
+const MaxWeaponLevel = 8
+
+// WeaponSlot is one weapon in the player's inventory. Every slot fires on its
+// own cooldown; within a tick slots fire in inventory order.
+type WeaponSlot struct {
+	Kind  WeaponKind
+	Level int
+	Timer float32 // counts down to the next attack
+}
+
+// WeaponStats are a weapon's effective multipliers at one level.
+type WeaponStats struct {
+	DamageMul    float32
+	CooldownMul  float32
+	RangeMul     float32
+	AttackRadius float32
+	Targets      int
+	Projectiles  int
+	Pierce       int
+	Bounces      int
+}
+
+// WeaponStatsAt sums a weapon's level table up to level.
+func WeaponStatsAt(kind WeaponKind, level int) WeaponStats {
+	d := weaponDef(kind)
+	st := WeaponStats{
+		DamageMul:    d.DamageMul,
+		CooldownMul:  d.CooldownMul,
+		RangeMul:     d.RangeMul,
+		AttackRadius: d.AttackRadius,
+		Targets:      d.Targets,
+		Projectiles:  d.Projectile.Count,
+		Pierce:       d.Projectile.Pierce,
+		Bounces:      d.Projectile.Bounces,
+	}
+	for _, l := range d.Levels[:min(max(level-1, 0), len(d.Levels))] {
+		st.DamageMul += l.DamageMul
+		st.CooldownMul += l.CooldownMul
+		st.RangeMul += l.RangeMul
+		st.AttackRadius += l.AttackRadius
+		st.Targets += l.Targets
+		st.Projectiles += l.Projectiles
+		st.Pierce += l.Pierce
+		st.Bounces += l.Bounces
+	}
+	return st
+}
+
+// Stats returns the slot's weapon stats at its current level.
+func (s WeaponSlot) Stats() WeaponStats { return WeaponStatsAt(s.Kind, s.Level) }
+
+// MaxLevel reports whether the slot cannot level further.
+func (s WeaponSlot) MaxLevel() bool { return s.Level >= weaponDef(s.Kind).MaxLevel() }
+
 var weaponOrder = []WeaponKind{
 	WeaponWhip,
 	WeaponSpear,
 	WeaponNova,
 	WeaponFang,
+	WeaponBolt,
+	WeaponOrbit,
+	WeaponSickle,
 }
 
 func weaponDef(kind WeaponKind) WeaponDef {
@@ -82,6 +369,92 @@ func weaponDef(kind WeaponKind) WeaponDef {
 	return weaponDefs[WeaponWhip]
 }
 
+// WeaponName returns the display name for a weapon kind.
+func WeaponName(kind WeaponKind) string {
+	return weaponDef(kind).Name
+}
+
+// baseWeapon maps an evolved weapon back to the weapon it evolved from.
+func baseWeapon(kind WeaponKind) WeaponKind {
+	for _, e := range weaponEvolutions {
+		if e.Into == kind {
+			return e.Base
+		}
+	}
+	return kind
+}
+
+// HasWeapon reports whether any slot holds kind.
+func (p *Player) HasWeapon(kind WeaponKind) bool {
+	return p.weaponSlot(kind) >= 0
+}
+
+// WeaponCooldown is the time between a slot's attacks.
+func (p *Player) WeaponCooldown(slot WeaponSlot) float32 {
+	return maxf(0.08, p.AttackCooldown*slot.Stats().CooldownMul)
+}
+
+// weaponSlot finds the slot holding kind, or the weapon it evolved into.
+func (p *Player) weaponSlot(kind WeaponKind) int {
+	for i, s := range p.Weapons {
+		if s.Kind == kind || baseWeapon(s.Kind) == kind {
+			return i
+		}
+	}
+	return -1
+}
+
+// pickupWeapon applies a weapon drop. An owned weapon levels up and a new one
+// takes a free slot. With every slot taken, or the owned weapon maxed, the
+// drop levels the lowest-level slot instead. It returns false when the drop
+// would change nothing, so the drop stays on the ground.
+func (w *World) pickupWeapon(kind WeaponKind) bool {
+	p := &w.Player
+	i := p.weaponSlot(kind)
+	if i < 0 && len(p.Weapons) < w.Cfg.WeaponSlots {
+		p.Weapons = append(p.Weapons, WeaponSlot{Kind: kind, Level: 1})
+		return true
+	}
+	if i < 0 || p.Weapons[i].MaxLevel() {
+		i = -1
+		for j, s := range p.Weapons {
+			if !s.MaxLevel() && (i < 0 || s.Level < p.Weapons[i].Level) {
+				i = j
+			}
+		}
+	}
+	if i < 0 {
+		return false
+	}
+	w.levelWeaponSlot(i)
+	return true
+}
+
+func (w *World) levelWeaponSlot(i int) {
+	slot := &w.Player.Weapons[i]
+	if slot.MaxLevel() {
+		return
+	}
+	slot.Level++
+	w.evolveWeapons()
+}
+
+// evolveWeapons applies every recipe whose weapon is maxed and whose passive
+// the player owns. The evolved weapon starts at level 1 and keeps its timer.
+func (w *World) evolveWeapons() {
+	p := &w.Player
+	for i := range p.Weapons {
+		slot := &p.Weapons[i]
+		for _, e := range weaponEvolutions {
+			if slot.Kind == e.Base && slot.MaxLevel() && p.PassiveLevel(e.Passive) > 0 {
+				slot.Kind = e.Into
+				slot.Level = 1
+				break
+			}
+		}
+	}
+}
+
 func (w *World) randomWeaponKind() WeaponKind {
 	total := 0
 	for _, kind := range weaponOrder {
@@ -102,14 +475,3 @@ func (w *World) randomWeaponKind() WeaponKind {
 	}
 	return WeaponWhip
 }
-
-func weaponDropChance(kind EnemyKind) float32 {
-	switch kind {
-	case EnemyTank:
-		return 0.42
-	case EnemyRunner:
-		return 0.22
-	default:
-		return 0.10
-	}
-}
@@ -1,94 +1,114 @@
 package world
 
 import (
-	"fmt"
-	"image/color"
-	"math/rand"
-
 	"horde-lab/internal/jobs"
-
-	"github.com/hajimehoshi/ebiten/v2"
-	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
-	"github.com/hajimehoshi/ebiten/v2/vector"
-
 	"horde-lab/internal/shared/input"
 )
 
-const (
-	EnemyNormal EnemyKind = iota
-	EnemyRunner
-	EnemyTank
-)
-
-type AssetProvider interface {
-	Get(key string) *ebiten.Image
-}
-
 const worldInboxCapacity = 256
 
 func NewWorld(w, h float32) *World {
-	cfg := DefaultConfig()
-	const seed int64 = 1
-	pl := Player{
-		Pos:   Vec2{X: w / 2, Y: h / 2},
-		Speed: cfg.PlayerSpeed,
-		R:     cfg.PlayerRadius,
+	return NewWorldWithConfig(w, h, DefaultConfig(), 1)
+}
 
-		AttackCooldown: cfg.PlayerAttackCooldown,
-		AttackRange:    cfg.PlayerAttackRange,
-		Damage:         cfg.PlayerDamage,
+// NewWorldWithConfig builds a world from an explicit config and RNG seed.
+// A zero seed is normalized to 1 to match ensureRNG.
+func NewWorldWithConfig(w, h float32, cfg Config, seed int64) *World {
+	if seed == 0 {
+		seed = 1
+	}
+	base := basePlayerStats(cfg)
+	center := Vec2{X: w / 2, Y: h / 2}
+	tp := terrainParams(w, h, cfg, seed, center)
+	var terrain *TileMap
+	var obstacles []Obstacle
+	if cfg.ChunkSize <= 0 {
+		terrain = GenerateTerrain(tp)
+		obstacles = generateObstacles(Vec2{}, w, h, cfg, seed, center, terrain)
+	}
+	pl := Player{
+		Pos: center,
+		R:   cfg.PlayerRadius,
 
-		MaxHP:        cfg.PlayerMaxHP,
-		HP:           cfg.PlayerMaxHP,
+		HP:           base.MaxHP,
 		HurtCooldown: cfg.PlayerHurtCooldown,
 
 		Level:    1,
 		XP:       0,
 		XPToNext: cfg.XPToNext(1),
-		XPMagnet: 10,
-		Weapon:   WeaponWhip,
+		Weapons:  []WeaponSlot{{Kind: WeaponWhip, Level: 1}},
+		Base:     base,
 	}
-	return &World{
+	pl.refreshStats()
+	world := &World{
 		W: w, H: h,
 		Cfg: cfg,
 
 		inboxCh:  make(chan Msg, worldInboxCapacity),
 		inboxBuf: make([]Msg, 0, 16),
 
-		Player:     pl,
-		Enemies:    make([]Enemy, 0, 256),
-		Orbs:       make([]XPOrb, 0, 256),
-		Drops:      make([]WeaponDrop, 0, 32),
-		Shots:      make([]EnemyProjectile, 0, 128),
-		Obstacles:  generateObstacles(w, h, cfg, seed, pl.Pos),
-		spawnEvery: cfg.BaseSpawnEvery,
-
-		rng:      rand.New(rand.NewSource(seed)),
+		Player:      pl,
+		Enemies:     make([]Enemy, 0, 256),
+		Orbs:        make([]XPOrb, 0, 256),
+		Drops:       make([]WeaponDrop, 0, 32),
+		Pickups:     make([]Pickup, 0, 32),
+		Shots:       make([]EnemyProjectile, 0, 128),
+		PlayerShots: make([]PlayerProjectile, 0, 64),
+		Obstacles:   obstacles,
+		Terrain:     terrain,
+		enemyGrid:   newSpatialGrid(enemyGridCellSize),
+		orbGrid:     newSpatialGrid(enemyGridCellSize),
+		queryBuf:    make([]int, 0, 64),
+		spawnEvery:  cfg.BaseSpawnEvery,
+
+		rng:      newRNGState(seed),
 		rngSeed:  seed,
 		rngCalls: 0,
 
 		Wave: buildWaveState(cfg, 1, seed),
-
-		aiPool:            newAIPool(),
-		aiPendingRequests: make(map[uint64]jobs.IntentRequest, 8),
-		aiReadyResults:    make(map[uint64]jobs.IntentResult, 8),
-	}
+		Upgrade: UpgradeMenu{
+			Rerolls:  cfg.UpgradeRerolls + cfg.Meta.Rerolls,
+			Skips:    cfg.UpgradeSkips,
+			Banishes: cfg.UpgradeBanishes,
+		},
+
+		pool:      newWorldJobs(),
+		aiIntents: jobs.NewPending(jobs.ComputeIntents),
+		waves:     jobs.NewPending(waveRequest.build),
+		flows:     &jobs.FlowCache{},
+		flowStale: true,
+	}
+	if world.chunked() {
+		world.initChunks(tp)
+		world.applyChunkWindow(world.chunkOf(center))
+	}
+	world.prefetchWave(2)
+	return world
 }
 
 func (w *World) Reset() {
 	// keep constants/config; reset mutable state
-	oldPool := w.aiPool
-	*w = *NewWorld(w.W, w.H)
+	oldPool := w.pool
+	oldChunks := w.chunks
+	*w = *NewWorldWithConfig(w.W, w.H, w.Cfg, w.rngSeed)
 	if oldPool != nil {
 		oldPool.Close()
 	}
+	oldChunks.close()
+}
+
+// SetMeta replaces the meta bonuses. They apply from the next Reset, so the
+// running player keeps the bonuses its run started with.
+func (w *World) SetMeta(m MetaBonuses) {
+	w.Cfg.Meta = m
 }
 
 func (w *World) Close() {
-	if w.aiPool != nil {
-		w.aiPool.Close()
-		w.aiPool = nil
+	if w.pool != nil {
+		w.pool.Close()
+		w.pool = nil
 	}
+	w.chunks.close()
 }
 
 func (w *World) Enqueue(m Msg) {
@@ -125,16 +145,23 @@ func (w *World) Tick(dt float32) {
 
 	w.TimeSurvived += dt
 
+	w.updatePlayerStats(dt)
 	w.updateDifficulty()
+	w.updateChunks()
 	w.updateSpawning(dt)
+	w.updateStatusEffects(dt)
 	w.updateEnemies(dt, intents)
+	w.updateBoss(dt)
+	w.rebuildEnemyGrid()
 	w.updateCombat(dt)
+	w.updatePlayerProjectiles(dt)
 	w.updateRunnerRangedShots(dt)
 	w.updateKnockback(dt)
 	w.updateContactDamage(dt)
 	w.updateEnemyProjectiles(dt)
 	w.updateXPOrbs(dt)
 	w.updateWeaponDrops()
+	w.updatePickups(dt)
 	w.updateShake(dt)
 	w.updateLevelUp()
 	w.submitAIJob(w.aiTick)
@@ -165,6 +192,18 @@ func (w *World) handleMsg(m Msg, dt float32) {
 		if !w.GameOver {
 			w.applyUpGradeChoice(msg.Choice)
 		}
+	case MsgRerollUpgrades:
+		if !w.GameOver {
+			w.rerollUpgrades()
+		}
+	case MsgSkipUpgrade:
+		if !w.GameOver {
+			w.skipUpgrade()
+		}
+	case MsgBanishUpgrade:
+		if !w.GameOver {
+			w.banishUpgrade(msg.Choice)
+		}
 	case MsgRestart:
 		if w.GameOver || w.Paused {
 			w.Reset()
@@ -174,13 +213,7 @@ func (w *World) handleMsg(m Msg, dt float32) {
 			w.Paused = !w.Paused
 		}
 	case MsgSaveSnapshot:
-		err := w.SaveSnapshot(msg.Path)
-		if msg.Reply != nil {
-			select {
-			case msg.Reply <- err:
-			default:
-			}
-		}
+		w.saveSnapshotAsync(msg.Path, msg.Reply)
 	case MsgLoadSnapshot:
 		err := w.LoadSnapshot(msg.Path)
 		if msg.Reply != nil {
@@ -208,446 +241,15 @@ func (w *World) applyInput(dt float32, in input.State) {
 		dir.X += 1
 	}
 
-	if dir.X != 0 || dir.Y != 0 {
+	speed := w.Player.Speed * statusSpeedScale(w.Player.Statuses)
+	if (dir.X != 0 || dir.Y != 0) && speed > 0 {
 		w.Player.Moving = true
 		dir = dir.Norm()
-		w.Player.Pos = w.resolveEntityPosition(Vec2{
-			X: w.Player.Pos.X + dir.X*w.Player.Speed*dt,
-			Y: w.Player.Pos.Y + dir.Y*w.Player.Speed*dt,
+		w.Player.Pos = w.moveEntity(w.Player.Pos, Vec2{
+			X: w.Player.Pos.X + dir.X*speed*dt,
+			Y: w.Player.Pos.Y + dir.Y*speed*dt,
 		}, w.Player.R)
 	} else {
 		w.Player.Moving = false
 	}
 }
-
-func (w *World) Draw(screen *ebiten.Image, assets AssetProvider) {
-	screen.Fill(color.RGBA{15, 15, 18, 255})
-
-	// camera centered on player
-	sw, sh := screen.Bounds().Dx(), screen.Bounds().Dy()
-	camX := float32(sw)/2 - w.Player.Pos.X
-	camY := float32(sh)/2 - w.Player.Pos.Y
-
-	// offset camera for damage shake
-
-	camX += w.ShakeOff.X
-	camY += w.ShakeOff.Y
-
-	// world background``
-	vector.FillRect(
-		screen,
-		camX, camY,
-		w.W, w.H,
-		color.RGBA{30, 30, 36, 255},
-		false, // anti-alias
-	)
-
-	// XP orbs
-	for _, obstacle := range w.Obstacles {
-		ox := camX + obstacle.Pos.X
-		oy := camY + obstacle.Pos.Y
-		vector.FillCircle(screen, ox, oy, obstacle.R, color.RGBA{58, 54, 50, 255}, false)
-		vector.StrokeCircle(screen, ox, oy, obstacle.R+2, 2, color.RGBA{110, 104, 94, 255}, false)
-		vector.StrokeCircle(screen, ox, oy, obstacle.R*0.55, 1, color.RGBA{88, 82, 72, 180}, false)
-	}
-
-	// XP orbs
-	for _, o := range w.Orbs {
-		vector.FillCircle(
-			screen,
-			camX+o.Pos.X,
-			camY+o.Pos.Y,
-			o.R,
-			color.RGBA{240, 210, 80, 255},
-			false,
-		)
-	}
-
-	// weapon drops
-	for _, d := range w.Drops {
-		dx := camX + d.Pos.X
-		dy := camY + d.Pos.Y
-		switch d.Kind {
-		case WeaponSpear:
-			vector.StrokeLine(screen, dx-d.R, dy+d.R, dx+d.R, dy-d.R, 2, color.RGBA{110, 220, 255, 255}, false)
-		case WeaponNova:
-			vector.FillCircle(screen, dx, dy, d.R, color.RGBA{230, 90, 220, 220}, false)
-			vector.StrokeCircle(screen, dx, dy, d.R+2, 1, color.RGBA{255, 160, 250, 255}, false)
-		case WeaponFang:
-			vector.FillRect(screen, dx-d.R*0.5, dy-d.R, d.R, d.R*2, color.RGBA{255, 120, 120, 255}, false)
-		default:
-			vector.FillRect(screen, dx-d.R, dy-d.R*0.35, d.R*2, d.R*0.7, color.RGBA{255, 220, 120, 255}, false)
-		}
-	}
-
-	// enemy projectiles
-	for _, s := range w.Shots {
-		sx := camX + s.Pos.X
-		sy := camY + s.Pos.Y
-		vector.FillCircle(screen, sx, sy, s.R, color.RGBA{255, 95, 95, 230}, false)
-		vector.StrokeCircle(screen, sx, sy, s.R+1, 1, color.RGBA{255, 200, 200, 255}, false)
-	}
-
-	// Enemy rendering with visual variety
-	for _, e := range w.Enemies {
-		ex := camX + e.Pos.X
-		ey := camY + e.Pos.Y
-
-		switch e.Kind {
-		case EnemyRunner:
-			// Fast, elongated diamond-like enemy (stretched horizontally)
-			clr := color.RGBA{240, 170, 60, 255}
-			if e.HitT > 0 {
-				clr = color.RGBA{255, 255, 255, 255}
-			}
-
-			// Draw as two overlapping rectangles to form diamond
-			// Horizontal part
-			vector.FillRect(
-				screen,
-				ex-e.R*1.2, ey-e.R*0.4,
-				e.R*2.4, e.R*0.8,
-				clr,
-				false,
-			)
-
-			// Vertical part (smaller)
-			vector.FillRect(
-				screen,
-				ex-e.R*0.4, ey-e.R*0.8,
-				e.R*0.8, e.R*1.6,
-				clr,
-				false,
-			)
-
-			// Bright center core
-			vector.FillRect(
-				screen,
-				ex-e.R*0.25, ey-e.R*0.25,
-				e.R*0.5, e.R*0.5,
-				color.RGBA{255, 220, 120, 255},
-				false,
-			)
-
-		case EnemyTank:
-			// Large, beefy tank with armor plating
-			baseClr := color.RGBA{170, 110, 240, 255}
-			if e.HitT > 0 {
-				baseClr = color.RGBA{255, 255, 255, 255}
-			}
-
-			// Large square body
-			vector.FillRect(
-				screen,
-				ex-e.R, ey-e.R,
-				e.R*2, e.R*2,
-				baseClr,
-				false,
-			)
-
-			// Armor plates (dark lines)
-			darkClr := color.RGBA{120, 70, 180, 255}
-
-			// Horizontal armor lines
-			vector.FillRect(
-				screen,
-				ex-e.R*0.9, ey-e.R*0.4,
-				e.R*1.8, e.R*0.2,
-				darkClr,
-				false,
-			)
-			vector.FillRect(
-				screen,
-				ex-e.R*0.9, ey+e.R*0.2,
-				e.R*1.8, e.R*0.2,
-				darkClr,
-				false,
-			)
-
-			// Vertical center line
-			vector.FillRect(
-				screen,
-				ex-e.R*0.1, ey-e.R*0.9,
-				e.R*0.2, e.R*1.8,
-				darkClr,
-				false,
-			)
-
-			// Core/weak point
-			vector.FillRect(
-				screen,
-				ex-e.R*0.3, ey-e.R*0.3,
-				e.R*0.6, e.R*0.6,
-				color.RGBA{220, 160, 255, 255},
-				false,
-			)
-		default: // EnemyNormal
-			clr := color.RGBA{220, 80, 80, 255}
-			if e.HitT > 0 {
-				clr = color.RGBA{255, 180, 180, 255}
-			}
-
-			vector.FillCircle(
-				screen,
-				ex, ey,
-				e.R,
-				clr,
-				false,
-			)
-
-			// small "eye"
-			eyeR := e.R * 0.35
-			vector.FillCircle(
-				screen,
-				ex, ey,
-				eyeR,
-				color.RGBA{150, 40, 40, 255},
-				false,
-			)
-		}
-	}
-	// attack line (fade normalized)
-	if w.LastAttackT > 0 {
-		const lastAttackMax float32 = 0.08
-		t := w.LastAttackT / lastAttackMax
-
-		if t < 0 {
-			t = 0
-		}
-
-		if t > 1 {
-			t = 1
-		}
-
-		alpha := uint8(255 * t)
-		switch w.LastAttackWeapon {
-		case WeaponNova:
-			vector.StrokeCircle(
-				screen,
-				camX+w.Player.Pos.X,
-				camY+w.Player.Pos.Y,
-				w.LastAttackRadius,
-				2,
-				color.RGBA{255, 130, 230, alpha},
-				false,
-			)
-		case WeaponSpear:
-			vector.StrokeLine(
-				screen,
-				camX+w.Player.Pos.X,
-				camY+w.Player.Pos.Y,
-				camX+w.LastAttackPos.X,
-				camY+w.LastAttackPos.Y,
-				3,
-				color.RGBA{120, 230, 255, alpha},
-				false,
-			)
-			vector.FillCircle(screen, camX+w.LastAttackPos.X, camY+w.LastAttackPos.Y, 3, color.RGBA{200, 245, 255, alpha}, false)
-		case WeaponFang:
-			midX := (w.Player.Pos.X + w.LastAttackPos.X) * 0.5
-			midY := (w.Player.Pos.Y + w.LastAttackPos.Y) * 0.5
-			vector.StrokeLine(screen, camX+w.Player.Pos.X, camY+w.Player.Pos.Y, camX+midX, camY+midY, 2, color.RGBA{255, 110, 110, alpha}, false)
-			vector.StrokeLine(screen, camX+midX, camY+midY, camX+w.LastAttackPos.X, camY+w.LastAttackPos.Y, 2, color.RGBA{255, 170, 170, alpha}, false)
-		default: // whip
-			vector.StrokeLine(
-				screen,
-				camX+w.Player.Pos.X,
-				camY+w.Player.Pos.Y,
-				camX+w.LastAttackPos.X,
-				camY+w.LastAttackPos.Y,
-				2, // line width
-				color.RGBA{255, 255, 100, alpha},
-				false,
-			)
-			vector.StrokeLine(
-				screen,
-				camX+w.Player.Pos.X,
-				camY+w.Player.Pos.Y,
-				camX+(w.Player.Pos.X*0.35+w.LastAttackPos.X*0.65),
-				camY+(w.Player.Pos.Y*0.35+w.LastAttackPos.Y*0.65),
-				1,
-				color.RGBA{255, 220, 140, alpha},
-				false,
-			)
-		}
-	}
-
-	// draw player
-	px := camX + w.Player.Pos.X
-	py := camY + w.Player.Pos.Y
-
-	playerImg := assets.Get("player_top")
-	if w.Player.Moving {
-		if walk := assets.Get("player_walk"); walk != nil {
-			playerImg = walk
-		}
-	}
-	if playerImg == nil {
-		playerImg = assets.Get("player")
-	}
-	if playerImg != nil {
-		b := playerImg.Bounds()
-		iw, ih := b.Dx(), b.Dy()
-
-		if iw > 0 && ih > 0 {
-			op := &ebiten.DrawImageOptions{}
-			op.GeoM.Translate(-float64(iw)/2, -float64(ih)/2)
-			op.GeoM.Scale(
-				float64((w.Player.R*3)/float32(iw)),
-				float64((w.Player.R*3)/float32(ih)),
-			)
-			op.GeoM.Translate(float64(px), float64(py))
-
-			if w.Player.HurtTimer > 0 {
-				op.ColorScale.Scale(1.25, 1.25, 1.25, 1.0)
-			}
-			screen.DrawImage(playerImg, op)
-		}
-	} else {
-		pclr := color.RGBA{80, 200, 120, 255}
-		if w.Player.HurtTimer > 0 {
-			pclr = color.RGBA{200, 240, 200, 255}
-		}
-		vector.FillCircle(
-			screen,
-			px,
-			py,
-			w.Player.R,
-			pclr,
-			false,
-		)
-	}
-
-	// HUD (top-left, screen space)
-	hud := fmt.Sprintf(
-		"HP: %.0f/%.0f\nLV: %d  XP: %.0f/%.0f\nWeapon: %s\nWave: %d %s (%.1fs)\nKills: %d\nEnemies: %d  Obstacles: %d\nOrbs: %d  Drops: %d\nSpawnEvery: %.2fs\nTime: %.1fs",
-		w.Player.HP, w.Player.MaxHP,
-		w.Player.Level, w.Player.XP, w.Player.XPToNext,
-		weaponDef(w.Player.Weapon).Name,
-		w.Wave.Index, w.Wave.Label, maxf(0, w.Wave.StartTime+w.Wave.Duration-w.TimeSurvived),
-		w.Stats.EnemiesKilled,
-		len(w.Enemies), len(w.Obstacles), len(w.Orbs), len(w.Drops),
-		w.spawnEvery,
-		w.TimeSurvived,
-	)
-
-	ebitenutil.DebugPrintAt(screen, hud, 8, 8)
-
-	// ---- Modal overlays (priority: GameOver > Upgrade > Paused) ----
-
-	// Game over overlay
-	if w.GameOver {
-		px, py, pw, ph := drawModalPanel(screen, assets, float32(sw), float32(sh), 0.62, 0.46)
-		x := int(px + 24)
-		y := int(py + 22)
-		ebitenutil.DebugPrintAt(screen, "GAME OVER", x, y)
-		ebitenutil.DebugPrintAt(screen, "Press R to restart", x, y+22)
-		ebitenutil.DebugPrintAt(screen, fmt.Sprintf("Time Survived: %.1fs", w.TimeSurvived), x, y+50)
-		ebitenutil.DebugPrintAt(screen, fmt.Sprintf("Level Reached: %d", w.Player.Level), x, y+72)
-		ebitenutil.DebugPrintAt(screen, fmt.Sprintf("Kills: %d", w.Stats.EnemiesKilled), x, y+94)
-		ebitenutil.DebugPrintAt(screen, fmt.Sprintf("Enemies Spawned: %d", w.Stats.EnemiesSpawned), x, y+116)
-		ebitenutil.DebugPrintAt(screen, fmt.Sprintf("Damage Taken: %.0f", w.Stats.DamageTaken), x, y+138)
-		ebitenutil.DebugPrintAt(screen, fmt.Sprintf("XP Collected: %.0f", w.Stats.XPCollected), x, y+160)
-		_ = pw
-		_ = ph
-
-		return
-	}
-
-	// Upgrade menu overlay
-	if w.Upgrade.Active {
-		px, py, pw, ph := drawModalPanel(screen, assets, float32(sw), float32(sh), 0.60, 0.42)
-		drawUpgradeChoiceButtons(screen, assets, w.Upgrade.Options[0].Title, w.Upgrade.Options[1].Title)
-
-		// menu text
-		x := int(px + 24)
-		y := int(py + 22)
-		ebitenutil.DebugPrintAt(screen, "LEVEL UP! Choose an upgrade:", x, y)
-		o0 := w.Upgrade.Options[0]
-		o1 := w.Upgrade.Options[1]
-		ebitenutil.DebugPrintAt(screen, o0.Title, x, y+22)
-		ebitenutil.DebugPrintAt(screen, "  "+o0.Desc, x, y+42)
-		ebitenutil.DebugPrintAt(screen, o1.Title, x, y+64)
-		ebitenutil.DebugPrintAt(screen, "  "+o1.Desc, x, y+84)
-		ebitenutil.DebugPrintAt(screen, "Press 1 or 2", x, y+106)
-		_ = pw
-		_ = ph
-	}
-
-	// Pause overlay
-	if w.Paused {
-		px, py, _, _ := drawModalPanel(screen, assets, float32(sw), float32(sh), 0.58, 0.36)
-		drawPauseButtons(screen, assets)
-		x := int(px + 24)
-		y := int(py + 22)
-		ebitenutil.DebugPrintAt(screen, "PAUSED", x, y)
-		ebitenutil.DebugPrintAt(screen, "Space/C: Continue", x, y+24)
-		ebitenutil.DebugPrintAt(screen, "R: Restart", x, y+44)
-	}
-}
-
-func drawImageFitted(dst *ebiten.Image, img *ebiten.Image, x, y, w, h float32) {
-	b := img.Bounds()
-	iw := float32(b.Dx())
-	ih := float32(b.Dy())
-	if iw <= 0 || ih <= 0 {
-		return
-	}
-	op := &ebiten.DrawImageOptions{}
-	op.GeoM.Scale(float64(w/iw), float64(h/ih))
-	op.GeoM.Translate(float64(x), float64(y))
-	dst.DrawImage(img, op)
-}
-
-func drawModalPanel(screen *ebiten.Image, assets AssetProvider, sw, sh, wr, hr float32) (x, y, w, h float32) {
-	vector.FillRect(screen, 0, 0, sw, sh, color.RGBA{0, 0, 0, 160}, false)
-	pw := sw * wr
-	ph := sh * hr
-	px := (sw - pw) * 0.5
-	py := (sh - ph) * 0.5
-	if panel := assets.Get("ui_panel"); panel != nil {
-		drawImageFitted(screen, panel, px, py, pw, ph)
-		return px, py, pw, ph
-	}
-	vector.FillRect(screen, px, py, pw, ph, color.RGBA{28, 28, 32, 230}, false)
-	return px, py, pw, ph
-}
-
-func drawUpgradeChoiceButtons(screen *ebiten.Image, assets AssetProvider, left, right string) {
-	sw := float32(screen.Bounds().Dx())
-	sh := float32(screen.Bounds().Dy())
-	bw := sw * 0.20
-	bh := sh * 0.065
-	y := sh * 0.60
-	x1 := sw*0.32 - bw*0.5
-	x2 := sw*0.68 - bw*0.5
-	drawButton(screen, assets, x1, y, bw, bh, "ui_button_selected")
-	drawButton(screen, assets, x2, y, bw, bh, "ui_button_hover")
-	if c := assets.Get("ui_cursor"); c != nil {
-		drawImageFitted(screen, c, x1-18, y+bh*0.5-12, 18, 24)
-	}
-	ebitenutil.DebugPrintAt(screen, left, int(x1)+12, int(y)+12)
-	ebitenutil.DebugPrintAt(screen, right, int(x2)+12, int(y)+12)
-}
-
-func drawPauseButtons(screen *ebiten.Image, assets AssetProvider) {
-	sw := float32(screen.Bounds().Dx())
-	sh := float32(screen.Bounds().Dy())
-	bw := sw * 0.22
-	bh := sh * 0.065
-	y1 := sh * 0.58
-	y2 := y1 + bh + 10
-	x := (sw - bw) * 0.5
-	drawButton(screen, assets, x, y1, bw, bh, "ui_button_selected")
-	drawButton(screen, assets, x, y2, bw, bh, "ui_button_normal")
-	ebitenutil.DebugPrintAt(screen, "C / Space: Continue", int(x)+14, int(y1)+12)
-	ebitenutil.DebugPrintAt(screen, "R: Restart", int(x)+14, int(y2)+12)
-}
-
-func drawButton(screen *ebiten.Image, assets AssetProvider, x, y, w, h float32, key string) {
-	if b := assets.Get(key); b != nil {
-		drawImageFitted(screen, b, x, y, w, h)
-		return
-	}
-	vector.FillRect(screen, x, y, w, h, color.RGBA{54, 54, 60, 220}, false)
-}